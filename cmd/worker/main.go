@@ -1,16 +1,34 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	_ "time/tzdata"
 
+	"github.com/Luzin7/alert-service/internal/domain"
 	"github.com/Luzin7/alert-service/internal/infra/smtp"
 
+	"github.com/Luzin7/alert-service/internal/infra/cache"
+	"github.com/Luzin7/alert-service/internal/infra/clicks"
 	"github.com/Luzin7/alert-service/internal/infra/database"
 	"github.com/Luzin7/alert-service/internal/infra/messenger"
+	"github.com/Luzin7/alert-service/internal/infra/metrics"
+	"github.com/Luzin7/alert-service/internal/infra/notifier"
 	"github.com/Luzin7/alert-service/internal/infra/providers"
+	"github.com/Luzin7/alert-service/internal/infra/rates"
+	"github.com/Luzin7/alert-service/internal/infra/templates"
 	"github.com/Luzin7/alert-service/internal/transport/consumer"
+	httptransport "github.com/Luzin7/alert-service/internal/transport/http"
 	"github.com/Luzin7/alert-service/internal/usecases"
 	"github.com/joho/godotenv"
 )
@@ -28,26 +46,112 @@ func main() {
 	if port == "" {
 		port = "8080"
 	}
+	publicPort := os.Getenv("PUBLIC_PORT")
+	if publicPort == "" {
+		publicPort = "8088"
+	}
 
 	databaseConnectionString := os.Getenv("DATABASE_URL")
 	if databaseConnectionString == "" {
 		log.Fatal("DATABASE_URL is not set")
 	}
 
-	db, err := database.DatabaseConnection(databaseConnectionString)
+	var err error
+	poolConfig := database.DefaultPoolConfig()
+	if maxConns := os.Getenv("DB_MAX_CONNS"); maxConns != "" {
+		value, err := strconv.ParseInt(maxConns, 10, 32)
+		if err != nil || value < 1 {
+			log.Fatalf("Invalid DB_MAX_CONNS: %q", maxConns)
+		}
+		poolConfig.MaxConns = int32(value)
+	}
+	if minConns := os.Getenv("DB_MIN_CONNS"); minConns != "" {
+		value, err := strconv.ParseInt(minConns, 10, 32)
+		if err != nil || value < 0 {
+			log.Fatalf("Invalid DB_MIN_CONNS: %q", minConns)
+		}
+		poolConfig.MinConns = int32(value)
+	}
+	if idleTime := os.Getenv("DB_MAX_CONN_IDLE_TIME"); idleTime != "" {
+		poolConfig.MaxConnIdleTime, err = time.ParseDuration(idleTime)
+		if err != nil {
+			log.Fatalf("Invalid DB_MAX_CONN_IDLE_TIME: %v", err)
+		}
+	}
+	if healthCheckPeriod := os.Getenv("DB_HEALTH_CHECK_PERIOD"); healthCheckPeriod != "" {
+		poolConfig.HealthCheckPeriod, err = time.ParseDuration(healthCheckPeriod)
+		if err != nil {
+			log.Fatalf("Invalid DB_HEALTH_CHECK_PERIOD: %v", err)
+		}
+	}
+	if statementTimeout := os.Getenv("DB_STATEMENT_TIMEOUT"); statementTimeout != "" {
+		poolConfig.StatementTimeout, err = time.ParseDuration(statementTimeout)
+		if err != nil {
+			log.Fatalf("Invalid DB_STATEMENT_TIMEOUT: %v", err)
+		}
+	}
+	queryTimeout := database.DefaultQueryTimeout
+	if value := os.Getenv("DB_QUERY_TIMEOUT"); value != "" {
+		queryTimeout, err = time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("Invalid DB_QUERY_TIMEOUT: %v", err)
+		}
+	}
+
+	db, err := database.DatabaseConnection(databaseConnectionString, poolConfig)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	// cacheAddr := os.Getenv("CACHE_ADDR")
-	// cachePassword := os.Getenv("CACHE_PASSWORD")
-	// cacheUsername := os.Getenv("CACHE_USERNAME")
-	// cacheDB := os.Getenv("CACHE_DB")
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err := runMigrate(context.Background(), db, os.Args[2:])
+		database.CloseDatabaseConnection(db)
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
+	if err := metrics.RegisterDBPool(func() metrics.DBPoolStats { return database.PoolStats(db) }); err != nil {
+		log.Fatalf("Failed to register database pool metrics: %v", err)
+	}
+
+	if migrateOnStart := os.Getenv("MIGRATE_ON_START"); migrateOnStart != "" {
+		enabled, err := strconv.ParseBool(migrateOnStart)
+		if err != nil {
+			log.Fatalf("Invalid MIGRATE_ON_START: %v", err)
+		}
+		if enabled {
+			migrator, release, err := newMigrator(context.Background(), db)
+			if err != nil {
+				log.Fatalf("Failed to load migrations: %v", err)
+			}
+			_, err = migrator.Up(context.Background())
+			release()
+			if err != nil {
+				log.Fatalf("Failed to migrate database: %v", err)
+			}
+		}
+	}
+
+	cacheAddr := os.Getenv("CACHE_ADDR")
+	if cacheAddr == "" {
+		log.Fatal("CACHE_ADDR is not set")
+	}
+	cachePassword := os.Getenv("CACHE_PASSWORD")
+	cacheUsername := os.Getenv("CACHE_USERNAME")
+	cacheDB := 0
+	if cacheDBStr := os.Getenv("CACHE_DB"); cacheDBStr != "" {
+		cacheDB, err = strconv.Atoi(cacheDBStr)
+		if err != nil {
+			log.Fatalf("Invalid CACHE_DB: %v", err)
+		}
+	}
 
-	// cacheConn, err := cache.CacheConnection(cacheAddr, cachePassword, cacheUsername, atoi(cacheDB))
-	// if err != nil {
-	// 	log.Fatalf("Failed to connect to cache: %v", err)
-	// }
+	cacheConn, err := cache.CacheConnection(cacheAddr, cachePassword, cacheUsername, cacheDB)
+	if err != nil {
+		log.Fatalf("Failed to connect to cache: %v", err)
+	}
 
 	messengerUsername := os.Getenv("MESSENGER_USERNAME")
 	messengerPassword := os.Getenv("MESSENGER_PASSWORD")
@@ -57,12 +161,18 @@ func main() {
 		log.Fatal("Messenger configuration is not set properly")
 	}
 
-	messengerConn, err := messenger.MessengerConnection(messengerUsername, messengerPassword, messengerHost, messengerPort)
-	if err != nil {
-		log.Fatalf("Failed to connect to messenger: %v", err)
-	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	messengerManager := messenger.NewManager(func() (messenger.Conn, error) {
+		return messenger.MessengerConnection(messengerUsername, messengerPassword, messengerHost, messengerPort)
+	}, messenger.DefaultBackoff)
+	// The connection must outlive the shutdown signal so that in-flight
+	// messages can still be acked while the worker drains; it is closed
+	// only after worker.Start returns.
+	messengerManager.Start(context.Background())
 
-	repo := database.NewRepository(db)
+	repo := database.NewRepository(db, database.WithQueryTimeout(queryTimeout))
 	smtpServer := os.Getenv("SMTP_SERVER")
 	smtpPortStr := os.Getenv("SMTP_PORT")
 	smtpPort, err := strconv.Atoi(smtpPortStr)
@@ -71,26 +181,405 @@ func main() {
 	}
 	smtpUsername := os.Getenv("SMTP_USERNAME")
 	smtpPassword := os.Getenv("SMTP_PASSWORD")
-	senderConn, err := smtp.SMTPConnection(smtpServer, smtpPort, smtpUsername, smtpPassword)
+	smtpFrom := os.Getenv("SMTP_FROM")
+	senderConn, err := smtp.SMTPConnection(smtpServer, smtpPort, smtpUsername, smtpPassword, smtpFrom)
 	if err != nil {
 		log.Fatalf("Failed to connect to SMTP: %v", err)
 	}
 
-	linkGenerator := providers.GoogleFlightsGenerator{
-		BaseURL: "https://www.google.com/travel/flights",
+	notifierClient := &http.Client{Timeout: notifier.DefaultTimeout}
+	if notifierTimeout := os.Getenv("NOTIFIER_TIMEOUT"); notifierTimeout != "" {
+		notifierClient.Timeout, err = time.ParseDuration(notifierTimeout)
+		if err != nil {
+			log.Fatalf("Invalid NOTIFIER_TIMEOUT: %v", err)
+		}
+	}
+	channels := []string{string(domain.ChannelEmail)}
+	if value := os.Getenv("NOTIFIER_CHANNELS"); value != "" {
+		channels = splitList(value)
+	}
+	var notifiers []domain.Notifier
+	for _, channel := range channels {
+		switch domain.Channel(channel) {
+		case domain.ChannelEmail:
+			notifiers = append(notifiers, notifier.NewEmail(senderConn))
+		case domain.ChannelWebhook:
+			webhook, err := notifier.NewWebhook([]byte(os.Getenv("WEBHOOK_SIGNING_SECRET")), notifier.WithHTTPClient(notifierClient))
+			if err != nil {
+				log.Fatalf("Invalid WEBHOOK_SIGNING_SECRET: %v", err)
+			}
+			notifiers = append(notifiers, webhook)
+		case domain.ChannelSlack:
+			notifiers = append(notifiers, notifier.NewSlack(notifier.WithHTTPClient(notifierClient)))
+		case domain.ChannelTelegram:
+			telegramToken := os.Getenv("TELEGRAM_BOT_TOKEN")
+			if telegramToken == "" {
+				log.Fatal("TELEGRAM_BOT_TOKEN is required when the telegram channel is enabled")
+			}
+			notifiers = append(notifiers, notifier.NewTelegram(os.Getenv("TELEGRAM_API_URL"), telegramToken, notifier.WithHTTPClient(notifierClient)))
+		default:
+			log.Fatalf("Invalid NOTIFIER_CHANNELS: unknown channel %q", channel)
+		}
+	}
+
+	linkProviders := []string{providers.GoogleFlights}
+	if value := os.Getenv("LINK_PROVIDERS"); value != "" {
+		linkProviders = splitList(value)
+	}
+	linkProviderSet := providers.DefaultProviders()
+	if trackingConfigPath := os.Getenv("LINK_TRACKING_CONFIG"); trackingConfigPath != "" {
+		trackingConfig, err := providers.LoadTrackingConfig(trackingConfigPath)
+		if err != nil {
+			log.Fatalf("Failed to load link tracking config: %v", err)
+		}
+		linkProviderSet = providers.WithTracking(linkProviderSet, trackingConfig)
+	}
+	linkGenerator, err := providers.NewRegistry(linkProviders, linkProviderSet...)
+	if err != nil {
+		log.Fatalf("Invalid LINK_PROVIDERS: %v", err)
+	}
+
+	renderer, err := templates.NewRenderer(os.Getenv("EMAIL_TEMPLATES_DIR"))
+	if err != nil {
+		log.Fatalf("Failed to load email templates: %v", err)
+	}
+
+	rules := domain.DefaultNotificationRules
+	if minDropPercent := os.Getenv("MIN_DROP_PERCENT"); minDropPercent != "" {
+		rules.MinDropPercent, err = strconv.ParseFloat(minDropPercent, 64)
+		if err != nil {
+			log.Fatalf("Invalid MIN_DROP_PERCENT: %v", err)
+		}
+	}
+	if urgentDropPercent := os.Getenv("URGENT_DROP_PERCENT"); urgentDropPercent != "" {
+		rules.UrgentDropPercent, err = strconv.ParseFloat(urgentDropPercent, 64)
+		if err != nil {
+			log.Fatalf("Invalid URGENT_DROP_PERCENT: %v", err)
+		}
+	}
+
+	useCaseOptions := []usecases.Option{
+		usecases.WithNotificationRules(rules),
+		usecases.WithNotificationLog(repo),
+	}
+	if perUser := os.Getenv("LINK_PROVIDERS_PER_USER"); perUser != "" {
+		enabled, err := strconv.ParseBool(perUser)
+		if err != nil {
+			log.Fatalf("Invalid LINK_PROVIDERS_PER_USER: %v", err)
+		}
+		if enabled {
+			useCaseOptions = append(useCaseOptions, usecases.WithUserLinkProviders())
+		}
+	}
+
+	var clickTracker *clicks.Tracker
+	if clickTrackingSecret := os.Getenv("CLICK_TRACKING_SECRET"); clickTrackingSecret != "" {
+		publicBaseURL := os.Getenv("PUBLIC_BASE_URL")
+		if publicBaseURL == "" {
+			log.Fatal("PUBLIC_BASE_URL is required when CLICK_TRACKING_SECRET is set")
+		}
+		signer, err := clicks.NewSigner([]byte(clickTrackingSecret))
+		if err != nil {
+			log.Fatalf("Invalid CLICK_TRACKING_SECRET: %v", err)
+		}
+		clickTracker = clicks.NewTracker(repo, signer, publicBaseURL)
+		useCaseOptions = append(useCaseOptions, usecases.WithLinkTracker(clickTracker))
+	}
+
+	var sendDigests *usecases.SendDigests
+	digestInterval := time.Minute
+	if digestEnabled := os.Getenv("DIGEST_ENABLED"); digestEnabled != "" {
+		enabled, err := strconv.ParseBool(digestEnabled)
+		if err != nil {
+			log.Fatalf("Invalid DIGEST_ENABLED: %v", err)
+		}
+		if enabled {
+			schedule := domain.DefaultDigestSchedule
+			if sendHour := os.Getenv("DIGEST_SEND_HOUR"); sendHour != "" {
+				schedule.Hour, err = strconv.Atoi(sendHour)
+				if err != nil || schedule.Hour < 0 || schedule.Hour > 23 {
+					log.Fatalf("Invalid DIGEST_SEND_HOUR: %q", sendHour)
+				}
+			}
+			if weekday := os.Getenv("DIGEST_WEEKDAY"); weekday != "" {
+				schedule.Weekday, err = parseWeekday(weekday)
+				if err != nil {
+					log.Fatalf("Invalid DIGEST_WEEKDAY: %v", err)
+				}
+			}
+			if interval := os.Getenv("DIGEST_CHECK_INTERVAL"); interval != "" {
+				digestInterval, err = time.ParseDuration(interval)
+				if err != nil || digestInterval <= 0 {
+					log.Fatalf("Invalid DIGEST_CHECK_INTERVAL: %q", interval)
+				}
+			}
+			sendDigests = usecases.NewSendDigests(repo, repo, senderConn, renderer, usecases.WithDigestSchedule(schedule))
+			useCaseOptions = append(useCaseOptions, usecases.WithDigests(repo))
+		}
+	}
+
+	var cooldownPolicy domain.CooldownPolicy
+	if window := os.Getenv("COOLDOWN_WINDOW"); window != "" {
+		cooldownPolicy.Window, err = time.ParseDuration(window)
+		if err != nil || cooldownPolicy.Window < 0 {
+			log.Fatalf("Invalid COOLDOWN_WINDOW: %q", window)
+		}
+	}
+	if minDropPercent := os.Getenv("COOLDOWN_MIN_DROP_PERCENT"); minDropPercent != "" {
+		cooldownPolicy.MinDropPercent, err = strconv.ParseFloat(minDropPercent, 64)
+		if err != nil {
+			log.Fatalf("Invalid COOLDOWN_MIN_DROP_PERCENT: %v", err)
+		}
+	}
+	if minDropAmount := os.Getenv("COOLDOWN_MIN_DROP_AMOUNT"); minDropAmount != "" {
+		cooldownPolicy.MinDropAmount, err = strconv.ParseFloat(minDropAmount, 64)
+		if err != nil {
+			log.Fatalf("Invalid COOLDOWN_MIN_DROP_AMOUNT: %v", err)
+		}
+	}
+	if cooldownPolicy.Enabled() {
+		useCaseOptions = append(useCaseOptions, usecases.WithCooldown(cache.NewCooldownStore(cacheConn, cooldownPolicy)))
+	}
+
+	priceHistory := true
+	if value := os.Getenv("PRICE_HISTORY_ENABLED"); value != "" {
+		priceHistory, err = strconv.ParseBool(value)
+		if err != nil {
+			log.Fatalf("Invalid PRICE_HISTORY_ENABLED: %v", err)
+		}
+	}
+	trendDays := usecases.DefaultPriceTrendDays
+	if value := os.Getenv("PRICE_TREND_DAYS"); value != "" {
+		trendDays, err = strconv.Atoi(value)
+		if err != nil || trendDays < 1 {
+			log.Fatalf("Invalid PRICE_TREND_DAYS: %q", value)
+		}
+	}
+	if priceHistory {
+		useCaseOptions = append(useCaseOptions, usecases.WithPriceHistory(repo, trendDays))
+	}
+
+	ratesFile, ratesURL := os.Getenv("EXCHANGE_RATES_FILE"), os.Getenv("EXCHANGE_RATES_URL")
+	if ratesFile != "" && ratesURL != "" {
+		log.Fatalf("Set only one of EXCHANGE_RATES_FILE and EXCHANGE_RATES_URL")
+	}
+	if ratesFile != "" || ratesURL != "" {
+		ratesTTL := rates.DefaultTTL
+		if value := os.Getenv("EXCHANGE_RATES_TTL"); value != "" {
+			ratesTTL, err = time.ParseDuration(value)
+			if err != nil || ratesTTL <= 0 {
+				log.Fatalf("Invalid EXCHANGE_RATES_TTL: %q", value)
+			}
+		}
+		var source rates.Source = rates.NewHTTPSource(ratesURL, nil)
+		if ratesFile != "" {
+			source = rates.NewFileSource(ratesFile)
+		}
+		exchangeRates := rates.NewProvider(source, ratesTTL)
+		if err := exchangeRates.Refresh(ctx); err != nil {
+			if ratesFile != "" {
+				log.Fatalf("Failed to load exchange rates: %v", err)
+			}
+			// The rates service may come up after the worker; the next
+			// lookup tries again.
+			log.Printf("Failed to load exchange rates: %v", err)
+		}
+		useCaseOptions = append(useCaseOptions, usecases.WithExchangeRates(exchangeRates))
+	}
+
+	deferQuietHours := true
+	if value := os.Getenv("QUIET_HOURS_DEFER"); value != "" {
+		deferQuietHours, err = strconv.ParseBool(value)
+		if err != nil {
+			log.Fatalf("Invalid QUIET_HOURS_DEFER: %v", err)
+		}
+	}
+	scheduledInterval := time.Minute
+	if interval := os.Getenv("SCHEDULED_CHECK_INTERVAL"); interval != "" {
+		scheduledInterval, err = time.ParseDuration(interval)
+		if err != nil || scheduledInterval <= 0 {
+			log.Fatalf("Invalid SCHEDULED_CHECK_INTERVAL: %q", interval)
+		}
+	}
+	if deferQuietHours {
+		useCaseOptions = append(useCaseOptions, usecases.WithAlertScheduler(repo))
+	}
+
+	processAlertUseCase := usecases.NewProcessAlert(linkGenerator, repo, notifiers, renderer, useCaseOptions...)
+
+	var deliverScheduled *usecases.DeliverScheduled
+	if deferQuietHours {
+		deliverScheduled = usecases.NewDeliverScheduled(repo, processAlertUseCase)
+	}
+
+	idempotencyStore := cache.NewIdempotencyStore(cacheConn, cache.DefaultProcessingTTL, cache.DefaultCompletedTTL)
+
+	handler := consumer.NewHandler(processAlertUseCase, idempotencyStore)
+
+	workerConfig := consumer.DefaultWorkerConfig()
+	if retryDelays := os.Getenv("RETRY_DELAYS"); retryDelays != "" {
+		workerConfig.RetryDelays, err = parseDurations(retryDelays)
+		if err != nil {
+			log.Fatalf("Invalid RETRY_DELAYS: %v", err)
+		}
+	}
+
+	if handlerTimeout := os.Getenv("HANDLER_TIMEOUT"); handlerTimeout != "" {
+		workerConfig.HandlerTimeout, err = time.ParseDuration(handlerTimeout)
+		if err != nil {
+			log.Fatalf("Invalid HANDLER_TIMEOUT: %v", err)
+		}
+	}
+	if shutdownTimeout := os.Getenv("SHUTDOWN_TIMEOUT"); shutdownTimeout != "" {
+		workerConfig.ShutdownTimeout, err = time.ParseDuration(shutdownTimeout)
+		if err != nil {
+			log.Fatalf("Invalid SHUTDOWN_TIMEOUT: %v", err)
+		}
 	}
 
-	processAlertUseCase := usecases.NewProcessAlert(linkGenerator, repo, senderConn)
+	if resubscribeDelay := os.Getenv("RESUBSCRIBE_DELAY"); resubscribeDelay != "" {
+		workerConfig.ResubscribeDelay, err = time.ParseDuration(resubscribeDelay)
+		if err != nil {
+			log.Fatalf("Invalid RESUBSCRIBE_DELAY: %v", err)
+		}
+	}
 
-	handler := consumer.NewHandler(processAlertUseCase)
+	if concurrency := os.Getenv("WORKER_CONCURRENCY"); concurrency != "" {
+		workerConfig.Concurrency, err = strconv.Atoi(concurrency)
+		if err != nil || workerConfig.Concurrency < 1 {
+			log.Fatalf("Invalid WORKER_CONCURRENCY: %q", concurrency)
+		}
+	}
+	if prefetch := os.Getenv("PREFETCH_COUNT"); prefetch != "" {
+		workerConfig.Prefetch, err = strconv.Atoi(prefetch)
+		if err != nil || workerConfig.Prefetch < 0 {
+			log.Fatalf("Invalid PREFETCH_COUNT: %q", prefetch)
+		}
+	}
+	if orderByAlert := os.Getenv("ORDER_BY_ALERT"); orderByAlert != "" {
+		workerConfig.OrderByAlert, err = strconv.ParseBool(orderByAlert)
+		if err != nil {
+			log.Fatalf("Invalid ORDER_BY_ALERT: %v", err)
+		}
+	}
 
-	worker := consumer.NewWorker(messengerConn, handler)
+	worker := consumer.NewWorker(messengerManager, handler, workerConfig)
 
 	queueName := os.Getenv("QUEUE_NAME")
 	if queueName == "" {
 		queueName = "price-alerts"
 	}
 
-	log.Printf("Starting worker on queue: %s", queueName)
-	worker.Start(queueName)
+	healthServer := httptransport.NewServer(":"+port, httptransport.DefaultServerConfig(),
+		httptransport.Check{Name: "postgres", Checker: database.NewPoolChecker(db)},
+		httptransport.Check{Name: "redis", Checker: httptransport.CheckFunc(func(ctx context.Context) error {
+			return cacheConn.Ping(ctx).Err()
+		})},
+		httptransport.Check{Name: "amqp", Checker: httptransport.CheckFunc(messengerManager.Ping)},
+		httptransport.Check{Name: "amqp_consumer", Checker: httptransport.CheckFunc(func(ctx context.Context) error {
+			if !worker.Subscribed() {
+				return errors.New("consumer is not subscribed")
+			}
+			return nil
+		})},
+		// Probing SMTP costs a full session and TLS handshake, so it runs in
+		// the background with a longer timeout and its result is reused
+		// between /readyz calls. An SMTP outage is reported without making
+		// the worker unready: failed deliveries are retried anyway.
+		httptransport.Check{
+			Name:     "smtp",
+			Checker:  httptransport.NewCachedChecker(httptransport.CheckFunc(senderConn.Ping), httptransport.DefaultCachedCheckTTL, httptransport.DefaultCachedCheckTimeout),
+			Optional: true,
+		},
+	)
+
+	servers := []*httptransport.Server{healthServer}
+	// Redirects are public; health checks and metrics stay on PORT, which
+	// should only be reachable from the internal network.
+	if clickTracker != nil {
+		servers = append(servers, httptransport.NewPublicServer(":"+publicPort, httptransport.DefaultServerConfig(), clickTracker))
+	}
+
+	serverErr := make(chan error, len(servers))
+	for _, server := range servers {
+		go func() {
+			err := server.Start(ctx)
+			if err != nil {
+				log.Printf("HTTP server failed: %v", err)
+				stop()
+			}
+			serverErr <- err
+		}()
+	}
+
+	var background sync.WaitGroup
+	if sendDigests != nil {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			sendDigests.Start(ctx, digestInterval)
+		}()
+	}
+	if deliverScheduled != nil {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			deliverScheduled.Start(ctx, scheduledInterval)
+		}()
+	}
+
+	log.Printf("Starting worker on queue: %s (health on :%s)", queueName, port)
+	if clickTracker != nil {
+		log.Printf("Serving click-tracking redirects on :%s", publicPort)
+	}
+	workerErr := worker.Start(ctx, queueName)
+	stop()
+	for range servers {
+		<-serverErr
+	}
+	background.Wait()
+
+	if err := messengerManager.Close(); err != nil {
+		log.Printf("Failed to close messenger connection: %v", err)
+	}
+	if err := cacheConn.Close(); err != nil {
+		log.Printf("Failed to close cache connection: %v", err)
+	}
+	database.CloseDatabaseConnection(db)
+
+	if workerErr != nil {
+		log.Fatalf("Worker stopped: %v", workerErr)
+	}
+	log.Println("Worker stopped gracefully")
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			items = append(items, part)
+		}
+	}
+	return items
+}
+
+func parseDurations(value string) ([]time.Duration, error) {
+	var durations []time.Duration
+	for _, part := range splitList(value) {
+		d, err := time.ParseDuration(part)
+		if err != nil {
+			return nil, err
+		}
+		durations = append(durations, d)
+	}
+	return durations, nil
+}
+
+func parseWeekday(value string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), strings.TrimSpace(value)) {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown weekday %q", value)
 }
@@ -12,7 +12,33 @@ type Alert struct {
 	NewPrice     float64
 	OldPrice     float64
 	TargetPrice  float64
+	ToleranceUp  float64
 	Currency     string
+	Cabin        CabinClass
+	Passengers   int
 	CheckedAt    time.Time
 	Link         string
+	Links        []BookingLink
+	Trend        PriceTrend
+	DisplayRate  ExchangeRate
+}
+
+func (a *Alert) LinkRequest() LinkRequest {
+	return LinkRequest{
+		AlertID:      a.ID,
+		MessageID:    a.MessageID,
+		Origin:       a.Origin,
+		Destination:  a.Destination,
+		OutboundDate: a.OutboundDate,
+		ReturnDate:   a.ReturnDate,
+		Cabin:        a.Cabin,
+		Passengers:   a.Passengers,
+	}
+}
+
+func (a *Alert) DropPercent() float64 {
+	if a.OldPrice <= 0 {
+		return 0
+	}
+	return (a.OldPrice - a.NewPrice) / a.OldPrice * 100
 }
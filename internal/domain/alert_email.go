@@ -1,7 +1,8 @@
 package domain
 
 type AlertEmail struct {
-	To      string
-	Subject string
-	Body    string
+	To       string
+	Subject  string
+	TextBody string
+	HTMLBody string
 }
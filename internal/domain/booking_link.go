@@ -0,0 +1,7 @@
+package domain
+
+type BookingLink struct {
+	Provider string
+	Name     string
+	URL      string
+}
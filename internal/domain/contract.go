@@ -6,13 +6,120 @@ import (
 )
 
 type LinkGenerator interface {
-	Generate(origin, dest string, out, ret time.Time) string
+	Generate(req LinkRequest) string
+}
+
+type BookingLinkGenerator interface {
+	Links(req LinkRequest) []BookingLink
+}
+
+type LinkTracker interface {
+	Track(ctx context.Context, alert *Alert, link BookingLink) (string, error)
+}
+
+type LinkClickRepository interface {
+	SaveTrackedLink(ctx context.Context, link TrackedLink) error
+	GetTrackedLink(ctx context.Context, id string) (TrackedLink, error)
+	RecordClick(ctx context.Context, click LinkClick) error
 }
 
 type TempEmailSender interface {
-	Send(to, subject, body string) error
+	Send(ctx context.Context, email *AlertEmail) (string, error)
+}
+
+// Notifier delivers a message on one channel and returns the provider's
+// reply, such as the SMTP response or the HTTP status.
+type Notifier interface {
+	Channel() Channel
+	Notify(ctx context.Context, msg Message) (string, error)
+}
+
+type EmailRenderer interface {
+	Render(alert *Alert, recipient Recipient) (*AlertEmail, error)
+}
+
+// DigestRenderer builds the summary email of a digest, with the items in
+// the order given.
+type DigestRenderer interface {
+	RenderDigest(digest Digest, recipient Recipient) (*AlertEmail, error)
 }
 
 type AlertRepository interface {
-	GetUserEmail(ctx context.Context, alertID int64) (string, error)
+	GetRecipient(ctx context.Context, alertID int64) (Recipient, error)
+}
+
+// NotificationLog records every delivery the worker attempts, one per
+// channel, so support can trace what happened to a user's alert.
+type NotificationLog interface {
+	CreateNotification(ctx context.Context, n Notification) (int64, error)
+	MarkNotificationSent(ctx context.Context, id int64, response string) error
+	MarkNotificationFailed(ctx context.Context, id int64, reason string) error
+	NotificationsByAlert(ctx context.Context, alertID int64, limit int) ([]Notification, error)
+	NotificationsByRecipient(ctx context.Context, email string, limit int) ([]Notification, error)
+}
+
+// DigestStore queues price changes of recipients in digest mode and hands
+// out due digests. Closing a period and claiming a digest must be safe to
+// run from several workers at once.
+type DigestStore interface {
+	AddDigestItem(ctx context.Context, item DigestItem) error
+	PendingDigestQueues(ctx context.Context) ([]DigestQueue, error)
+	CloseDigest(ctx context.Context, queue DigestQueue, periodEnd time.Time) (bool, error)
+	ClaimDigest(ctx context.Context, lease time.Duration) (Digest, error)
+	MarkDigestSent(ctx context.Context, id int64, response string) error
+	RetryDigest(ctx context.Context, id int64, reason string) error
+	MarkDigestFailed(ctx context.Context, id int64, reason string) error
+}
+
+// AlertScheduler durably holds alerts back until a later time and hands them
+// out once due. Only the latest alert of each AlertID is kept pending. The
+// Mark and Retry methods leave the row alone when a newer alert replaced it
+// in the meantime, so the newer one is processed too.
+type AlertScheduler interface {
+	ScheduleAlert(ctx context.Context, alert *Alert, deliverAt time.Time) error
+	ClaimScheduledAlert(ctx context.Context, lease time.Duration) (ScheduledAlert, error)
+	MarkScheduledAlertSent(ctx context.Context, scheduled ScheduledAlert) error
+	MarkScheduledAlertSkipped(ctx context.Context, scheduled ScheduledAlert, reason string) error
+	RetryScheduledAlert(ctx context.Context, scheduled ScheduledAlert, reason string) error
+	MarkScheduledAlertFailed(ctx context.Context, scheduled ScheduledAlert, reason string) error
+}
+
+// PriceHistoryStore keeps every price checked for each alert. Stats and
+// daily prices only take the prices in currency checked since the given time.
+type PriceHistoryStore interface {
+	RecordPrice(ctx context.Context, point PricePoint) error
+	PriceStats(ctx context.Context, alertID int64, currency string, since time.Time) (PriceStats, error)
+	DailyLowestPrices(ctx context.Context, alertID int64, currency string, since time.Time) ([]PricePoint, error)
+}
+
+// ExchangeRateProvider quotes the rate between two currencies. It returns
+// ErrUnknownCurrency when either has no rate.
+type ExchangeRateProvider interface {
+	Rate(ctx context.Context, from, to string) (ExchangeRate, error)
+}
+
+// CooldownStore keeps the last notification of each alert, shared by all
+// worker replicas.
+type CooldownStore interface {
+	// Claim records next as the alert's last notification when the policy
+	// allows it after the current one, atomically across replicas. It
+	// returns the state it replaced, zero when there was none.
+	Claim(ctx context.Context, alertID int64, next CooldownState) (CooldownState, bool, error)
+	// Restore puts previous back when the notification claimed as claimed
+	// was not delivered, unless another claim happened since.
+	Restore(ctx context.Context, alertID int64, claimed, previous CooldownState) error
+}
+
+type IdempotencyStatus int
+
+const (
+	IdempotencyAcquired IdempotencyStatus = iota
+	IdempotencyInProgress
+	IdempotencyCompleted
+)
+
+type IdempotencyStore interface {
+	Acquire(ctx context.Context, key string) (IdempotencyStatus, error)
+	Complete(ctx context.Context, key string) error
+	Release(ctx context.Context, key string) error
 }
@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+// CooldownState is the last notification sent for an alert.
+type CooldownState struct {
+	Price      float64   `json:"price"`
+	Currency   string    `json:"currency"`
+	NotifiedAt time.Time `json:"notifiedAt"`
+}
+
+func (s CooldownState) IsZero() bool {
+	return s.NotifiedAt.IsZero()
+}
+
+// CooldownPolicy suppresses repeat notifications of an alert within Window
+// of the last one, unless the price fell at least MinDropPercent or
+// MinDropAmount below the last notified price. Zero thresholds are ignored;
+// with both at zero nothing breaks the cooldown.
+type CooldownPolicy struct {
+	Window         time.Duration
+	MinDropPercent float64
+	MinDropAmount  float64
+}
+
+func (p CooldownPolicy) Enabled() bool {
+	return p.Window > 0
+}
+
+// Allows reports whether next may be notified after last.
+func (p CooldownPolicy) Allows(last, next CooldownState) bool {
+	if last.IsZero() || next.NotifiedAt.Sub(last.NotifiedAt) >= p.Window {
+		return true
+	}
+	// Prices in different currencies cannot be compared.
+	if last.Currency != next.Currency {
+		return true
+	}
+
+	drop := last.Price - next.Price
+	if p.MinDropAmount > 0 && drop >= p.MinDropAmount {
+		return true
+	}
+	return p.MinDropPercent > 0 && last.Price > 0 && drop/last.Price*100 >= p.MinDropPercent
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCooldownPolicy_Allows(t *testing.T) {
+	notifiedAt := time.Date(2025, 12, 2, 10, 0, 0, 0, time.UTC)
+	last := CooldownState{Price: 1000, Currency: "BRL", NotifiedAt: notifiedAt}
+	next := func(price float64, after time.Duration) CooldownState {
+		return CooldownState{Price: price, Currency: "BRL", NotifiedAt: notifiedAt.Add(after)}
+	}
+
+	testCases := []struct {
+		name     string
+		policy   CooldownPolicy
+		last     CooldownState
+		next     CooldownState
+		expected bool
+	}{
+		{name: "Never notified", policy: CooldownPolicy{Window: time.Hour}, last: CooldownState{}, next: next(999, 0), expected: true},
+		{name: "Window elapsed", policy: CooldownPolicy{Window: time.Hour}, last: last, next: next(1100, time.Hour), expected: true},
+		{name: "Within window", policy: CooldownPolicy{Window: time.Hour}, last: last, next: next(900, 10*time.Minute), expected: false},
+		{name: "Drop percent reached", policy: CooldownPolicy{Window: time.Hour, MinDropPercent: 5}, last: last, next: next(950, time.Minute), expected: true},
+		{name: "Drop percent not reached", policy: CooldownPolicy{Window: time.Hour, MinDropPercent: 5}, last: last, next: next(951, time.Minute), expected: false},
+		{name: "Drop amount reached", policy: CooldownPolicy{Window: time.Hour, MinDropAmount: 30}, last: last, next: next(970, time.Minute), expected: true},
+		{name: "Drop amount not reached", policy: CooldownPolicy{Window: time.Hour, MinDropAmount: 30}, last: last, next: next(970.01, time.Minute), expected: false},
+		{name: "Either threshold", policy: CooldownPolicy{Window: time.Hour, MinDropPercent: 50, MinDropAmount: 30}, last: last, next: next(960, time.Minute), expected: true},
+		{name: "Price went up", policy: CooldownPolicy{Window: time.Hour, MinDropPercent: 5, MinDropAmount: 30}, last: last, next: next(1200, time.Minute), expected: false},
+		{
+			name:     "Different currency",
+			policy:   CooldownPolicy{Window: time.Hour},
+			last:     last,
+			next:     CooldownState{Price: 200, Currency: "USD", NotifiedAt: notifiedAt.Add(time.Minute)},
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.policy.Allows(tc.last, tc.next))
+		})
+	}
+}
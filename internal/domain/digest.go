@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+var ErrNoDigestDue = errors.New("no digest due")
+
+// DigestFrequency is how often a recipient in digest mode receives the
+// summary of their price changes. DigestOff sends every alert right away.
+type DigestFrequency string
+
+const (
+	DigestOff    DigestFrequency = "off"
+	DigestDaily  DigestFrequency = "daily"
+	DigestWeekly DigestFrequency = "weekly"
+)
+
+func (f DigestFrequency) Enabled() bool {
+	return f == DigestDaily || f == DigestWeekly
+}
+
+// DigestSchedule is when digests go out, in each recipient's timezone: daily
+// digests at Hour, weekly digests at Hour on Weekday.
+type DigestSchedule struct {
+	Hour    int
+	Weekday time.Weekday
+}
+
+var DefaultDigestSchedule = DigestSchedule{Hour: 8, Weekday: time.Monday}
+
+// PeriodEnd returns the latest send time at or before now. Items queued
+// before it belong to the digest sent at that time.
+func (s DigestSchedule) PeriodEnd(frequency DigestFrequency, now time.Time, loc *time.Location) time.Time {
+	local := now.In(loc)
+	end := time.Date(local.Year(), local.Month(), local.Day(), s.Hour, 0, 0, 0, loc)
+	if end.After(local) {
+		end = end.AddDate(0, 0, -1)
+	}
+	if frequency == DigestWeekly {
+		for end.Weekday() != s.Weekday {
+			end = end.AddDate(0, 0, -1)
+		}
+	}
+	return end
+}
+
+// DigestItem is a price change waiting for the recipient's next digest.
+type DigestItem struct {
+	ID           int64
+	AlertID      int64
+	MessageID    string
+	Email        string
+	Trigger      Trigger
+	Origin       string
+	Destination  string
+	OutboundDate time.Time
+	ReturnDate   time.Time
+	OldPrice     float64
+	NewPrice     float64
+	TargetPrice  float64
+	Currency     string
+	Link         string
+	CreatedAt    time.Time
+}
+
+func NewDigestItem(alert *Alert, recipient Recipient, trigger Trigger) DigestItem {
+	return DigestItem{
+		AlertID:      alert.ID,
+		MessageID:    alert.MessageID,
+		Email:        recipient.Email,
+		Trigger:      trigger,
+		Origin:       alert.Origin,
+		Destination:  alert.Destination,
+		OutboundDate: alert.OutboundDate,
+		ReturnDate:   alert.ReturnDate,
+		OldPrice:     alert.OldPrice,
+		NewPrice:     alert.NewPrice,
+		TargetPrice:  alert.TargetPrice,
+		Currency:     alert.Currency,
+		Link:         alert.Link,
+	}
+}
+
+func (i DigestItem) DropPercent() float64 {
+	if i.OldPrice <= 0 {
+		return 0
+	}
+	return (i.OldPrice - i.NewPrice) / i.OldPrice * 100
+}
+
+// DigestQueue summarizes the items a recipient has waiting, with the
+// preferences needed to decide whether their digest is due.
+type DigestQueue struct {
+	Email     string
+	Frequency DigestFrequency
+	Timezone  string
+	Oldest    time.Time
+}
+
+// Due reports whether the queue should be closed into a digest at now, and
+// the period end to close it at. Queues of recipients who turned digests off
+// are flushed right away so their items are not lost.
+func (q DigestQueue) Due(schedule DigestSchedule, now time.Time) (time.Time, bool) {
+	if !q.Frequency.Enabled() {
+		return now, true
+	}
+	end := schedule.PeriodEnd(q.Frequency, now, Recipient{Timezone: q.Timezone}.Location())
+	return end, q.Oldest.Before(end)
+}
+
+// Digest is one summary email and the items it carries.
+type Digest struct {
+	ID        int64
+	Email     string
+	Frequency DigestFrequency
+	PeriodEnd time.Time
+	Attempts  int
+	Items     []DigestItem
+}
+
+// SortItems orders the items by biggest drop first.
+func (d *Digest) SortItems() {
+	sort.SliceStable(d.Items, func(i, j int) bool {
+		return d.Items[i].DropPercent() > d.Items[j].DropPercent()
+	})
+}
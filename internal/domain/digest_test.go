@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDigestSchedule_PeriodEnd(t *testing.T) {
+	schedule := DigestSchedule{Hour: 8, Weekday: time.Monday}
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		frequency DigestFrequency
+		now       time.Time
+		expected  time.Time
+	}{
+		// 2025-12-03 is a Wednesday.
+		{name: "Daily, after send hour", frequency: DigestDaily, now: time.Date(2025, 12, 3, 9, 0, 0, 0, madrid), expected: time.Date(2025, 12, 3, 8, 0, 0, 0, madrid)},
+		{name: "Daily, before send hour", frequency: DigestDaily, now: time.Date(2025, 12, 3, 7, 59, 0, 0, madrid), expected: time.Date(2025, 12, 2, 8, 0, 0, 0, madrid)},
+		{name: "Daily, at send hour", frequency: DigestDaily, now: time.Date(2025, 12, 3, 8, 0, 0, 0, madrid), expected: time.Date(2025, 12, 3, 8, 0, 0, 0, madrid)},
+		{name: "Weekly, midweek", frequency: DigestWeekly, now: time.Date(2025, 12, 3, 9, 0, 0, 0, madrid), expected: time.Date(2025, 12, 1, 8, 0, 0, 0, madrid)},
+		{name: "Weekly, send day before send hour", frequency: DigestWeekly, now: time.Date(2025, 12, 8, 7, 0, 0, 0, madrid), expected: time.Date(2025, 12, 1, 8, 0, 0, 0, madrid)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.True(t, tc.expected.Equal(schedule.PeriodEnd(tc.frequency, tc.now.UTC(), madrid)))
+		})
+	}
+}
+
+func TestDigestQueue_Due(t *testing.T) {
+	now := time.Date(2025, 12, 3, 9, 0, 0, 0, time.UTC)
+	periodEnd := time.Date(2025, 12, 3, 8, 0, 0, 0, time.UTC)
+
+	end, due := DigestQueue{Frequency: DigestDaily, Timezone: "UTC", Oldest: periodEnd.Add(-time.Minute)}.Due(DefaultDigestSchedule, now)
+	assert.True(t, due)
+	assert.True(t, periodEnd.Equal(end))
+
+	_, due = DigestQueue{Frequency: DigestDaily, Timezone: "UTC", Oldest: periodEnd.Add(time.Minute)}.Due(DefaultDigestSchedule, now)
+	assert.False(t, due)
+
+	end, due = DigestQueue{Frequency: DigestOff, Oldest: now.Add(-time.Minute)}.Due(DefaultDigestSchedule, now)
+	assert.True(t, due)
+	assert.Equal(t, now, end)
+}
+
+func TestDigest_SortItems(t *testing.T) {
+	digest := Digest{Items: []DigestItem{
+		{ID: 1, OldPrice: 1000, NewPrice: 900},
+		{ID: 2, OldPrice: 1000, NewPrice: 600},
+		{ID: 3, OldPrice: 0, NewPrice: 500},
+		{ID: 4, OldPrice: 2000, NewPrice: 1800},
+	}}
+
+	digest.SortItems()
+
+	var ids []int64
+	for _, item := range digest.Items {
+		ids = append(ids, item.ID)
+	}
+	assert.Equal(t, []int64{2, 1, 4, 3}, ids)
+}
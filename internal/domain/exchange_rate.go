@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// ExchangeRate converts amounts in From to To, as quoted at AsOf. The zero
+// value means there is nothing to convert.
+type ExchangeRate struct {
+	From string
+	To   string
+	Rate float64
+	AsOf time.Time
+}
+
+func (r ExchangeRate) IsZero() bool {
+	return r.Rate <= 0
+}
+
+func (r ExchangeRate) Convert(amount float64) float64 {
+	return amount * r.Rate
+}
+
+// Age returns how old the quote is at now, never negative.
+func (r ExchangeRate) Age(now time.Time) time.Duration {
+	return max(now.Sub(r.AsOf), 0)
+}
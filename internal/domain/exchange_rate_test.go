@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExchangeRate(t *testing.T) {
+	asOf := time.Date(2025, 12, 2, 12, 0, 0, 0, time.UTC)
+	rate := ExchangeRate{From: "BRL", To: "USD", Rate: 0.19, AsOf: asOf}
+
+	assert.False(t, rate.IsZero())
+	assert.True(t, ExchangeRate{}.IsZero())
+	assert.InDelta(t, 342.0, rate.Convert(1800), 0.0001)
+	assert.Equal(t, 3*time.Hour, rate.Age(asOf.Add(3*time.Hour)))
+	assert.Zero(t, rate.Age(asOf.Add(-time.Minute)))
+}
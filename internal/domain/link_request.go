@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type CabinClass string
+
+const (
+	CabinEconomy        CabinClass = "economy"
+	CabinPremiumEconomy CabinClass = "premium_economy"
+	CabinBusiness       CabinClass = "business"
+	CabinFirst          CabinClass = "first"
+)
+
+func ParseCabinClass(value string) (CabinClass, error) {
+	switch cabin := CabinClass(value); cabin {
+	case "":
+		return CabinEconomy, nil
+	case CabinEconomy, CabinPremiumEconomy, CabinBusiness, CabinFirst:
+		return cabin, nil
+	default:
+		return "", fmt.Errorf("unknown cabin class %q", value)
+	}
+}
+
+// LinkRequest describes the itinerary a booking link should open. A zero
+// ReturnDate means a one-way trip; empty Providers means the configured
+// default providers. AlertID and MessageID identify the price.updated event
+// the link is generated for.
+type LinkRequest struct {
+	AlertID      int64
+	MessageID    string
+	Origin       string
+	Destination  string
+	OutboundDate time.Time
+	ReturnDate   time.Time
+	Cabin        CabinClass
+	Passengers   int
+	Providers    []string
+}
+
+func (r LinkRequest) OneWay() bool {
+	return r.ReturnDate.IsZero()
+}
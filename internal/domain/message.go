@@ -0,0 +1,12 @@
+package domain
+
+// Message is an alert rendered for one recipient. Content is the localized
+// email; chat channels reuse its subject and text body. Address is filled in
+// per channel from the recipient's preferences.
+type Message struct {
+	Alert     *Alert
+	Recipient Recipient
+	Trigger   Trigger
+	Content   *AlertEmail
+	Address   string
+}
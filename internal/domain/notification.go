@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+	// ErrPermanentDelivery is matched, through errors.Is, by delivery errors
+	// that retrying cannot fix, such as a rejected recipient.
+	ErrPermanentDelivery = errors.New("permanent delivery failure")
+)
+
+type NotificationStatus string
+
+const (
+	NotificationPending NotificationStatus = "pending"
+	NotificationSent    NotificationStatus = "sent"
+	NotificationFailed  NotificationStatus = "failed"
+)
+
+// Notification is one delivery attempt of an alert on a channel. Recipient
+// is always the user's email; Response holds the provider's reply when the
+// message was accepted, or the error when it failed.
+type Notification struct {
+	ID        int64
+	AlertID   int64
+	MessageID string
+	Recipient string
+	Channel   Channel
+	Subject   string
+	Trigger   Trigger
+	OldPrice  float64
+	NewPrice  float64
+	Currency  string
+	Status    NotificationStatus
+	Response  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
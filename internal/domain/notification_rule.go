@@ -0,0 +1,75 @@
+package domain
+
+type Trigger string
+
+const (
+	TriggerTargetReached   Trigger = "target_reached"
+	TriggerWithinTolerance Trigger = "within_tolerance"
+	TriggerPriceDrop       Trigger = "price_drop"
+)
+
+type SkipReason string
+
+const (
+	SkipInvalidPrice     SkipReason = "invalid_price"
+	SkipPriceNotDropped  SkipReason = "price_not_dropped"
+	SkipNoMeaningfulDrop SkipReason = "no_meaningful_drop"
+	SkipOptedOut         SkipReason = "opted_out"
+	SkipNoChannel        SkipReason = "no_channel"
+	SkipQuietHours       SkipReason = "quiet_hours"
+	SkipCooldown         SkipReason = "cooldown"
+	SkipDeferred         SkipReason = "deferred"
+)
+
+type Decision struct {
+	Notify  bool
+	Trigger Trigger
+	Skip    SkipReason
+}
+
+func NotifyDecision(trigger Trigger) Decision {
+	return Decision{Notify: true, Trigger: trigger}
+}
+
+func SkipDecision(reason SkipReason) Decision {
+	return Decision{Skip: reason}
+}
+
+type NotificationRules struct {
+	MinDropPercent float64
+	// UrgentDropPercent is the drop at which an alert is urgent: likely to
+	// be short-lived, so worth sending during quiet hours to recipients who
+	// opted in. Zero disables it.
+	UrgentDropPercent float64
+}
+
+var DefaultNotificationRules = NotificationRules{MinDropPercent: 10, UrgentDropPercent: 30}
+
+func (r NotificationRules) Urgent(alert *Alert) bool {
+	return r.UrgentDropPercent > 0 && alert.DropPercent() >= r.UrgentDropPercent
+}
+
+func (r NotificationRules) Evaluate(alert *Alert) Decision {
+	if alert.NewPrice <= 0 {
+		return SkipDecision(SkipInvalidPrice)
+	}
+
+	if alert.OldPrice > 0 && alert.NewPrice >= alert.OldPrice {
+		return SkipDecision(SkipPriceNotDropped)
+	}
+
+	if alert.TargetPrice > 0 {
+		if alert.NewPrice <= alert.TargetPrice {
+			return NotifyDecision(TriggerTargetReached)
+		}
+		if alert.ToleranceUp > 0 && alert.NewPrice <= alert.TargetPrice+alert.ToleranceUp {
+			return NotifyDecision(TriggerWithinTolerance)
+		}
+	}
+
+	if alert.OldPrice > 0 && alert.DropPercent() >= r.MinDropPercent {
+		return NotifyDecision(TriggerPriceDrop)
+	}
+
+	return SkipDecision(SkipNoMeaningfulDrop)
+}
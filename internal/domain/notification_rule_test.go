@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationRules_Evaluate(t *testing.T) {
+	rules := NotificationRules{MinDropPercent: 10}
+
+	testCases := []struct {
+		name     string
+		alert    Alert
+		expected Decision
+	}{
+		{
+			name:     "Below target",
+			alert:    Alert{OldPrice: 2500, NewPrice: 1800, TargetPrice: 2000},
+			expected: NotifyDecision(TriggerTargetReached),
+		},
+		{
+			name:     "Exactly at target",
+			alert:    Alert{OldPrice: 2100, NewPrice: 2000, TargetPrice: 2000},
+			expected: NotifyDecision(TriggerTargetReached),
+		},
+		{
+			name:     "Within tolerance band",
+			alert:    Alert{OldPrice: 2200, NewPrice: 2080, TargetPrice: 2000, ToleranceUp: 100},
+			expected: NotifyDecision(TriggerWithinTolerance),
+		},
+		{
+			name:     "Above tolerance band with meaningful drop",
+			alert:    Alert{OldPrice: 3000, NewPrice: 2500, TargetPrice: 2000, ToleranceUp: 100},
+			expected: NotifyDecision(TriggerPriceDrop),
+		},
+		{
+			name:     "Above tolerance band with small drop",
+			alert:    Alert{OldPrice: 2600, NewPrice: 2500, TargetPrice: 2000, ToleranceUp: 100},
+			expected: SkipDecision(SkipNoMeaningfulDrop),
+		},
+		{
+			name:     "Price increased",
+			alert:    Alert{OldPrice: 1800, NewPrice: 1900, TargetPrice: 2000},
+			expected: SkipDecision(SkipPriceNotDropped),
+		},
+		{
+			name:     "Price unchanged",
+			alert:    Alert{OldPrice: 1800, NewPrice: 1800, TargetPrice: 2000},
+			expected: SkipDecision(SkipPriceNotDropped),
+		},
+		{
+			name:     "First observation below target",
+			alert:    Alert{NewPrice: 1800, TargetPrice: 2000},
+			expected: NotifyDecision(TriggerTargetReached),
+		},
+		{
+			name:     "First observation without target",
+			alert:    Alert{NewPrice: 1800},
+			expected: SkipDecision(SkipNoMeaningfulDrop),
+		},
+		{
+			name:     "Invalid price",
+			alert:    Alert{OldPrice: 1800, NewPrice: 0, TargetPrice: 2000},
+			expected: SkipDecision(SkipInvalidPrice),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, rules.Evaluate(&tc.alert))
+		})
+	}
+}
+
+func TestAlert_DropPercent(t *testing.T) {
+	assert.InDelta(t, 28.0, (&Alert{OldPrice: 2500, NewPrice: 1800}).DropPercent(), 0.001)
+	assert.InDelta(t, -10.0, (&Alert{OldPrice: 1000, NewPrice: 1100}).DropPercent(), 0.001)
+	assert.Equal(t, 0.0, (&Alert{NewPrice: 1100}).DropPercent())
+}
+
+func TestNotificationRules_Urgent(t *testing.T) {
+	rules := NotificationRules{MinDropPercent: 10, UrgentDropPercent: 30}
+
+	assert.True(t, rules.Urgent(&Alert{OldPrice: 1000, NewPrice: 700}))
+	assert.False(t, rules.Urgent(&Alert{OldPrice: 1000, NewPrice: 701}))
+	assert.False(t, NotificationRules{MinDropPercent: 10}.Urgent(&Alert{OldPrice: 1000, NewPrice: 100}))
+}
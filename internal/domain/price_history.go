@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// PricePoint is a price the Search Service checked for an alert.
+type PricePoint struct {
+	AlertID   int64
+	Price     float64
+	Currency  string
+	CheckedAt time.Time
+}
+
+// PriceStats summarizes the prices of an alert over a window. Count is zero
+// when no price was recorded in it.
+type PriceStats struct {
+	Min     float64
+	Max     float64
+	Average float64
+	Count   int
+}
+
+// PriceTrend is the history shown next to a new price: the stats of the last
+// Days days and the lowest price of each of them, oldest first.
+type PriceTrend struct {
+	Days  int
+	Stats PriceStats
+	Daily []PricePoint
+}
+
+// Comparable reports whether there are enough prices to compare a new one
+// against.
+func (t PriceTrend) Comparable() bool {
+	return t.Stats.Count > 1
+}
+
+// Lowest reports whether price is the lowest of the window, to the cent.
+func (t PriceTrend) Lowest(price float64) bool {
+	return t.Comparable() && math.Round(price*100) <= math.Round(t.Stats.Min*100)
+}
+
+// BelowAverage returns how far price is below the window average, in
+// percent, or zero when it is not below it.
+func (t PriceTrend) BelowAverage(price float64) float64 {
+	if !t.Comparable() || t.Stats.Average <= 0 || price >= t.Stats.Average {
+		return 0
+	}
+	return (t.Stats.Average - price) / t.Stats.Average * 100
+}
+
+// DailyPrices returns the lowest price of each day, oldest first.
+func (t PriceTrend) DailyPrices() []float64 {
+	prices := make([]float64, len(t.Daily))
+	for i, point := range t.Daily {
+		prices[i] = point.Price
+	}
+	return prices
+}
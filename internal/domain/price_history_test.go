@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceTrend_Lowest(t *testing.T) {
+	trend := PriceTrend{Days: 30, Stats: PriceStats{Min: 1800, Max: 2500, Average: 2100, Count: 12}}
+
+	testCases := []struct {
+		name     string
+		trend    PriceTrend
+		price    float64
+		expected bool
+	}{
+		{name: "Equals minimum", trend: trend, price: 1800, expected: true},
+		{name: "Below minimum", trend: trend, price: 1750, expected: true},
+		{name: "Rounds to the cent", trend: trend, price: 1800.004, expected: true},
+		{name: "Above minimum", trend: trend, price: 1800.01, expected: false},
+		{name: "Single price", trend: PriceTrend{Days: 30, Stats: PriceStats{Min: 1800, Max: 1800, Average: 1800, Count: 1}}, price: 1800, expected: false},
+		{name: "No history", trend: PriceTrend{}, price: 1800, expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.trend.Lowest(tc.price))
+		})
+	}
+}
+
+func TestPriceTrend_BelowAverage(t *testing.T) {
+	trend := PriceTrend{Days: 30, Stats: PriceStats{Min: 1800, Max: 2500, Average: 2000, Count: 12}}
+
+	assert.InDelta(t, 10.0, trend.BelowAverage(1800), 0.001)
+	assert.Zero(t, trend.BelowAverage(2000))
+	assert.Zero(t, trend.BelowAverage(2100))
+	assert.Zero(t, PriceTrend{}.BelowAverage(1800))
+}
+
+func TestPriceTrend_DailyPrices(t *testing.T) {
+	day := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	trend := PriceTrend{Daily: []PricePoint{
+		{Price: 2100, CheckedAt: day},
+		{Price: 1950, CheckedAt: day.AddDate(0, 0, 1)},
+	}}
+
+	assert.Equal(t, []float64{2100, 1950}, trend.DailyPrices())
+}
@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+type Channel string
+
+const (
+	ChannelEmail    Channel = "email"
+	ChannelWebhook  Channel = "webhook"
+	ChannelSlack    Channel = "slack"
+	ChannelTelegram Channel = "telegram"
+)
+
+const (
+	DefaultLocale   = "pt-BR"
+	DefaultTimezone = "America/Sao_Paulo"
+)
+
+var DefaultChannels = []Channel{ChannelEmail}
+
+// QuietHours is a daily window, in minutes after midnight in the recipient's
+// timezone, during which nothing is sent. The window may wrap past midnight
+// (22:00-07:00); equal bounds disable it.
+type QuietHours struct {
+	Start int
+	End   int
+}
+
+func (q QuietHours) Enabled() bool {
+	return q.Start != q.End
+}
+
+func (q QuietHours) Contains(t time.Time) bool {
+	if !q.Enabled() {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if q.Start < q.End {
+		return minute >= q.Start && minute < q.End
+	}
+	return minute >= q.Start || minute < q.End
+}
+
+// EndAfter returns when the window containing t ends, in t's location.
+func (q QuietHours) EndAfter(t time.Time) time.Time {
+	end := time.Date(t.Year(), t.Month(), t.Day(), q.End/60, q.End%60, 0, 0, t.Location())
+	if !end.After(t) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+// Recipient is the user behind an alert and how they want to be notified.
+type Recipient struct {
+	AlertID  int64
+	Name     string
+	Email    string
+	Locale   string
+	Timezone string
+	// Currency is the currency prices are also shown in, converted from the
+	// alert's; empty shows them only as they came.
+	Currency   string
+	Channels   []Channel
+	QuietHours QuietHours
+	// UrgentInQuietHours lets urgent alerts through quiet hours.
+	UrgentInQuietHours bool
+	OptedOut           bool
+	OptedOutTriggers   []Trigger
+	WebhookURL         string
+	SlackWebhookURL    string
+	TelegramChatID     string
+	Digest             DigestFrequency
+	// LinkProviders are the booking sites the user picked, in order; empty
+	// uses the defaults.
+	LinkProviders []string
+}
+
+// NewRecipient returns a recipient with the default preferences.
+func NewRecipient(alertID int64, email string) Recipient {
+	return Recipient{
+		AlertID:  alertID,
+		Email:    email,
+		Locale:   DefaultLocale,
+		Timezone: DefaultTimezone,
+		Channels: DefaultChannels,
+		Digest:   DigestOff,
+	}
+}
+
+// Location returns the recipient's timezone, falling back to the default
+// when it is empty or unknown.
+func (r Recipient) Location() *time.Location {
+	for _, name := range []string{r.Timezone, DefaultTimezone} {
+		if name == "" {
+			continue
+		}
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+func (r Recipient) HasChannel(channel Channel) bool {
+	return slices.Contains(r.Channels, channel)
+}
+
+// Address returns where the recipient receives messages on channel, or an
+// empty string when they have not configured it.
+func (r Recipient) Address(channel Channel) string {
+	switch channel {
+	case ChannelEmail:
+		return r.Email
+	case ChannelWebhook:
+		return r.WebhookURL
+	case ChannelSlack:
+		return r.SlackWebhookURL
+	case ChannelTelegram:
+		return r.TelegramChatID
+	default:
+		return ""
+	}
+}
+
+// QuietHoursEnd returns when the quiet hours that contain now end.
+func (r Recipient) QuietHoursEnd(now time.Time) time.Time {
+	return r.QuietHours.EndAfter(now.In(r.Location()))
+}
+
+// Evaluate applies the recipient's preferences to a decision of the
+// notification rules: opt-outs, enabled channels and quiet hours at now.
+func (r Recipient) Evaluate(decision Decision, now time.Time) Decision {
+	if !decision.Notify {
+		return decision
+	}
+	if r.OptedOut || slices.Contains(r.OptedOutTriggers, decision.Trigger) {
+		return SkipDecision(SkipOptedOut)
+	}
+	if len(r.Channels) == 0 {
+		return SkipDecision(SkipNoChannel)
+	}
+	if r.QuietHours.Contains(now.In(r.Location())) {
+		return SkipDecision(SkipQuietHours)
+	}
+	return decision
+}
@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuietHours_Contains(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2025, 12, 2, hour, minute, 0, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		name     string
+		quiet    QuietHours
+		t        time.Time
+		expected bool
+	}{
+		{name: "Disabled", quiet: QuietHours{}, t: at(3, 0), expected: false},
+		{name: "Same day window, inside", quiet: QuietHours{Start: 13 * 60, End: 14 * 60}, t: at(13, 30), expected: true},
+		{name: "Same day window, end is exclusive", quiet: QuietHours{Start: 13 * 60, End: 14 * 60}, t: at(14, 0), expected: false},
+		{name: "Overnight window, before midnight", quiet: QuietHours{Start: 22 * 60, End: 7 * 60}, t: at(23, 15), expected: true},
+		{name: "Overnight window, after midnight", quiet: QuietHours{Start: 22 * 60, End: 7 * 60}, t: at(3, 0), expected: true},
+		{name: "Overnight window, daytime", quiet: QuietHours{Start: 22 * 60, End: 7 * 60}, t: at(12, 0), expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.quiet.Contains(tc.t))
+		})
+	}
+}
+
+func TestQuietHours_EndAfter(t *testing.T) {
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	assert.NoError(t, err)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, 12, day, hour, minute, 0, 0, saoPaulo)
+	}
+
+	testCases := []struct {
+		name     string
+		quiet    QuietHours
+		t        time.Time
+		expected time.Time
+	}{
+		{name: "Overnight window, before midnight", quiet: QuietHours{Start: 22 * 60, End: 7 * 60}, t: at(2, 23, 15), expected: at(3, 7, 0)},
+		{name: "Overnight window, after midnight", quiet: QuietHours{Start: 22 * 60, End: 7 * 60}, t: at(3, 3, 0), expected: at(3, 7, 0)},
+		{name: "Same day window", quiet: QuietHours{Start: 13 * 60, End: 14*60 + 30}, t: at(2, 13, 45), expected: at(2, 14, 30)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.True(t, tc.expected.Equal(tc.quiet.EndAfter(tc.t)))
+		})
+	}
+}
+
+func TestRecipient_QuietHoursEnd(t *testing.T) {
+	recipient := Recipient{Timezone: "Asia/Tokyo", QuietHours: QuietHours{Start: 22 * 60, End: 7 * 60}}
+
+	// 15:00 UTC is 00:00 in Tokyo; 07:00 there is 22:00 UTC.
+	end := recipient.QuietHoursEnd(time.Date(2025, 12, 2, 15, 0, 0, 0, time.UTC))
+
+	assert.True(t, time.Date(2025, 12, 2, 22, 0, 0, 0, time.UTC).Equal(end))
+}
+
+func TestRecipient_Location(t *testing.T) {
+	assert.Equal(t, "Europe/Madrid", Recipient{Timezone: "Europe/Madrid"}.Location().String())
+	assert.Equal(t, DefaultTimezone, Recipient{Timezone: "Mars/Olympus_Mons"}.Location().String())
+	assert.Equal(t, DefaultTimezone, Recipient{}.Location().String())
+}
+
+func TestRecipient_Evaluate(t *testing.T) {
+	// 06:00 UTC is 03:00 in São Paulo.
+	now := time.Date(2025, 12, 2, 6, 0, 0, 0, time.UTC)
+	notify := NotifyDecision(TriggerPriceDrop)
+
+	testCases := []struct {
+		name      string
+		recipient func(r *Recipient)
+		decision  Decision
+		expected  Decision
+	}{
+		{name: "Defaults", recipient: func(r *Recipient) {}, decision: notify, expected: notify},
+		{name: "Already skipped", recipient: func(r *Recipient) { r.OptedOut = true }, decision: SkipDecision(SkipNoMeaningfulDrop), expected: SkipDecision(SkipNoMeaningfulDrop)},
+		{name: "Opted out", recipient: func(r *Recipient) { r.OptedOut = true }, decision: notify, expected: SkipDecision(SkipOptedOut)},
+		{name: "Opted out of trigger", recipient: func(r *Recipient) { r.OptedOutTriggers = []Trigger{TriggerPriceDrop} }, decision: notify, expected: SkipDecision(SkipOptedOut)},
+		{name: "Opted out of another trigger", recipient: func(r *Recipient) { r.OptedOutTriggers = []Trigger{TriggerWithinTolerance} }, decision: notify, expected: notify},
+		{name: "No channels", recipient: func(r *Recipient) { r.Channels = nil }, decision: notify, expected: SkipDecision(SkipNoChannel)},
+		{name: "Quiet hours in recipient timezone", recipient: func(r *Recipient) { r.QuietHours = QuietHours{Start: 22 * 60, End: 7 * 60} }, decision: notify, expected: SkipDecision(SkipQuietHours)},
+		{
+			name: "Outside quiet hours in recipient timezone",
+			recipient: func(r *Recipient) {
+				r.Timezone = "Asia/Tokyo"
+				r.QuietHours = QuietHours{Start: 22 * 60, End: 7 * 60}
+			},
+			decision: notify,
+			expected: notify,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recipient := NewRecipient(1, "user@example.com")
+			tc.recipient(&recipient)
+
+			assert.Equal(t, tc.expected, recipient.Evaluate(tc.decision, now))
+		})
+	}
+}
+
+func TestRecipient_Address(t *testing.T) {
+	recipient := NewRecipient(1, "user@example.com")
+	recipient.WebhookURL = "https://hooks.example.com/alerts"
+	recipient.TelegramChatID = "123456"
+
+	assert.Equal(t, "user@example.com", recipient.Address(ChannelEmail))
+	assert.Equal(t, "https://hooks.example.com/alerts", recipient.Address(ChannelWebhook))
+	assert.Equal(t, "123456", recipient.Address(ChannelTelegram))
+	assert.Empty(t, recipient.Address(ChannelSlack))
+	assert.Empty(t, recipient.Address("sms"))
+}
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrNoScheduledAlertDue = errors.New("no scheduled alert due")
+
+// ScheduledAlert is an alert held back by the recipient's quiet hours, to be
+// processed again at DeliverAt. Version changes whenever a newer alert
+// replaces the pending one.
+type ScheduledAlert struct {
+	ID        int64
+	Alert     Alert
+	DeliverAt time.Time
+	Attempts  int
+	Version   int64
+}
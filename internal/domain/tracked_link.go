@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrTrackedLinkNotFound = errors.New("tracked link not found")
+
+// TrackedLink is a booking link stored behind a signed redirect, so clicks
+// can be attributed to the alert and price.updated event that produced it.
+type TrackedLink struct {
+	ID        string
+	AlertID   int64
+	MessageID string
+	Provider  string
+	URL       string
+	CreatedAt time.Time
+}
+
+type LinkClick struct {
+	LinkID    string
+	AlertID   int64
+	Provider  string
+	UserAgent string
+	ClickedAt time.Time
+}
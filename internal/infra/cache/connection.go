@@ -1,6 +1,9 @@
 package cache
 
 import (
+	"context"
+	"time"
+
 	"github.com/redis/go-redis/v9"
 )
 
@@ -13,5 +16,13 @@ func CacheConnection(addr string, password string, username string, db int) (*re
 		Protocol: 2,
 	})
 
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := connection.Ping(ctx).Err(); err != nil {
+		connection.Close()
+		return nil, err
+	}
+
 	return connection, nil
 }
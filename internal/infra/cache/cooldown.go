@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	cooldownPrefix = "alert-service:cooldown:"
+
+	// cooldownAttempts bounds the optimistic transactions retried when
+	// another replica writes the same alert concurrently.
+	cooldownAttempts = 3
+)
+
+var ErrCooldownContention = errors.New("cooldown state kept changing during claim")
+
+// CooldownStore keeps the last notification of each alert in Redis for the
+// policy window. Claims run in WATCH/MULTI transactions, so two replicas
+// handling updates of the same alert cannot both notify.
+type CooldownStore struct {
+	client redis.UniversalClient
+	policy domain.CooldownPolicy
+	now    func() time.Time
+}
+
+func NewCooldownStore(client redis.UniversalClient, policy domain.CooldownPolicy) *CooldownStore {
+	return &CooldownStore{client: client, policy: policy, now: time.Now}
+}
+
+func (s *CooldownStore) Claim(ctx context.Context, alertID int64, next domain.CooldownState) (domain.CooldownState, bool, error) {
+	key := cooldownKey(alertID)
+	value, err := json.Marshal(next)
+	if err != nil {
+		return domain.CooldownState{}, false, err
+	}
+
+	var previous domain.CooldownState
+	var claimed bool
+	claim := func(tx *redis.Tx) error {
+		var err error
+		previous, _, err = getCooldown(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+		if claimed = s.policy.Allows(previous, next); !claimed {
+			return nil
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, value, s.policy.Window)
+			return nil
+		})
+		return err
+	}
+
+	for attempt := 0; attempt < cooldownAttempts; attempt++ {
+		err := s.client.Watch(ctx, claim, key)
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		if err != nil {
+			return domain.CooldownState{}, false, err
+		}
+		return previous, claimed, nil
+	}
+	return domain.CooldownState{}, false, ErrCooldownContention
+}
+
+func (s *CooldownStore) Restore(ctx context.Context, alertID int64, claimed, previous domain.CooldownState) error {
+	key := cooldownKey(alertID)
+	expected, err := json.Marshal(claimed)
+	if err != nil {
+		return err
+	}
+
+	restore := func(tx *redis.Tx) error {
+		_, current, err := getCooldown(ctx, tx, key)
+		if err != nil || !bytes.Equal(current, expected) {
+			return err
+		}
+
+		ttl := previous.NotifiedAt.Add(s.policy.Window).Sub(s.now())
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			if previous.IsZero() || ttl <= 0 {
+				pipe.Del(ctx, key)
+				return nil
+			}
+			value, err := json.Marshal(previous)
+			if err != nil {
+				return err
+			}
+			pipe.Set(ctx, key, value, ttl)
+			return nil
+		})
+		return err
+	}
+
+	err = s.client.Watch(ctx, restore, key)
+	if errors.Is(err, redis.TxFailedErr) {
+		// Another replica claimed the alert meanwhile; its state stands.
+		return nil
+	}
+	return err
+}
+
+func getCooldown(ctx context.Context, tx *redis.Tx, key string) (domain.CooldownState, []byte, error) {
+	value, err := tx.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return domain.CooldownState{}, nil, nil
+	}
+	if err != nil {
+		return domain.CooldownState{}, nil, err
+	}
+
+	var state domain.CooldownState
+	if err := json.Unmarshal(value, &state); err != nil {
+		return domain.CooldownState{}, nil, err
+	}
+	return state, value, nil
+}
+
+func cooldownKey(alertID int64) string {
+	return cooldownPrefix + strconv.FormatInt(alertID, 10)
+}
@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testCooldownPolicy = domain.CooldownPolicy{Window: time.Hour, MinDropPercent: 5}
+
+func newTestCooldownStore(t *testing.T) (*CooldownStore, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewCooldownStore(client, testCooldownPolicy), server
+}
+
+func cooldownState(price float64, at time.Time) domain.CooldownState {
+	return domain.CooldownState{Price: price, Currency: "BRL", NotifiedAt: at}
+}
+
+func TestCooldownStore_Claim(t *testing.T) {
+	store, server := newTestCooldownStore(t)
+	ctx := context.Background()
+	now := time.Date(2025, 12, 2, 10, 0, 0, 0, time.UTC)
+	first := cooldownState(1000, now)
+
+	previous, claimed, err := store.Claim(ctx, 42, first)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+	assert.True(t, previous.IsZero())
+	assert.Equal(t, time.Hour, server.TTL(cooldownPrefix+"42"))
+
+	previous, claimed, err = store.Claim(ctx, 42, cooldownState(990, now.Add(time.Minute)))
+	require.NoError(t, err)
+	assert.False(t, claimed)
+	assert.Equal(t, first, previous)
+
+	previous, claimed, err = store.Claim(ctx, 42, cooldownState(900, now.Add(2*time.Minute)))
+	require.NoError(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, first, previous)
+}
+
+func TestCooldownStore_Claim_AlertsAreIndependent(t *testing.T) {
+	store, _ := newTestCooldownStore(t)
+	ctx := context.Background()
+	now := time.Date(2025, 12, 2, 10, 0, 0, 0, time.UTC)
+
+	_, claimed, err := store.Claim(ctx, 42, cooldownState(1000, now))
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	_, claimed, err = store.Claim(ctx, 43, cooldownState(1000, now))
+	require.NoError(t, err)
+	assert.True(t, claimed)
+}
+
+func TestCooldownStore_Restore(t *testing.T) {
+	store, server := newTestCooldownStore(t)
+	ctx := context.Background()
+	now := time.Date(2025, 12, 2, 10, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now.Add(10 * time.Minute) }
+
+	first := cooldownState(1000, now)
+	_, _, err := store.Claim(ctx, 42, first)
+	require.NoError(t, err)
+	second := cooldownState(900, now.Add(10*time.Minute))
+	previous, claimed, err := store.Claim(ctx, 42, second)
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	require.NoError(t, store.Restore(ctx, 42, second, previous))
+
+	_, claimed, err = store.Claim(ctx, 42, cooldownState(990, now.Add(11*time.Minute)))
+	require.NoError(t, err)
+	assert.False(t, claimed, "the first notification's cooldown is back")
+	assert.Equal(t, 50*time.Minute, server.TTL(cooldownPrefix+"42"))
+}
+
+func TestCooldownStore_Restore_FirstClaimDeletesState(t *testing.T) {
+	store, server := newTestCooldownStore(t)
+	ctx := context.Background()
+	claimedState := cooldownState(1000, time.Date(2025, 12, 2, 10, 0, 0, 0, time.UTC))
+
+	previous, _, err := store.Claim(ctx, 42, claimedState)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Restore(ctx, 42, claimedState, previous))
+
+	assert.False(t, server.Exists(cooldownPrefix+"42"))
+}
+
+func TestCooldownStore_Restore_KeepsNewerClaim(t *testing.T) {
+	store, server := newTestCooldownStore(t)
+	ctx := context.Background()
+	now := time.Date(2025, 12, 2, 10, 0, 0, 0, time.UTC)
+
+	stale := cooldownState(1000, now)
+	_, _, err := store.Claim(ctx, 42, stale)
+	require.NoError(t, err)
+	server.Del(cooldownPrefix + "42")
+	_, _, err = store.Claim(ctx, 42, cooldownState(900, now.Add(time.Minute)))
+	require.NoError(t, err)
+
+	require.NoError(t, store.Restore(ctx, 42, stale, domain.CooldownState{}))
+
+	assert.True(t, server.Exists(cooldownPrefix+"42"))
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	DefaultProcessingTTL = 5 * time.Minute
+	DefaultCompletedTTL  = 7 * 24 * time.Hour
+
+	idempotencyPrefix = "alert-service:idempotency:"
+	stateProcessing   = "processing"
+	stateCompleted    = "completed"
+)
+
+type IdempotencyStore struct {
+	client        redis.Cmdable
+	processingTTL time.Duration
+	completedTTL  time.Duration
+}
+
+func NewIdempotencyStore(client redis.Cmdable, processingTTL, completedTTL time.Duration) *IdempotencyStore {
+	return &IdempotencyStore{
+		client:        client,
+		processingTTL: processingTTL,
+		completedTTL:  completedTTL,
+	}
+}
+
+func (s *IdempotencyStore) Acquire(ctx context.Context, key string) (domain.IdempotencyStatus, error) {
+	redisKey := idempotencyPrefix + key
+
+	// The key can expire between SETNX and GET, so try once more before
+	// giving up.
+	for attempt := 0; attempt < 2; attempt++ {
+		acquired, err := s.client.SetNX(ctx, redisKey, stateProcessing, s.processingTTL).Result()
+		if err != nil {
+			return 0, err
+		}
+		if acquired {
+			return domain.IdempotencyAcquired, nil
+		}
+
+		state, err := s.client.Get(ctx, redisKey).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		switch state {
+		case stateCompleted:
+			return domain.IdempotencyCompleted, nil
+		case stateProcessing:
+			return domain.IdempotencyInProgress, nil
+		default:
+			return 0, fmt.Errorf("unknown idempotency state %q for key %s", state, key)
+		}
+	}
+
+	return domain.IdempotencyInProgress, nil
+}
+
+func (s *IdempotencyStore) Complete(ctx context.Context, key string) error {
+	return s.client.Set(ctx, idempotencyPrefix+key, stateCompleted, s.completedTTL).Err()
+}
+
+func (s *IdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.client.Del(ctx, idempotencyPrefix+key).Err()
+}
@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedisStore(t *testing.T) (*IdempotencyStore, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewIdempotencyStore(client, time.Minute, time.Hour), server
+}
+
+func TestIdempotencyStore_Acquire_FirstTime(t *testing.T) {
+	store, server := newTestRedisStore(t)
+
+	status, err := store.Acquire(context.Background(), "msg-123")
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.IdempotencyAcquired, status)
+	value, err := server.Get(idempotencyPrefix + "msg-123")
+	require.NoError(t, err)
+	assert.Equal(t, stateProcessing, value)
+	assert.Equal(t, time.Minute, server.TTL(idempotencyPrefix+"msg-123"))
+}
+
+func TestIdempotencyStore_Acquire_InProgress(t *testing.T) {
+	store, _ := newTestRedisStore(t)
+	ctx := context.Background()
+
+	_, err := store.Acquire(ctx, "msg-123")
+	require.NoError(t, err)
+
+	status, err := store.Acquire(ctx, "msg-123")
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.IdempotencyInProgress, status)
+}
+
+func TestIdempotencyStore_Acquire_Completed(t *testing.T) {
+	store, server := newTestRedisStore(t)
+	ctx := context.Background()
+
+	_, err := store.Acquire(ctx, "msg-123")
+	require.NoError(t, err)
+	require.NoError(t, store.Complete(ctx, "msg-123"))
+
+	status, err := store.Acquire(ctx, "msg-123")
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.IdempotencyCompleted, status)
+	assert.Equal(t, time.Hour, server.TTL(idempotencyPrefix+"msg-123"))
+}
+
+func TestIdempotencyStore_Acquire_AfterCrashExpires(t *testing.T) {
+	store, server := newTestRedisStore(t)
+	ctx := context.Background()
+
+	_, err := store.Acquire(ctx, "msg-123")
+	require.NoError(t, err)
+
+	server.FastForward(2 * time.Minute)
+
+	status, err := store.Acquire(ctx, "msg-123")
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.IdempotencyAcquired, status)
+}
+
+func TestIdempotencyStore_Release(t *testing.T) {
+	store, server := newTestRedisStore(t)
+	ctx := context.Background()
+
+	_, err := store.Acquire(ctx, "msg-123")
+	require.NoError(t, err)
+	require.NoError(t, store.Release(ctx, "msg-123"))
+
+	assert.False(t, server.Exists(idempotencyPrefix+"msg-123"))
+
+	status, err := store.Acquire(ctx, "msg-123")
+	require.NoError(t, err)
+	assert.Equal(t, domain.IdempotencyAcquired, status)
+}
+
+func TestIdempotencyStore_Acquire_UnknownState(t *testing.T) {
+	store, server := newTestRedisStore(t)
+	require.NoError(t, server.Set(idempotencyPrefix+"msg-123", "garbage"))
+
+	_, err := store.Acquire(context.Background(), "msg-123")
+
+	assert.Error(t, err)
+}
+
+func TestIdempotencyStore_Acquire_RedisDown(t *testing.T) {
+	store, server := newTestRedisStore(t)
+	server.Close()
+
+	_, err := store.Acquire(context.Background(), "msg-123")
+
+	assert.Error(t, err)
+}
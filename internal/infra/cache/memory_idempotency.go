@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+)
+
+type memoryEntry struct {
+	state     string
+	expiresAt time.Time
+}
+
+type MemoryIdempotencyStore struct {
+	mu            sync.Mutex
+	entries       map[string]memoryEntry
+	processingTTL time.Duration
+	completedTTL  time.Duration
+	now           func() time.Time
+}
+
+func NewMemoryIdempotencyStore(processingTTL, completedTTL time.Duration) *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		entries:       make(map[string]memoryEntry),
+		processingTTL: processingTTL,
+		completedTTL:  completedTTL,
+		now:           time.Now,
+	}
+}
+
+func (s *MemoryIdempotencyStore) Acquire(ctx context.Context, key string) (domain.IdempotencyStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expiresAt) {
+		if entry.state == stateCompleted {
+			return domain.IdempotencyCompleted, nil
+		}
+		return domain.IdempotencyInProgress, nil
+	}
+
+	s.entries[key] = memoryEntry{state: stateProcessing, expiresAt: now.Add(s.processingTTL)}
+	return domain.IdempotencyAcquired, nil
+}
+
+func (s *MemoryIdempotencyStore) Complete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = memoryEntry{state: stateCompleted, expiresAt: s.now().Add(s.completedTTL)}
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryIdempotencyStore_Lifecycle(t *testing.T) {
+	store := NewMemoryIdempotencyStore(time.Minute, time.Hour)
+	ctx := context.Background()
+
+	status, err := store.Acquire(ctx, "msg-123")
+	require.NoError(t, err)
+	assert.Equal(t, domain.IdempotencyAcquired, status)
+
+	status, err = store.Acquire(ctx, "msg-123")
+	require.NoError(t, err)
+	assert.Equal(t, domain.IdempotencyInProgress, status)
+
+	require.NoError(t, store.Complete(ctx, "msg-123"))
+
+	status, err = store.Acquire(ctx, "msg-123")
+	require.NoError(t, err)
+	assert.Equal(t, domain.IdempotencyCompleted, status)
+}
+
+func TestMemoryIdempotencyStore_Release(t *testing.T) {
+	store := NewMemoryIdempotencyStore(time.Minute, time.Hour)
+	ctx := context.Background()
+
+	_, err := store.Acquire(ctx, "msg-123")
+	require.NoError(t, err)
+	require.NoError(t, store.Release(ctx, "msg-123"))
+
+	status, err := store.Acquire(ctx, "msg-123")
+	require.NoError(t, err)
+	assert.Equal(t, domain.IdempotencyAcquired, status)
+}
+
+func TestMemoryIdempotencyStore_Expiration(t *testing.T) {
+	store := NewMemoryIdempotencyStore(time.Minute, time.Hour)
+	now := time.Date(2025, 12, 2, 10, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := store.Acquire(ctx, "msg-processing")
+	require.NoError(t, err)
+	_, err = store.Acquire(ctx, "msg-completed")
+	require.NoError(t, err)
+	require.NoError(t, store.Complete(ctx, "msg-completed"))
+
+	now = now.Add(2 * time.Minute)
+
+	status, err := store.Acquire(ctx, "msg-processing")
+	require.NoError(t, err)
+	assert.Equal(t, domain.IdempotencyAcquired, status)
+
+	status, err = store.Acquire(ctx, "msg-completed")
+	require.NoError(t, err)
+	assert.Equal(t, domain.IdempotencyCompleted, status)
+
+	now = now.Add(2 * time.Hour)
+
+	status, err = store.Acquire(ctx, "msg-completed")
+	require.NoError(t, err)
+	assert.Equal(t, domain.IdempotencyAcquired, status)
+}
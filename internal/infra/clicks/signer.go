@@ -0,0 +1,72 @@
+package clicks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+)
+
+const (
+	idBytes        = 9
+	signatureBytes = 12
+	MinSecretBytes = 32
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid click token")
+	ErrWeakSecret   = errors.New("click tracking secret must be at least 32 bytes")
+)
+
+var encoding = base64.RawURLEncoding
+
+var (
+	idLength    = encoding.EncodedLen(idBytes)
+	tokenLength = idLength + encoding.EncodedLen(signatureBytes)
+)
+
+// Signer issues short tokens made of a random link ID followed by a
+// truncated HMAC-SHA256 of that ID, so tokens cannot be forged or guessed
+// without the secret.
+type Signer struct {
+	secret []byte
+}
+
+func NewSigner(secret []byte) (*Signer, error) {
+	if len(secret) < MinSecretBytes {
+		return nil, ErrWeakSecret
+	}
+	return &Signer{secret: secret}, nil
+}
+
+func NewID() (string, error) {
+	id := make([]byte, idBytes)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(id), nil
+}
+
+func (s *Signer) Sign(id string) string {
+	return id + encoding.EncodeToString(s.mac(id))
+}
+
+func (s *Signer) Verify(token string) (string, error) {
+	if len(token) != tokenLength {
+		return "", ErrInvalidToken
+	}
+
+	id, signature := token[:idLength], token[idLength:]
+	decoded, err := encoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(decoded, s.mac(id)) {
+		return "", ErrInvalidToken
+	}
+	return id, nil
+}
+
+func (s *Signer) mac(id string) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(id))
+	return h.Sum(nil)[:signatureBytes]
+}
@@ -0,0 +1,72 @@
+package clicks
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func TestSigner_SignAndVerify(t *testing.T) {
+	signer, err := NewSigner(testSecret)
+	require.NoError(t, err)
+
+	id, err := NewID()
+	require.NoError(t, err)
+
+	token := signer.Sign(id)
+	assert.Len(t, token, tokenLength)
+	assert.True(t, strings.HasPrefix(token, id))
+
+	verified, err := signer.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, id, verified)
+}
+
+func TestSigner_Verify_RejectsTamperedTokens(t *testing.T) {
+	signer, err := NewSigner(testSecret)
+	require.NoError(t, err)
+	other, err := NewSigner([]byte("another-secret-another-secret-xx"))
+	require.NoError(t, err)
+
+	id, err := NewID()
+	require.NoError(t, err)
+	token := signer.Sign(id)
+	nextID, err := NewID()
+	require.NoError(t, err)
+
+	testCases := map[string]string{
+		"empty":            "",
+		"id only":          id,
+		"too long":         token + "A",
+		"other secret":     other.Sign(id),
+		"swapped id":       nextID + token[idLength:],
+		"invalid encoding": token[:tokenLength-1] + "!",
+	}
+
+	for name, candidate := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := signer.Verify(candidate)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func TestNewSigner_WeakSecret(t *testing.T) {
+	_, err := NewSigner([]byte("short"))
+	assert.ErrorIs(t, err, ErrWeakSecret)
+}
+
+func TestNewID_Unique(t *testing.T) {
+	seen := make(map[string]bool)
+	for range 1000 {
+		id, err := NewID()
+		require.NoError(t, err)
+		assert.Len(t, id, idLength)
+		assert.False(t, seen[id])
+		seen[id] = true
+	}
+}
@@ -0,0 +1,80 @@
+package clicks
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+)
+
+const RedirectPath = "/r/"
+
+type Tracker struct {
+	repo    domain.LinkClickRepository
+	signer  *Signer
+	baseURL string
+	now     func() time.Time
+}
+
+func NewTracker(repo domain.LinkClickRepository, signer *Signer, baseURL string) *Tracker {
+	return &Tracker{
+		repo:    repo,
+		signer:  signer,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		now:     time.Now,
+	}
+}
+
+// Track stores the link and returns the signed redirect URL that replaces
+// it in the email.
+func (t *Tracker) Track(ctx context.Context, alert *domain.Alert, link domain.BookingLink) (string, error) {
+	id, err := NewID()
+	if err != nil {
+		return "", err
+	}
+
+	err = t.repo.SaveTrackedLink(ctx, domain.TrackedLink{
+		ID:        id,
+		AlertID:   alert.ID,
+		MessageID: alert.MessageID,
+		Provider:  link.Provider,
+		URL:       link.URL,
+		CreatedAt: t.now().UTC(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return t.baseURL + RedirectPath + t.signer.Sign(id), nil
+}
+
+// Resolve verifies the token, records the click and returns the provider
+// URL. Invalid and unknown tokens both report ErrTrackedLinkNotFound. A
+// failure to record the click is logged but does not block the redirect.
+func (t *Tracker) Resolve(ctx context.Context, token, userAgent string) (string, error) {
+	id, err := t.signer.Verify(token)
+	if err != nil {
+		return "", domain.ErrTrackedLinkNotFound
+	}
+
+	link, err := t.repo.GetTrackedLink(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	err = t.repo.RecordClick(ctx, domain.LinkClick{
+		LinkID:    link.ID,
+		AlertID:   link.AlertID,
+		Provider:  link.Provider,
+		UserAgent: userAgent,
+		ClickedAt: t.now().UTC(),
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Printf("failed to record click on link %s: %v", link.ID, err)
+	}
+
+	return link.URL, nil
+}
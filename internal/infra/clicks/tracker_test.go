@@ -0,0 +1,136 @@
+package clicks
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryRepository struct {
+	mu       sync.Mutex
+	links    map[string]domain.TrackedLink
+	clicks   []domain.LinkClick
+	saveErr  error
+	clickErr error
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{links: make(map[string]domain.TrackedLink)}
+}
+
+func (r *memoryRepository) SaveTrackedLink(ctx context.Context, link domain.TrackedLink) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.saveErr != nil {
+		return r.saveErr
+	}
+	r.links[link.ID] = link
+	return nil
+}
+
+func (r *memoryRepository) GetTrackedLink(ctx context.Context, id string) (domain.TrackedLink, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	link, ok := r.links[id]
+	if !ok {
+		return domain.TrackedLink{}, domain.ErrTrackedLinkNotFound
+	}
+	return link, nil
+}
+
+func (r *memoryRepository) RecordClick(ctx context.Context, click domain.LinkClick) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.clickErr != nil {
+		return r.clickErr
+	}
+	r.clicks = append(r.clicks, click)
+	return nil
+}
+
+func newTestTracker(t *testing.T, repo *memoryRepository) *Tracker {
+	t.Helper()
+
+	signer, err := NewSigner(testSecret)
+	require.NoError(t, err)
+
+	tracker := NewTracker(repo, signer, "https://alerts.example.com/")
+	tracker.now = func() time.Time { return time.Date(2025, 12, 2, 10, 0, 0, 0, time.UTC) }
+	return tracker
+}
+
+func TestTracker_TrackAndResolve(t *testing.T) {
+	repo := newMemoryRepository()
+	tracker := newTestTracker(t, repo)
+
+	alert := &domain.Alert{ID: 42, MessageID: "msg-123"}
+	link := domain.BookingLink{Provider: "kayak", Name: "Kayak", URL: "https://www.kayak.com/flights/GRU-JFK/2025-12-15"}
+
+	tracked, err := tracker.Track(context.Background(), alert, link)
+
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(tracked, "https://alerts.example.com/r/"))
+	token := strings.TrimPrefix(tracked, "https://alerts.example.com/r/")
+	assert.Len(t, token, tokenLength)
+
+	target, err := tracker.Resolve(context.Background(), token, "Mozilla/5.0")
+
+	require.NoError(t, err)
+	assert.Equal(t, link.URL, target)
+	require.Len(t, repo.clicks, 1)
+	assert.Equal(t, domain.LinkClick{
+		LinkID:    token[:idLength],
+		AlertID:   42,
+		Provider:  "kayak",
+		UserAgent: "Mozilla/5.0",
+		ClickedAt: time.Date(2025, 12, 2, 10, 0, 0, 0, time.UTC),
+	}, repo.clicks[0])
+	assert.Equal(t, "msg-123", repo.links[token[:idLength]].MessageID)
+}
+
+func TestTracker_Track_SaveError(t *testing.T) {
+	repo := newMemoryRepository()
+	repo.saveErr = errors.New("database down")
+	tracker := newTestTracker(t, repo)
+
+	_, err := tracker.Track(context.Background(), &domain.Alert{ID: 1}, domain.BookingLink{URL: "https://example.com"})
+
+	assert.EqualError(t, err, "database down")
+}
+
+func TestTracker_Resolve_InvalidOrUnknownToken(t *testing.T) {
+	repo := newMemoryRepository()
+	tracker := newTestTracker(t, repo)
+
+	id, err := NewID()
+	require.NoError(t, err)
+
+	_, err = tracker.Resolve(context.Background(), "forged-token", "")
+	assert.ErrorIs(t, err, domain.ErrTrackedLinkNotFound)
+
+	_, err = tracker.Resolve(context.Background(), tracker.signer.Sign(id), "")
+	assert.ErrorIs(t, err, domain.ErrTrackedLinkNotFound)
+
+	assert.Empty(t, repo.clicks)
+}
+
+func TestTracker_Resolve_ClickErrorStillRedirects(t *testing.T) {
+	repo := newMemoryRepository()
+	tracker := newTestTracker(t, repo)
+
+	tracked, err := tracker.Track(context.Background(), &domain.Alert{ID: 1}, domain.BookingLink{Provider: "kayak", URL: "https://example.com"})
+	require.NoError(t, err)
+	repo.clickErr = errors.New("insert failed")
+
+	target, err := tracker.Resolve(context.Background(), tracked[strings.LastIndex(tracked, "/")+1:], "")
+
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", target)
+}
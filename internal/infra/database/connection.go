@@ -2,23 +2,74 @@ package database
 
 import (
 	"context"
+	"strconv"
+	"time"
 
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func DatabaseConnection(connectionString string) (*pgx.Conn, error) {
-	connection, err := pgx.Connect(context.Background(), connectionString)
+type PoolConfig struct {
+	MaxConns          int32
+	MinConns          int32
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+	// StatementTimeout is set as the session statement_timeout, so the server
+	// aborts queries that outlive their caller. Zero keeps the server default.
+	StatementTimeout time.Duration
+}
+
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{
+		MaxConns:          10,
+		MinConns:          1,
+		MaxConnIdleTime:   5 * time.Minute,
+		HealthCheckPeriod: 30 * time.Second,
+		StatementTimeout:  10 * time.Second,
+	}
+}
+
+func DatabaseConnection(connectionString string, config PoolConfig) (*pgxpool.Pool, error) {
+	poolConfig, err := newPoolConfig(connectionString, config)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := connection.Ping(context.Background()); err != nil {
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pool.Ping(context.Background()); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	return pool, nil
+}
+
+func newPoolConfig(connectionString string, config PoolConfig) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(connectionString)
+	if err != nil {
 		return nil, err
 	}
 
-	return connection, nil
+	if config.MaxConns > 0 {
+		poolConfig.MaxConns = config.MaxConns
+	}
+	poolConfig.MinConns = config.MinConns
+	if config.MaxConnIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = config.MaxConnIdleTime
+	}
+	if config.HealthCheckPeriod > 0 {
+		poolConfig.HealthCheckPeriod = config.HealthCheckPeriod
+	}
+	if config.StatementTimeout > 0 {
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(config.StatementTimeout.Milliseconds(), 10)
+	}
+
+	return poolConfig, nil
 }
 
-func CloseDatabaseConnection(connection *pgx.Conn) error {
-	return connection.Close(context.Background())
+func CloseDatabaseConnection(pool *pgxpool.Pool) {
+	pool.Close()
 }
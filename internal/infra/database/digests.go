@@ -0,0 +1,161 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+const digestItemColumns = "id, alert_id, message_id, email, trigger, origin, destination, outbound_date, return_date, old_price, new_price, target_price, currency, link, created_at"
+
+// AddDigestItem queues a price change for the recipient's next digest. A
+// redelivered message is queued only once.
+func (r *Repository) AddDigestItem(ctx context.Context, item domain.DigestItem) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.database.Exec(ctx,
+		`INSERT INTO digest_items (alert_id, message_id, email, trigger, origin, destination, outbound_date, return_date, old_price, new_price, target_price, currency, link)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+ON CONFLICT (message_id) WHERE message_id <> '' DO NOTHING`,
+		item.AlertID, item.MessageID, item.Email, string(item.Trigger), item.Origin, item.Destination,
+		item.OutboundDate, nullDate(item.ReturnDate), item.OldPrice, item.NewPrice, item.TargetPrice, item.Currency, item.Link)
+	return err
+}
+
+// PendingDigestQueues returns, per recipient, the items not yet in a digest
+// together with their digest frequency and timezone.
+func (r *Repository) PendingDigestQueues(ctx context.Context) ([]domain.DigestQueue, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.database.Query(ctx, `SELECT i.email, COALESCE(p.digest, 'off'), COALESCE(p.timezone, ''), min(i.created_at)
+FROM digest_items i
+LEFT JOIN notification_preferences p ON lower(p.email) = lower(i.email)
+WHERE i.digest_id IS NULL
+GROUP BY i.email, p.digest, p.timezone`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var queues []domain.DigestQueue
+	for rows.Next() {
+		var q domain.DigestQueue
+		var frequency string
+		if err := rows.Scan(&q.Email, &frequency, &q.Timezone, &q.Oldest); err != nil {
+			return nil, err
+		}
+		q.Frequency = domain.DigestFrequency(frequency)
+		queues = append(queues, q)
+	}
+	return queues, rows.Err()
+}
+
+// CloseDigest creates the digest of a recipient's period and moves the items
+// queued before periodEnd into it, in a single statement. It reports false
+// when the period was already closed, by this or another worker; items that
+// arrived late stay queued for the next period.
+func (r *Repository) CloseDigest(ctx context.Context, queue domain.DigestQueue, periodEnd time.Time) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tag, err := r.database.Exec(ctx, `WITH digest AS (
+    INSERT INTO digests (email, frequency, period_end) VALUES ($1, $2, $3)
+    ON CONFLICT (lower(email), period_end) DO NOTHING
+    RETURNING id
+)
+UPDATE digest_items SET digest_id = digest.id
+FROM digest
+WHERE lower(digest_items.email) = lower($1) AND digest_items.digest_id IS NULL AND digest_items.created_at < $3`,
+		queue.Email, string(queue.Frequency), periodEnd)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ClaimDigest leases the oldest pending digest to this worker and returns it
+// with its items. A worker that stops before marking the digest lets the
+// lease expire, and the digest is claimed again.
+func (r *Repository) ClaimDigest(ctx context.Context, lease time.Duration) (domain.Digest, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var digest domain.Digest
+	var frequency string
+	err := r.database.QueryRow(ctx, `UPDATE digests SET locked_until = now() + make_interval(secs => $1), attempts = attempts + 1, updated_at = now()
+WHERE id = (
+    SELECT id FROM digests
+    WHERE status = 'pending' AND locked_until <= now()
+    ORDER BY period_end, id
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, email, frequency, period_end, attempts`, lease.Seconds()).
+		Scan(&digest.ID, &digest.Email, &frequency, &digest.PeriodEnd, &digest.Attempts)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Digest{}, domain.ErrNoDigestDue
+	}
+	if err != nil {
+		return domain.Digest{}, err
+	}
+	digest.Frequency = domain.DigestFrequency(frequency)
+
+	rows, err := r.database.Query(ctx, "SELECT "+digestItemColumns+" FROM digest_items WHERE digest_id=$1 ORDER BY id", digest.ID)
+	if err != nil {
+		return domain.Digest{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item domain.DigestItem
+		var trigger string
+		var returnDate *time.Time
+		err := rows.Scan(&item.ID, &item.AlertID, &item.MessageID, &item.Email, &trigger, &item.Origin, &item.Destination,
+			&item.OutboundDate, &returnDate, &item.OldPrice, &item.NewPrice, &item.TargetPrice, &item.Currency, &item.Link, &item.CreatedAt)
+		if err != nil {
+			return domain.Digest{}, err
+		}
+		item.Trigger = domain.Trigger(trigger)
+		if returnDate != nil {
+			item.ReturnDate = *returnDate
+		}
+		digest.Items = append(digest.Items, item)
+	}
+	return digest, rows.Err()
+}
+
+func (r *Repository) MarkDigestSent(ctx context.Context, id int64, response string) error {
+	return r.finishDigest(ctx, id, "sent", response)
+}
+
+// RetryDigest keeps the digest pending; it is claimed again once the lease
+// taken by ClaimDigest expires.
+func (r *Repository) RetryDigest(ctx context.Context, id int64, reason string) error {
+	return r.finishDigest(ctx, id, "pending", reason)
+}
+
+func (r *Repository) MarkDigestFailed(ctx context.Context, id int64, reason string) error {
+	return r.finishDigest(ctx, id, "failed", reason)
+}
+
+func (r *Repository) finishDigest(ctx context.Context, id int64, status, response string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.database.Exec(ctx,
+		"UPDATE digests SET status=$2, response=$3, updated_at=now() WHERE id=$1",
+		id, status, response)
+	return err
+}
+
+func nullDate(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,190 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_AddDigestItem(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	outbound := time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC)
+	item := domain.DigestItem{
+		AlertID:      42,
+		MessageID:    "abc-123",
+		Email:        "user@example.com",
+		Trigger:      domain.TriggerPriceDrop,
+		Origin:       "GRU",
+		Destination:  "JFK",
+		OutboundDate: outbound,
+		OldPrice:     2500,
+		NewPrice:     1800,
+		Currency:     "BRL",
+		Link:         "https://alerts.example.com/r/token1",
+	}
+
+	mock.ExpectExec("INSERT INTO digest_items .* ON CONFLICT \\(message_id\\) WHERE message_id <> '' DO NOTHING").
+		WithArgs(int64(42), "abc-123", "user@example.com", "price_drop", "GRU", "JFK", outbound, (*time.Time)(nil), 2500.0, 1800.0, 0.0, "BRL", "https://alerts.example.com/r/token1").
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	err = NewRepository(mock).AddDigestItem(context.Background(), item)
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_PendingDigestQueues(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	oldest := time.Date(2025, 12, 1, 18, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT i.email, COALESCE\\(p.digest, 'off'\\).*FROM digest_items i\\s+LEFT JOIN notification_preferences p.*WHERE i.digest_id IS NULL").
+		WillReturnRows(pgxmock.NewRows([]string{"email", "digest", "timezone", "oldest"}).
+			AddRow("ana@example.com", "weekly", "Europe/Madrid", oldest).
+			AddRow("bia@example.com", "off", "", oldest))
+
+	queues, err := NewRepository(mock).PendingDigestQueues(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []domain.DigestQueue{
+		{Email: "ana@example.com", Frequency: domain.DigestWeekly, Timezone: "Europe/Madrid", Oldest: oldest},
+		{Email: "bia@example.com", Frequency: domain.DigestOff, Oldest: oldest},
+	}, queues)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_CloseDigest(t *testing.T) {
+	testCases := []struct {
+		name     string
+		affected int64
+		expected bool
+	}{
+		{name: "closed", affected: 3, expected: true},
+		{name: "already closed", affected: 0, expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock, err := pgxmock.NewConn()
+			require.NoError(t, err)
+			defer mock.Close(context.Background())
+
+			periodEnd := time.Date(2025, 12, 2, 11, 0, 0, 0, time.UTC)
+			queue := domain.DigestQueue{Email: "ana@example.com", Frequency: domain.DigestDaily}
+			mock.ExpectExec("WITH digest AS \\(\\s+INSERT INTO digests .* ON CONFLICT \\(lower\\(email\\), period_end\\) DO NOTHING.*UPDATE digest_items SET digest_id = digest.id").
+				WithArgs("ana@example.com", "daily", periodEnd).
+				WillReturnResult(pgxmock.NewResult("UPDATE", tc.affected))
+
+			closed, err := NewRepository(mock).CloseDigest(context.Background(), queue, periodEnd)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, closed)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_ClaimDigest(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	periodEnd := time.Date(2025, 12, 2, 11, 0, 0, 0, time.UTC)
+	outbound := time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC)
+	returnDate := time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2025, 12, 1, 18, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("UPDATE digests SET locked_until = now\\(\\) \\+ make_interval\\(secs => \\$1\\).*FOR UPDATE SKIP LOCKED").
+		WithArgs(300.0).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "email", "frequency", "period_end", "attempts"}).
+			AddRow(int64(5), "ana@example.com", "daily", periodEnd, 1))
+	mock.ExpectQuery("SELECT id, alert_id, .* FROM digest_items WHERE digest_id=\\$1").
+		WithArgs(int64(5)).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "alert_id", "message_id", "email", "trigger", "origin", "destination", "outbound_date", "return_date", "old_price", "new_price", "target_price", "currency", "link", "created_at"}).
+			AddRow(int64(1), int64(42), "abc-123", "ana@example.com", "price_drop", "GRU", "JFK", outbound, &returnDate, 2500.0, 1800.0, 0.0, "BRL", "https://example.com/1", createdAt).
+			AddRow(int64(2), int64(43), "def-456", "ana@example.com", "target_reached", "GRU", "LIS", outbound, nil, 3000.0, 2900.0, 2950.0, "BRL", "", createdAt))
+
+	digest, err := NewRepository(mock).ClaimDigest(context.Background(), 5*time.Minute)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), digest.ID)
+	assert.Equal(t, domain.DigestDaily, digest.Frequency)
+	assert.Equal(t, periodEnd, digest.PeriodEnd)
+	assert.Equal(t, 1, digest.Attempts)
+	require.Len(t, digest.Items, 2)
+	assert.Equal(t, returnDate, digest.Items[0].ReturnDate)
+	assert.True(t, digest.Items[1].ReturnDate.IsZero())
+	assert.Equal(t, domain.TriggerTargetReached, digest.Items[1].Trigger)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_ClaimDigest_NoneDue(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	mock.ExpectQuery("UPDATE digests SET locked_until").
+		WithArgs(300.0).
+		WillReturnError(pgx.ErrNoRows)
+
+	_, err = NewRepository(mock).ClaimDigest(context.Background(), 5*time.Minute)
+
+	assert.ErrorIs(t, err, domain.ErrNoDigestDue)
+}
+
+func TestRepository_FinishDigest(t *testing.T) {
+	testCases := []struct {
+		name     string
+		finish   func(repo *Repository) error
+		status   string
+		response string
+	}{
+		{
+			name:     "sent",
+			finish:   func(repo *Repository) error { return repo.MarkDigestSent(context.Background(), 5, "250 ok") },
+			status:   "sent",
+			response: "250 ok",
+		},
+		{
+			name: "retry",
+			finish: func(repo *Repository) error {
+				return repo.RetryDigest(context.Background(), 5, "smtp transient failure: 421 try later")
+			},
+			status:   "pending",
+			response: "smtp transient failure: 421 try later",
+		},
+		{
+			name: "failed",
+			finish: func(repo *Repository) error {
+				return repo.MarkDigestFailed(context.Background(), 5, "smtp recipient rejected: 550 no such user")
+			},
+			status:   "failed",
+			response: "smtp recipient rejected: 550 no such user",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock, err := pgxmock.NewConn()
+			require.NoError(t, err)
+			defer mock.Close(context.Background())
+
+			mock.ExpectExec("UPDATE digests SET status=\\$2, response=\\$3").
+				WithArgs(int64(5), tc.status, tc.response).
+				WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+			require.NoError(t, tc.finish(NewRepository(mock)))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
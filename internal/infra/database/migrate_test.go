@@ -0,0 +1,199 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/infra/database/migrations"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testMigrations = []Migration{
+	{Version: 1, Name: "create_users", Up: "CREATE TABLE users (id BIGINT)", Down: "DROP TABLE users"},
+	{Version: 2, Name: "create_notifications", Up: "CREATE TABLE notifications (id BIGINT)", Down: "DROP TABLE notifications"},
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_create_notifications.up.sql":   {Data: []byte("CREATE TABLE notifications (id BIGINT)")},
+		"0002_create_notifications.down.sql": {Data: []byte("DROP TABLE notifications")},
+		"0001_create_users.up.sql":           {Data: []byte("CREATE TABLE users (id BIGINT)")},
+		"0001_create_users.down.sql":         {Data: []byte("DROP TABLE users")},
+		"embed.go":                           {Data: []byte("package migrations")},
+	}
+
+	loaded, err := LoadMigrations(fsys)
+
+	require.NoError(t, err)
+	assert.Equal(t, testMigrations, loaded)
+}
+
+func TestLoadMigrations_Invalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{
+			name: "missing down",
+			fsys: fstest.MapFS{"0001_create_users.up.sql": {Data: []byte("CREATE TABLE users (id BIGINT)")}},
+		},
+		{
+			name: "conflicting names",
+			fsys: fstest.MapFS{
+				"0001_create_users.up.sql":    {Data: []byte("CREATE TABLE users (id BIGINT)")},
+				"0001_create_alerts.down.sql": {Data: []byte("DROP TABLE alerts")},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadMigrations(tc.fsys)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadMigrations_Embedded(t *testing.T) {
+	loaded, err := LoadMigrations(migrations.FS)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, loaded)
+	for i, m := range loaded {
+		assert.Equal(t, int64(i+1), m.Version, "migration versions must be sequential")
+	}
+}
+
+func expectLock(mock pgxmock.PgxConnIface) {
+	mock.ExpectExec("SELECT pg_advisory_lock").WithArgs(migrationLockKey).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+}
+
+func expectUnlock(mock pgxmock.PgxConnIface) {
+	mock.ExpectExec("SELECT pg_advisory_unlock").WithArgs(migrationLockKey).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+}
+
+func TestMigrator_Up(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	expectLock(mock)
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+		WillReturnRows(pgxmock.NewRows([]string{"version", "applied_at"}).AddRow(int64(1), time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec("SET LOCAL statement_timeout = 0").WillReturnResult(pgxmock.NewResult("SET", 0))
+	mock.ExpectExec("CREATE TABLE notifications").WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	mock.ExpectExec("INSERT INTO schema_migrations").WithArgs(int64(2), "create_notifications").WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+	expectUnlock(mock)
+
+	applied, err := NewMigrator(mock, testMigrations).Up(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, int64(2), applied[0].Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Up_FailureRollsBackAndUnlocks(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	expectLock(mock)
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+		WillReturnRows(pgxmock.NewRows([]string{"version", "applied_at"}))
+	mock.ExpectBegin()
+	mock.ExpectExec("SET LOCAL statement_timeout = 0").WillReturnResult(pgxmock.NewResult("SET", 0))
+	mock.ExpectExec("CREATE TABLE users").WillReturnError(errors.New("permission denied"))
+	mock.ExpectRollback()
+	expectUnlock(mock)
+
+	applied, err := NewMigrator(mock, testMigrations).Up(context.Background())
+
+	assert.EqualError(t, err, "migration 0001_create_users: permission denied")
+	assert.Empty(t, applied)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Down(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	expectLock(mock)
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+		WillReturnRows(pgxmock.NewRows([]string{"version", "applied_at"}).AddRow(int64(1), time.Now()).AddRow(int64(2), time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec("SET LOCAL statement_timeout = 0").WillReturnResult(pgxmock.NewResult("SET", 0))
+	mock.ExpectExec("DROP TABLE notifications").WillReturnResult(pgxmock.NewResult("DROP TABLE", 0))
+	mock.ExpectExec("DELETE FROM schema_migrations").WithArgs(int64(2)).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectCommit()
+	expectUnlock(mock)
+
+	reverted, ok, err := NewMigrator(mock, testMigrations).Down(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), reverted.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Down_NothingApplied(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	expectLock(mock)
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+		WillReturnRows(pgxmock.NewRows([]string{"version", "applied_at"}))
+	expectUnlock(mock)
+
+	_, ok, err := NewMigrator(mock, testMigrations).Down(context.Background())
+
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Down_UnknownVersion(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	expectLock(mock)
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+		WillReturnRows(pgxmock.NewRows([]string{"version", "applied_at"}).AddRow(int64(9), time.Now()))
+	expectUnlock(mock)
+
+	_, _, err = NewMigrator(mock, testMigrations).Down(context.Background())
+
+	assert.EqualError(t, err, "applied migration 9 is unknown to this binary")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Status(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	appliedAt := time.Date(2025, 12, 2, 10, 0, 0, 0, time.UTC)
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+		WillReturnRows(pgxmock.NewRows([]string{"version", "applied_at"}).AddRow(int64(1), appliedAt))
+
+	statuses, err := NewMigrator(mock, testMigrations).Status(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.True(t, statuses[0].Applied)
+	assert.Equal(t, appliedAt, statuses[0].AppliedAt)
+	assert.False(t, statuses[1].Applied)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
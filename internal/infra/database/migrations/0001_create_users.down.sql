@@ -0,0 +1,3 @@
+-- users belongs to the Search Service and is left in place. Earlier versions
+-- of 0001 added link_providers to it, which 0011 moves out.
+ALTER TABLE users DROP COLUMN IF EXISTS link_providers;
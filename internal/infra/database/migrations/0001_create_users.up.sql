@@ -0,0 +1,8 @@
+-- The users table is shared with the Search Service, so this only creates it
+-- in environments where that service has not.
+CREATE TABLE IF NOT EXISTS users (
+    id          BIGSERIAL PRIMARY KEY,
+    alert_id    BIGINT NOT NULL UNIQUE,
+    email       TEXT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
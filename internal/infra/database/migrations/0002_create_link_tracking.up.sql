@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS tracked_links (
+    id          TEXT PRIMARY KEY,
+    alert_id    BIGINT NOT NULL,
+    message_id  TEXT NOT NULL DEFAULT '',
+    provider    TEXT NOT NULL,
+    url         TEXT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS tracked_links_alert_id_idx ON tracked_links (alert_id);
+
+CREATE TABLE IF NOT EXISTS link_clicks (
+    id          BIGSERIAL PRIMARY KEY,
+    link_id     TEXT NOT NULL REFERENCES tracked_links (id) ON DELETE CASCADE,
+    alert_id    BIGINT NOT NULL,
+    provider    TEXT NOT NULL,
+    user_agent  TEXT NOT NULL DEFAULT '',
+    clicked_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS link_clicks_alert_id_idx ON link_clicks (alert_id, clicked_at);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS notifications (
+    id          BIGSERIAL PRIMARY KEY,
+    alert_id    BIGINT NOT NULL,
+    message_id  TEXT NOT NULL DEFAULT '',
+    recipient   TEXT NOT NULL,
+    subject     TEXT NOT NULL DEFAULT '',
+    trigger     TEXT NOT NULL DEFAULT '',
+    old_price   NUMERIC(12, 2) NOT NULL,
+    new_price   NUMERIC(12, 2) NOT NULL,
+    currency    TEXT NOT NULL,
+    status      TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+    response    TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS notifications_alert_id_idx ON notifications (alert_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS notifications_recipient_idx ON notifications (lower(recipient), created_at DESC);
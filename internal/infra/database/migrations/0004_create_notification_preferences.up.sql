@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    id                  BIGSERIAL PRIMARY KEY,
+    email               TEXT NOT NULL,
+    name                TEXT NOT NULL DEFAULT '',
+    locale              TEXT NOT NULL DEFAULT 'pt-BR',
+    timezone            TEXT NOT NULL DEFAULT 'America/Sao_Paulo',
+    channels            TEXT[] NOT NULL DEFAULT '{email}',
+    quiet_hours_start   SMALLINT NOT NULL DEFAULT 0 CHECK (quiet_hours_start BETWEEN 0 AND 1439),
+    quiet_hours_end     SMALLINT NOT NULL DEFAULT 0 CHECK (quiet_hours_end BETWEEN 0 AND 1439),
+    opted_out           BOOLEAN NOT NULL DEFAULT false,
+    opted_out_triggers  TEXT[] NOT NULL DEFAULT '{}',
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS notification_preferences_email_idx ON notification_preferences (lower(email));
@@ -0,0 +1,7 @@
+ALTER TABLE notifications
+    DROP COLUMN IF EXISTS channel;
+
+ALTER TABLE notification_preferences
+    DROP COLUMN IF EXISTS webhook_url,
+    DROP COLUMN IF EXISTS slack_webhook_url,
+    DROP COLUMN IF EXISTS telegram_chat_id;
@@ -0,0 +1,7 @@
+ALTER TABLE notification_preferences
+    ADD COLUMN IF NOT EXISTS webhook_url TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS slack_webhook_url TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS telegram_chat_id TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS channel TEXT NOT NULL DEFAULT 'email';
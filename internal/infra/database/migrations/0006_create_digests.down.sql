@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS digest_items;
+DROP TABLE IF EXISTS digests;
+
+ALTER TABLE notification_preferences
+    DROP COLUMN IF EXISTS digest;
@@ -0,0 +1,41 @@
+ALTER TABLE notification_preferences
+    ADD COLUMN IF NOT EXISTS digest TEXT NOT NULL DEFAULT 'off' CHECK (digest IN ('off', 'daily', 'weekly'));
+
+CREATE TABLE IF NOT EXISTS digests (
+    id            BIGSERIAL PRIMARY KEY,
+    email         TEXT NOT NULL,
+    frequency     TEXT NOT NULL,
+    period_end    TIMESTAMPTZ NOT NULL,
+    status        TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts      INTEGER NOT NULL DEFAULT 0,
+    locked_until  TIMESTAMPTZ NOT NULL DEFAULT '-infinity',
+    response      TEXT NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS digests_email_period_idx ON digests (lower(email), period_end);
+CREATE INDEX IF NOT EXISTS digests_pending_idx ON digests (locked_until) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS digest_items (
+    id             BIGSERIAL PRIMARY KEY,
+    digest_id      BIGINT REFERENCES digests (id) ON DELETE SET NULL,
+    alert_id       BIGINT NOT NULL,
+    message_id     TEXT NOT NULL DEFAULT '',
+    email          TEXT NOT NULL,
+    trigger        TEXT NOT NULL DEFAULT '',
+    origin         TEXT NOT NULL,
+    destination    TEXT NOT NULL,
+    outbound_date  DATE NOT NULL,
+    return_date    DATE,
+    old_price      NUMERIC(12, 2) NOT NULL,
+    new_price      NUMERIC(12, 2) NOT NULL,
+    target_price   NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    currency       TEXT NOT NULL,
+    link           TEXT NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS digest_items_message_id_idx ON digest_items (message_id) WHERE message_id <> '';
+CREATE INDEX IF NOT EXISTS digest_items_queue_idx ON digest_items (lower(email), created_at) WHERE digest_id IS NULL;
+CREATE INDEX IF NOT EXISTS digest_items_digest_id_idx ON digest_items (digest_id);
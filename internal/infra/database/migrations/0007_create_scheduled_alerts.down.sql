@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS scheduled_alerts;
+
+ALTER TABLE notification_preferences
+    DROP COLUMN IF EXISTS quiet_hours_urgent;
@@ -0,0 +1,19 @@
+ALTER TABLE notification_preferences
+    ADD COLUMN IF NOT EXISTS quiet_hours_urgent BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS scheduled_alerts (
+    id            BIGSERIAL PRIMARY KEY,
+    alert_id      BIGINT NOT NULL,
+    message_id    TEXT NOT NULL DEFAULT '',
+    payload       JSONB NOT NULL,
+    deliver_at    TIMESTAMPTZ NOT NULL,
+    status        TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'skipped', 'failed')),
+    attempts      INTEGER NOT NULL DEFAULT 0,
+    locked_until  TIMESTAMPTZ NOT NULL DEFAULT '-infinity',
+    response      TEXT NOT NULL DEFAULT '',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS scheduled_alerts_pending_alert_idx ON scheduled_alerts (alert_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS scheduled_alerts_due_idx ON scheduled_alerts (deliver_at) WHERE status = 'pending';
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS price_history (
+    id          BIGSERIAL PRIMARY KEY,
+    alert_id    BIGINT NOT NULL,
+    price       NUMERIC(12, 2) NOT NULL,
+    currency    TEXT NOT NULL,
+    checked_at  TIMESTAMPTZ NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS price_history_alert_checked_idx ON price_history (alert_id, checked_at);
@@ -0,0 +1,2 @@
+ALTER TABLE notification_preferences
+    ADD COLUMN IF NOT EXISTS display_currency TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE scheduled_alerts
+    DROP COLUMN IF EXISTS version;
@@ -0,0 +1,2 @@
+ALTER TABLE scheduled_alerts
+    ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 0;
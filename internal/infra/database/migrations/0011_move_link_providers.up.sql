@@ -0,0 +1,19 @@
+ALTER TABLE notification_preferences
+    ADD COLUMN IF NOT EXISTS link_providers TEXT[] NOT NULL DEFAULT '{}';
+
+-- Earlier versions kept the selection in users.link_providers, but users
+-- belongs to the Search Service: carry it over and drop the column.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns
+               WHERE table_schema = current_schema() AND table_name = 'users' AND column_name = 'link_providers') THEN
+        INSERT INTO notification_preferences (email, link_providers)
+        SELECT DISTINCT ON (lower(email)) email, link_providers
+        FROM users
+        WHERE link_providers <> '{}'
+        ORDER BY lower(email), id DESC
+        ON CONFLICT ((lower(email))) DO UPDATE SET link_providers = EXCLUDED.link_providers;
+
+        ALTER TABLE users DROP COLUMN link_providers;
+    END IF;
+END $$;
@@ -0,0 +1,8 @@
+// Package migrations embeds the versioned SQL scripts of the alert-service
+// schema. Files are named NNNN_description.up.sql and NNNN_description.down.sql.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+
+	"github.com/Luzin7/alert-service/internal/infra/metrics"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func PoolStats(pool *pgxpool.Pool) metrics.DBPoolStats {
+	stat := pool.Stat()
+	return metrics.DBPoolStats{
+		AcquiredConns:        stat.AcquiredConns(),
+		IdleConns:            stat.IdleConns(),
+		TotalConns:           stat.TotalConns(),
+		MaxConns:             stat.MaxConns(),
+		AcquireCount:         stat.AcquireCount(),
+		EmptyAcquireCount:    stat.EmptyAcquireCount(),
+		CanceledAcquireCount: stat.CanceledAcquireCount(),
+		AcquireDuration:      stat.AcquireDuration(),
+	}
+}
+
+// PoolChecker pings the database and reports pool usage alongside the result.
+type PoolChecker struct {
+	pool *pgxpool.Pool
+}
+
+func NewPoolChecker(pool *pgxpool.Pool) *PoolChecker {
+	return &PoolChecker{pool: pool}
+}
+
+func (c *PoolChecker) Check(ctx context.Context) error {
+	return c.pool.Ping(ctx)
+}
+
+func (c *PoolChecker) Details() map[string]any {
+	stats := PoolStats(c.pool)
+	return map[string]any{
+		"acquiredConns": stats.AcquiredConns,
+		"idleConns":     stats.IdleConns,
+		"totalConns":    stats.TotalConns,
+		"maxConns":      stats.MaxConns,
+	}
+}
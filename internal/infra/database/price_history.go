@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+)
+
+// RecordPrice stores a checked price. A redelivered message is stored only
+// once, since the Search Service checks an alert once at each CheckedAt.
+func (r *Repository) RecordPrice(ctx context.Context, point domain.PricePoint) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.database.Exec(ctx,
+		`INSERT INTO price_history (alert_id, price, currency, checked_at) VALUES ($1, $2, $3, $4)
+ON CONFLICT (alert_id, checked_at) DO NOTHING`,
+		point.AlertID, point.Price, point.Currency, point.CheckedAt)
+	return err
+}
+
+// PriceStats returns the lowest, highest and average price of the alert
+// checked since the given time.
+func (r *Repository) PriceStats(ctx context.Context, alertID int64, currency string, since time.Time) (domain.PriceStats, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var stats domain.PriceStats
+	err := r.database.QueryRow(ctx,
+		`SELECT COALESCE(min(price), 0)::float8, COALESCE(max(price), 0)::float8, COALESCE(avg(price), 0)::float8, count(*)
+FROM price_history
+WHERE alert_id = $1 AND currency = $2 AND checked_at >= $3`,
+		alertID, currency, since).
+		Scan(&stats.Min, &stats.Max, &stats.Average, &stats.Count)
+	if err != nil {
+		return domain.PriceStats{}, err
+	}
+	return stats, nil
+}
+
+// DailyLowestPrices returns the lowest price of each UTC day the alert was
+// checked since the given time, oldest first.
+func (r *Repository) DailyLowestPrices(ctx context.Context, alertID int64, currency string, since time.Time) ([]domain.PricePoint, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.database.Query(ctx,
+		`SELECT date_trunc('day', checked_at, 'UTC') AS day, min(price)::float8
+FROM price_history
+WHERE alert_id = $1 AND currency = $2 AND checked_at >= $3
+GROUP BY day
+ORDER BY day`,
+		alertID, currency, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []domain.PricePoint
+	for rows.Next() {
+		point := domain.PricePoint{AlertID: alertID, Currency: currency}
+		if err := rows.Scan(&point.CheckedAt, &point.Price); err != nil {
+			return nil, err
+		}
+		points = append(points, point)
+	}
+	return points, rows.Err()
+}
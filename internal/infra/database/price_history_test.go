@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_RecordPrice(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	checkedAt := time.Date(2025, 12, 2, 10, 0, 0, 0, time.UTC)
+	mock.ExpectExec("INSERT INTO price_history .* ON CONFLICT \\(alert_id, checked_at\\) DO NOTHING").
+		WithArgs(int64(42), 1800.0, "BRL", checkedAt).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	err = NewRepository(mock).RecordPrice(context.Background(), domain.PricePoint{AlertID: 42, Price: 1800, Currency: "BRL", CheckedAt: checkedAt})
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_PriceStats(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	since := time.Date(2025, 11, 2, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT COALESCE\\(min\\(price\\), 0\\).*FROM price_history\\s+WHERE alert_id = \\$1 AND currency = \\$2 AND checked_at >= \\$3").
+		WithArgs(int64(42), "BRL", since).
+		WillReturnRows(pgxmock.NewRows([]string{"min", "max", "avg", "count"}).
+			AddRow(1800.0, 2500.0, 2100.0, 12))
+
+	stats, err := NewRepository(mock).PriceStats(context.Background(), 42, "BRL", since)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.PriceStats{Min: 1800, Max: 2500, Average: 2100, Count: 12}, stats)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_PriceStats_Error(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	mock.ExpectQuery("FROM price_history").WithArgs(int64(42), "BRL", pgxmock.AnyArg()).WillReturnError(errors.New("connection refused"))
+
+	_, err = NewRepository(mock).PriceStats(context.Background(), 42, "BRL", time.Now())
+
+	assert.EqualError(t, err, "connection refused")
+}
+
+func TestRepository_DailyLowestPrices(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	since := time.Date(2025, 11, 2, 10, 0, 0, 0, time.UTC)
+	day := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT date_trunc\\('day', checked_at, 'UTC'\\) AS day, min\\(price\\).*GROUP BY day\\s+ORDER BY day").
+		WithArgs(int64(42), "BRL", since).
+		WillReturnRows(pgxmock.NewRows([]string{"day", "min"}).
+			AddRow(day, 2100.0).
+			AddRow(day.AddDate(0, 0, 1), 1800.0))
+
+	points, err := NewRepository(mock).DailyLowestPrices(context.Background(), 42, "BRL", since)
+
+	require.NoError(t, err)
+	assert.Equal(t, []domain.PricePoint{
+		{AlertID: 42, Price: 2100, Currency: "BRL", CheckedAt: day},
+		{AlertID: 42, Price: 1800, Currency: "BRL", CheckedAt: day.AddDate(0, 0, 1)},
+	}, points)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,25 +2,61 @@ package database
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"time"
 
+	"github.com/Luzin7/alert-service/internal/domain"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type DBConnection interface {
 	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
 }
 
+// DefaultQueryTimeout bounds every repository call that arrives without an
+// earlier deadline.
+const DefaultQueryTimeout = 5 * time.Second
+
 type Repository struct {
-	database DBConnection
+	database     DBConnection
+	queryTimeout time.Duration
+}
+
+type RepositoryOption func(*Repository)
+
+// WithQueryTimeout sets the deadline applied to each call; zero disables it.
+func WithQueryTimeout(timeout time.Duration) RepositoryOption {
+	return func(r *Repository) {
+		r.queryTimeout = timeout
+	}
+}
+
+func NewRepository(db DBConnection, opts ...RepositoryOption) *Repository {
+	r := &Repository{
+		database:     db,
+		queryTimeout: DefaultQueryTimeout,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
-func NewRepository(db DBConnection) *Repository {
-	return &Repository{
-		database: db,
+func (r *Repository) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.queryTimeout <= 0 {
+		return ctx, func() {}
 	}
+	return context.WithTimeout(ctx, r.queryTimeout)
 }
 
 func (r *Repository) GetUserEmail(ctx context.Context, alertID int64) (string, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	var email string
 	err := r.database.QueryRow(ctx, "SELECT email FROM users WHERE alert_id=$1", alertID).Scan(&email)
 	if err != nil {
@@ -28,3 +64,188 @@ func (r *Repository) GetUserEmail(ctx context.Context, alertID int64) (string, e
 	}
 	return email, nil
 }
+
+// GetRecipient returns the user behind an alert with their notification
+// preferences, or the default preferences when they have none.
+func (r *Repository) GetRecipient(ctx context.Context, alertID int64) (domain.Recipient, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var email string
+	var hasPreferences bool
+	var prefs domain.Recipient
+	var channels, optedOutTriggers []string
+	var digest string
+	var quietStart, quietEnd int16
+	err := r.database.QueryRow(ctx, `SELECT u.email, p.id IS NOT NULL,
+       COALESCE(p.name, ''), COALESCE(p.locale, ''), COALESCE(p.timezone, ''), COALESCE(p.display_currency, ''),
+       COALESCE(p.channels, '{}'),
+       COALESCE(p.quiet_hours_start, 0), COALESCE(p.quiet_hours_end, 0), COALESCE(p.quiet_hours_urgent, false),
+       COALESCE(p.opted_out, false), COALESCE(p.opted_out_triggers, '{}'),
+       COALESCE(p.webhook_url, ''), COALESCE(p.slack_webhook_url, ''), COALESCE(p.telegram_chat_id, ''),
+       COALESCE(p.digest, ''), COALESCE(p.link_providers, '{}')
+FROM users u
+LEFT JOIN notification_preferences p ON lower(p.email) = lower(u.email)
+WHERE u.alert_id=$1`, alertID).
+		Scan(&email, &hasPreferences, &prefs.Name, &prefs.Locale, &prefs.Timezone, &prefs.Currency, &channels,
+			&quietStart, &quietEnd, &prefs.UrgentInQuietHours, &prefs.OptedOut, &optedOutTriggers,
+			&prefs.WebhookURL, &prefs.SlackWebhookURL, &prefs.TelegramChatID, &digest, &prefs.LinkProviders)
+	if err != nil {
+		return domain.Recipient{}, err
+	}
+
+	recipient := domain.NewRecipient(alertID, email)
+	if !hasPreferences {
+		return recipient, nil
+	}
+
+	recipient.Name = prefs.Name
+	if prefs.Locale != "" {
+		recipient.Locale = prefs.Locale
+	}
+	if prefs.Timezone != "" {
+		recipient.Timezone = prefs.Timezone
+	}
+	recipient.Currency = strings.ToUpper(prefs.Currency)
+	recipient.Channels = make([]domain.Channel, 0, len(channels))
+	for _, channel := range channels {
+		recipient.Channels = append(recipient.Channels, domain.Channel(channel))
+	}
+	recipient.QuietHours = domain.QuietHours{Start: int(quietStart), End: int(quietEnd)}
+	recipient.UrgentInQuietHours = prefs.UrgentInQuietHours
+	recipient.OptedOut = prefs.OptedOut
+	for _, trigger := range optedOutTriggers {
+		recipient.OptedOutTriggers = append(recipient.OptedOutTriggers, domain.Trigger(trigger))
+	}
+	recipient.WebhookURL = prefs.WebhookURL
+	recipient.SlackWebhookURL = prefs.SlackWebhookURL
+	recipient.TelegramChatID = prefs.TelegramChatID
+	if digest != "" {
+		recipient.Digest = domain.DigestFrequency(digest)
+	}
+	if len(prefs.LinkProviders) > 0 {
+		recipient.LinkProviders = prefs.LinkProviders
+	}
+	return recipient, nil
+}
+
+func (r *Repository) SaveTrackedLink(ctx context.Context, link domain.TrackedLink) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.database.Exec(ctx,
+		"INSERT INTO tracked_links (id, alert_id, message_id, provider, url, created_at) VALUES ($1, $2, $3, $4, $5, $6)",
+		link.ID, link.AlertID, link.MessageID, link.Provider, link.URL, link.CreatedAt)
+	return err
+}
+
+func (r *Repository) GetTrackedLink(ctx context.Context, id string) (domain.TrackedLink, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	link := domain.TrackedLink{ID: id}
+	err := r.database.QueryRow(ctx,
+		"SELECT alert_id, message_id, provider, url, created_at FROM tracked_links WHERE id=$1", id).
+		Scan(&link.AlertID, &link.MessageID, &link.Provider, &link.URL, &link.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.TrackedLink{}, domain.ErrTrackedLinkNotFound
+	}
+	if err != nil {
+		return domain.TrackedLink{}, err
+	}
+	return link, nil
+}
+
+func (r *Repository) RecordClick(ctx context.Context, click domain.LinkClick) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.database.Exec(ctx,
+		"INSERT INTO link_clicks (link_id, alert_id, provider, user_agent, clicked_at) VALUES ($1, $2, $3, $4, $5)",
+		click.LinkID, click.AlertID, click.Provider, click.UserAgent, click.ClickedAt)
+	return err
+}
+
+const notificationColumns = "id, alert_id, message_id, recipient, channel, subject, trigger, old_price, new_price, currency, status, response, created_at, updated_at"
+
+func (r *Repository) CreateNotification(ctx context.Context, n domain.Notification) (int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var id int64
+	err := r.database.QueryRow(ctx,
+		"INSERT INTO notifications (alert_id, message_id, recipient, channel, subject, trigger, old_price, new_price, currency, status) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id",
+		n.AlertID, n.MessageID, n.Recipient, string(n.Channel), n.Subject, string(n.Trigger), n.OldPrice, n.NewPrice, n.Currency, string(domain.NotificationPending)).
+		Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *Repository) MarkNotificationSent(ctx context.Context, id int64, response string) error {
+	return r.finishNotification(ctx, id, domain.NotificationSent, response)
+}
+
+func (r *Repository) MarkNotificationFailed(ctx context.Context, id int64, reason string) error {
+	return r.finishNotification(ctx, id, domain.NotificationFailed, reason)
+}
+
+func (r *Repository) finishNotification(ctx context.Context, id int64, status domain.NotificationStatus, response string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tag, err := r.database.Exec(ctx,
+		"UPDATE notifications SET status=$2, response=$3, updated_at=now() WHERE id=$1",
+		id, string(status), response)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotificationNotFound
+	}
+	return nil
+}
+
+// NotificationsByAlert returns the latest notifications of an alert, newest
+// first.
+func (r *Repository) NotificationsByAlert(ctx context.Context, alertID int64, limit int) ([]domain.Notification, error) {
+	return r.queryNotifications(ctx,
+		"SELECT "+notificationColumns+" FROM notifications WHERE alert_id=$1 ORDER BY created_at DESC, id DESC LIMIT $2",
+		alertID, limit)
+}
+
+// NotificationsByRecipient returns the latest notifications sent to a user's
+// email address, newest first. The address is matched case-insensitively.
+func (r *Repository) NotificationsByRecipient(ctx context.Context, email string, limit int) ([]domain.Notification, error) {
+	return r.queryNotifications(ctx,
+		"SELECT "+notificationColumns+" FROM notifications WHERE lower(recipient)=lower($1) ORDER BY created_at DESC, id DESC LIMIT $2",
+		email, limit)
+}
+
+func (r *Repository) queryNotifications(ctx context.Context, sql string, args ...interface{}) ([]domain.Notification, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.database.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []domain.Notification
+	for rows.Next() {
+		var n domain.Notification
+		var channel, trigger, status string
+		err := rows.Scan(&n.ID, &n.AlertID, &n.MessageID, &n.Recipient, &channel, &n.Subject, &trigger,
+			&n.OldPrice, &n.NewPrice, &n.Currency, &status, &n.Response, &n.CreatedAt, &n.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		n.Channel = domain.Channel(channel)
+		n.Trigger = domain.Trigger(trigger)
+		n.Status = domain.NotificationStatus(status)
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
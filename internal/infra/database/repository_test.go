@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/Luzin7/alert-service/internal/domain"
 	"github.com/jackc/pgx/v5"
 	"github.com/pashagolub/pgxmock/v4"
 	"github.com/stretchr/testify/assert"
@@ -153,3 +155,370 @@ func TestRepository_GetUserEmail_ContextCancellation(t *testing.T) {
 	assert.Empty(t, email)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestRepository_SaveTrackedLink(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	repo := NewRepository(mock)
+
+	link := domain.TrackedLink{
+		ID:        "abc123",
+		AlertID:   42,
+		MessageID: "msg-123",
+		Provider:  "kayak",
+		URL:       "https://www.kayak.com/flights/GRU-JFK/2025-12-15",
+		CreatedAt: time.Date(2025, 12, 2, 10, 0, 0, 0, time.UTC),
+	}
+
+	mock.ExpectExec("INSERT INTO tracked_links").
+		WithArgs(link.ID, link.AlertID, link.MessageID, link.Provider, link.URL, link.CreatedAt).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	err = repo.SaveTrackedLink(context.Background(), link)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_GetTrackedLink(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	repo := NewRepository(mock)
+	createdAt := time.Date(2025, 12, 2, 10, 0, 0, 0, time.UTC)
+
+	rows := pgxmock.NewRows([]string{"alert_id", "message_id", "provider", "url", "created_at"}).
+		AddRow(int64(42), "msg-123", "kayak", "https://www.kayak.com", createdAt)
+	mock.ExpectQuery("SELECT alert_id, message_id, provider, url, created_at FROM tracked_links WHERE id=\\$1").
+		WithArgs("abc123").
+		WillReturnRows(rows)
+
+	link, err := repo.GetTrackedLink(context.Background(), "abc123")
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.TrackedLink{
+		ID:        "abc123",
+		AlertID:   42,
+		MessageID: "msg-123",
+		Provider:  "kayak",
+		URL:       "https://www.kayak.com",
+		CreatedAt: createdAt,
+	}, link)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_GetTrackedLink_NotFound(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	repo := NewRepository(mock)
+
+	mock.ExpectQuery("SELECT alert_id, message_id, provider, url, created_at FROM tracked_links WHERE id=\\$1").
+		WithArgs("missing").
+		WillReturnError(pgx.ErrNoRows)
+
+	_, err = repo.GetTrackedLink(context.Background(), "missing")
+
+	assert.ErrorIs(t, err, domain.ErrTrackedLinkNotFound)
+}
+
+func TestRepository_RecordClick(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	repo := NewRepository(mock)
+
+	click := domain.LinkClick{
+		LinkID:    "abc123",
+		AlertID:   42,
+		Provider:  "kayak",
+		UserAgent: "Mozilla/5.0",
+		ClickedAt: time.Date(2025, 12, 2, 11, 0, 0, 0, time.UTC),
+	}
+
+	mock.ExpectExec("INSERT INTO link_clicks").
+		WithArgs(click.LinkID, click.AlertID, click.Provider, click.UserAgent, click.ClickedAt).
+		WillReturnError(errors.New("connection reset"))
+
+	err = repo.RecordClick(context.Background(), click)
+
+	assert.EqualError(t, err, "connection reset")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_CreateNotification(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	repo := NewRepository(mock)
+
+	notification := domain.Notification{
+		AlertID:   42,
+		MessageID: "abc-123",
+		Recipient: "user@example.com",
+		Channel:   domain.ChannelEmail,
+		Subject:   "Preço caiu: GRU → JFK",
+		Trigger:   domain.TriggerTargetReached,
+		OldPrice:  2500,
+		NewPrice:  1800,
+		Currency:  "BRL",
+	}
+
+	mock.ExpectQuery("INSERT INTO notifications .* RETURNING id").
+		WithArgs(int64(42), "abc-123", "user@example.com", "email", "Preço caiu: GRU → JFK", "target_reached", 2500.0, 1800.0, "BRL", "pending").
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(7)))
+
+	id, err := repo.CreateNotification(context.Background(), notification)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_MarkNotification(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mark     func(repo *Repository) error
+		status   string
+		response string
+	}{
+		{
+			name: "sent",
+			mark: func(repo *Repository) error {
+				return repo.MarkNotificationSent(context.Background(), 7, "250 2.0.0 ok queued as ABC123")
+			},
+			status:   "sent",
+			response: "250 2.0.0 ok queued as ABC123",
+		},
+		{
+			name: "failed",
+			mark: func(repo *Repository) error {
+				return repo.MarkNotificationFailed(context.Background(), 7, "smtp recipient rejected: 550 no such user")
+			},
+			status:   "failed",
+			response: "smtp recipient rejected: 550 no such user",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock, err := pgxmock.NewConn()
+			require.NoError(t, err)
+			defer mock.Close(context.Background())
+
+			mock.ExpectExec("UPDATE notifications SET status=\\$2, response=\\$3").
+				WithArgs(int64(7), tc.status, tc.response).
+				WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+			require.NoError(t, tc.mark(NewRepository(mock)))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_MarkNotificationSent_NotFound(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	mock.ExpectExec("UPDATE notifications").
+		WithArgs(int64(99), "sent", "250 ok").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	err = NewRepository(mock).MarkNotificationSent(context.Background(), 99, "250 ok")
+
+	assert.ErrorIs(t, err, domain.ErrNotificationNotFound)
+}
+
+func notificationRows() *pgxmock.Rows {
+	createdAt := time.Date(2025, 12, 2, 10, 0, 0, 0, time.UTC)
+	return pgxmock.NewRows([]string{"id", "alert_id", "message_id", "recipient", "channel", "subject", "trigger", "old_price", "new_price", "currency", "status", "response", "created_at", "updated_at"}).
+		AddRow(int64(8), int64(42), "def-456", "user@example.com", "slack", "s2", "price_drop", 1800.0, 1500.0, "BRL", "failed", "smtp transient failure: 421 try later", createdAt.Add(time.Hour), createdAt.Add(time.Hour)).
+		AddRow(int64(7), int64(42), "abc-123", "user@example.com", "email", "s1", "target_reached", 2500.0, 1800.0, "BRL", "sent", "250 ok", createdAt, createdAt)
+}
+
+func TestRepository_NotificationsByAlert(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	mock.ExpectQuery("SELECT .* FROM notifications WHERE alert_id=\\$1 ORDER BY created_at DESC, id DESC LIMIT \\$2").
+		WithArgs(int64(42), 20).
+		WillReturnRows(notificationRows())
+
+	notifications, err := NewRepository(mock).NotificationsByAlert(context.Background(), 42, 20)
+
+	require.NoError(t, err)
+	require.Len(t, notifications, 2)
+	assert.Equal(t, int64(8), notifications[0].ID)
+	assert.Equal(t, domain.NotificationFailed, notifications[0].Status)
+	assert.Equal(t, domain.ChannelSlack, notifications[0].Channel)
+	assert.Equal(t, domain.TriggerPriceDrop, notifications[0].Trigger)
+	assert.Equal(t, "smtp transient failure: 421 try later", notifications[0].Response)
+	assert.Equal(t, domain.NotificationSent, notifications[1].Status)
+	assert.Equal(t, 1800.0, notifications[1].NewPrice)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_NotificationsByRecipient(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	mock.ExpectQuery("SELECT .* FROM notifications WHERE lower\\(recipient\\)=lower\\(\\$1\\)").
+		WithArgs("User@Example.com", 10).
+		WillReturnRows(notificationRows())
+
+	notifications, err := NewRepository(mock).NotificationsByRecipient(context.Background(), "User@Example.com", 10)
+
+	require.NoError(t, err)
+	assert.Len(t, notifications, 2)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_NotificationsByAlert_Error(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	mock.ExpectQuery("SELECT .* FROM notifications").
+		WithArgs(int64(42), 20).
+		WillReturnError(errors.New("connection reset"))
+
+	notifications, err := NewRepository(mock).NotificationsByAlert(context.Background(), 42, 20)
+
+	assert.EqualError(t, err, "connection reset")
+	assert.Nil(t, notifications)
+}
+
+type deadlineRow struct{}
+
+func (deadlineRow) Scan(dest ...any) error {
+	*dest[0].(*string) = "user@example.com"
+	return nil
+}
+
+type deadlineConn struct {
+	DBConnection
+	deadline time.Time
+	ok       bool
+}
+
+func (c *deadlineConn) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	c.deadline, c.ok = ctx.Deadline()
+	return deadlineRow{}
+}
+
+func TestRepository_QueryTimeout(t *testing.T) {
+	testCases := []struct {
+		name         string
+		opts         []RepositoryOption
+		wantDeadline bool
+		maxRemaining time.Duration
+	}{
+		{name: "default", wantDeadline: true, maxRemaining: DefaultQueryTimeout},
+		{name: "custom", opts: []RepositoryOption{WithQueryTimeout(100 * time.Millisecond)}, wantDeadline: true, maxRemaining: 100 * time.Millisecond},
+		{name: "disabled", opts: []RepositoryOption{WithQueryTimeout(0)}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conn := &deadlineConn{}
+			start := time.Now()
+
+			_, err := NewRepository(conn, tc.opts...).GetUserEmail(context.Background(), 1)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantDeadline, conn.ok)
+			if tc.wantDeadline {
+				assert.WithinDuration(t, start.Add(tc.maxRemaining), conn.deadline, 50*time.Millisecond)
+			}
+		})
+	}
+}
+
+var recipientColumns = []string{"email", "has_preferences", "name", "locale", "timezone", "display_currency", "channels", "quiet_hours_start", "quiet_hours_end", "quiet_hours_urgent", "opted_out", "opted_out_triggers", "webhook_url", "slack_webhook_url", "telegram_chat_id", "digest", "link_providers"}
+
+func TestRepository_GetRecipient_Defaults(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	mock.ExpectQuery("SELECT u.email, p.id IS NOT NULL.*FROM users u\\s+LEFT JOIN notification_preferences p").
+		WithArgs(int64(42)).
+		WillReturnRows(pgxmock.NewRows(recipientColumns).
+			AddRow("user@example.com", false, "", "", "", "", []string{}, int16(0), int16(0), false, false, []string{}, "", "", "", "", []string{}))
+
+	recipient, err := NewRepository(mock).GetRecipient(context.Background(), 42)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewRecipient(42, "user@example.com"), recipient)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_GetRecipient_WithPreferences(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	mock.ExpectQuery("SELECT u.email").
+		WithArgs(int64(42)).
+		WillReturnRows(pgxmock.NewRows(recipientColumns).
+			AddRow("ana@example.com", true, "Ana", "es", "Europe/Madrid", "usd", []string{"email", "telegram"}, int16(1320), int16(420), true, false, []string{"price_drop"}, "", "", "987654", "weekly", []string{"kayak", "google_flights"}))
+
+	recipient, err := NewRepository(mock).GetRecipient(context.Background(), 42)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.Recipient{
+		AlertID:            42,
+		Name:               "Ana",
+		Email:              "ana@example.com",
+		Locale:             "es",
+		Timezone:           "Europe/Madrid",
+		Currency:           "USD",
+		Channels:           []domain.Channel{domain.ChannelEmail, domain.ChannelTelegram},
+		QuietHours:         domain.QuietHours{Start: 22 * 60, End: 7 * 60},
+		UrgentInQuietHours: true,
+		OptedOutTriggers:   []domain.Trigger{domain.TriggerPriceDrop},
+		TelegramChatID:     "987654",
+		Digest:             domain.DigestWeekly,
+		LinkProviders:      []string{"kayak", "google_flights"},
+	}, recipient)
+}
+
+func TestRepository_GetRecipient_NoChannels(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	mock.ExpectQuery("SELECT u.email").
+		WithArgs(int64(42)).
+		WillReturnRows(pgxmock.NewRows(recipientColumns).
+			AddRow("ana@example.com", true, "", "", "", "", []string{}, int16(0), int16(0), false, false, []string{}, "", "", "", "off", []string{}))
+
+	recipient, err := NewRepository(mock).GetRecipient(context.Background(), 42)
+
+	require.NoError(t, err)
+	assert.Empty(t, recipient.Channels)
+	assert.Equal(t, domain.DefaultLocale, recipient.Locale)
+}
+
+func TestRepository_GetRecipient_NotFound(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	mock.ExpectQuery("SELECT u.email").
+		WithArgs(int64(999)).
+		WillReturnError(pgx.ErrNoRows)
+
+	_, err = NewRepository(mock).GetRecipient(context.Background(), 999)
+
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+}
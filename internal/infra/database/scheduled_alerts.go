@@ -0,0 +1,94 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// ScheduleAlert holds the alert until deliverAt. A pending alert with the
+// same AlertID is replaced, since only the latest price matters.
+func (r *Repository) ScheduleAlert(ctx context.Context, alert *domain.Alert, deliverAt time.Time) error {
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	_, err = r.database.Exec(ctx,
+		`INSERT INTO scheduled_alerts (alert_id, message_id, payload, deliver_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (alert_id) WHERE status = 'pending' DO UPDATE
+SET message_id = EXCLUDED.message_id, payload = EXCLUDED.payload, deliver_at = EXCLUDED.deliver_at,
+    attempts = 0, locked_until = '-infinity', version = scheduled_alerts.version + 1, updated_at = now()`,
+		alert.ID, alert.MessageID, payload, deliverAt)
+	return err
+}
+
+// ClaimScheduledAlert leases the oldest due alert to this worker. A worker
+// that stops before marking it lets the lease expire, and the alert is
+// claimed again.
+func (r *Repository) ClaimScheduledAlert(ctx context.Context, lease time.Duration) (domain.ScheduledAlert, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var scheduled domain.ScheduledAlert
+	var payload []byte
+	err := r.database.QueryRow(ctx, `UPDATE scheduled_alerts SET locked_until = now() + make_interval(secs => $1), attempts = attempts + 1, updated_at = now()
+WHERE id = (
+    SELECT id FROM scheduled_alerts
+    WHERE status = 'pending' AND deliver_at <= now() AND locked_until <= now()
+    ORDER BY deliver_at, id
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, payload, deliver_at, attempts, version`, lease.Seconds()).
+		Scan(&scheduled.ID, &payload, &scheduled.DeliverAt, &scheduled.Attempts, &scheduled.Version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ScheduledAlert{}, domain.ErrNoScheduledAlertDue
+	}
+	if err != nil {
+		return domain.ScheduledAlert{}, err
+	}
+
+	if err := json.Unmarshal(payload, &scheduled.Alert); err != nil {
+		return domain.ScheduledAlert{}, err
+	}
+	return scheduled, nil
+}
+
+func (r *Repository) MarkScheduledAlertSent(ctx context.Context, scheduled domain.ScheduledAlert) error {
+	return r.finishScheduledAlert(ctx, scheduled, "sent", "")
+}
+
+func (r *Repository) MarkScheduledAlertSkipped(ctx context.Context, scheduled domain.ScheduledAlert, reason string) error {
+	return r.finishScheduledAlert(ctx, scheduled, "skipped", reason)
+}
+
+// RetryScheduledAlert keeps the alert pending; it is claimed again once the
+// lease taken by ClaimScheduledAlert expires.
+func (r *Repository) RetryScheduledAlert(ctx context.Context, scheduled domain.ScheduledAlert, reason string) error {
+	return r.finishScheduledAlert(ctx, scheduled, "pending", reason)
+}
+
+func (r *Repository) MarkScheduledAlertFailed(ctx context.Context, scheduled domain.ScheduledAlert, reason string) error {
+	return r.finishScheduledAlert(ctx, scheduled, "failed", reason)
+}
+
+// finishScheduledAlert only touches the row while it still holds the claimed
+// version; a newer alert scheduled meanwhile stays pending.
+func (r *Repository) finishScheduledAlert(ctx context.Context, scheduled domain.ScheduledAlert, status, response string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.database.Exec(ctx,
+		"UPDATE scheduled_alerts SET status=$3, response=$4, updated_at=now() WHERE id=$1 AND version=$2",
+		scheduled.ID, scheduled.Version, status, response)
+	return err
+}
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scheduledTestAlert() *domain.Alert {
+	return &domain.Alert{
+		ID:           42,
+		MessageID:    "abc-123",
+		Origin:       "GRU",
+		Destination:  "JFK",
+		OutboundDate: time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC),
+		NewPrice:     1800,
+		OldPrice:     2500,
+		Currency:     "BRL",
+		Cabin:        domain.CabinEconomy,
+		Passengers:   1,
+	}
+}
+
+func TestRepository_ScheduleAlert(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	alert := scheduledTestAlert()
+	payload, err := json.Marshal(alert)
+	require.NoError(t, err)
+	deliverAt := time.Date(2025, 12, 2, 10, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec("INSERT INTO scheduled_alerts .* ON CONFLICT \\(alert_id\\) WHERE status = 'pending' DO UPDATE.*version = scheduled_alerts.version \\+ 1").
+		WithArgs(int64(42), "abc-123", payload, deliverAt).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	err = NewRepository(mock).ScheduleAlert(context.Background(), alert, deliverAt)
+
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_ClaimScheduledAlert(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	alert := scheduledTestAlert()
+	payload, err := json.Marshal(alert)
+	require.NoError(t, err)
+	deliverAt := time.Date(2025, 12, 2, 10, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("UPDATE scheduled_alerts SET locked_until = now\\(\\) \\+ make_interval\\(secs => \\$1\\).*deliver_at <= now\\(\\).*FOR UPDATE SKIP LOCKED").
+		WithArgs(300.0).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "payload", "deliver_at", "attempts", "version"}).
+			AddRow(int64(7), payload, deliverAt, 1, int64(2)))
+
+	scheduled, err := NewRepository(mock).ClaimScheduledAlert(context.Background(), 5*time.Minute)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.ScheduledAlert{ID: 7, Alert: *alert, DeliverAt: deliverAt, Attempts: 1, Version: 2}, scheduled)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepository_ClaimScheduledAlert_NoneDue(t *testing.T) {
+	mock, err := pgxmock.NewConn()
+	require.NoError(t, err)
+	defer mock.Close(context.Background())
+
+	mock.ExpectQuery("UPDATE scheduled_alerts SET locked_until").
+		WithArgs(300.0).
+		WillReturnError(pgx.ErrNoRows)
+
+	_, err = NewRepository(mock).ClaimScheduledAlert(context.Background(), 5*time.Minute)
+
+	assert.ErrorIs(t, err, domain.ErrNoScheduledAlertDue)
+}
+
+func TestRepository_FinishScheduledAlert(t *testing.T) {
+	scheduled := domain.ScheduledAlert{ID: 7, Alert: domain.Alert{ID: 42}, Version: 2}
+
+	testCases := []struct {
+		name     string
+		finish   func(repo *Repository) error
+		status   string
+		response string
+	}{
+		{
+			name:   "sent",
+			finish: func(repo *Repository) error { return repo.MarkScheduledAlertSent(context.Background(), scheduled) },
+			status: "sent",
+		},
+		{
+			name: "skipped",
+			finish: func(repo *Repository) error {
+				return repo.MarkScheduledAlertSkipped(context.Background(), scheduled, "opted_out")
+			},
+			status:   "skipped",
+			response: "opted_out",
+		},
+		{
+			name: "retry",
+			finish: func(repo *Repository) error {
+				return repo.RetryScheduledAlert(context.Background(), scheduled, "smtp transient failure: 421 try later")
+			},
+			status:   "pending",
+			response: "smtp transient failure: 421 try later",
+		},
+		{
+			name: "failed",
+			finish: func(repo *Repository) error {
+				return repo.MarkScheduledAlertFailed(context.Background(), scheduled, "smtp recipient rejected: 550 no such user")
+			},
+			status:   "failed",
+			response: "smtp recipient rejected: 550 no such user",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock, err := pgxmock.NewConn()
+			require.NoError(t, err)
+			defer mock.Close(context.Background())
+
+			mock.ExpectExec("UPDATE scheduled_alerts SET status=\\$3, response=\\$4, updated_at=now\\(\\) WHERE id=\\$1 AND version=\\$2").
+				WithArgs(int64(7), int64(2), tc.status, tc.response).
+				WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+			require.NoError(t, tc.finish(NewRepository(mock)))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,177 @@
+// Package i18n holds the message catalogs of the alert emails and formats
+// numbers, prices and dates for each locale.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLocale is the last step of every fallback chain, so its catalog
+// must define every key.
+const DefaultLocale = "pt-BR"
+
+//go:embed locales/*.json
+var locales embed.FS
+
+type Catalog map[string]string
+
+type Bundle struct {
+	catalogs map[string]Catalog
+}
+
+// NewBundle loads the embedded catalogs, one per locales/<tag>.json file.
+func NewBundle() (*Bundle, error) {
+	entries, err := fs.ReadDir(locales, "locales")
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Bundle{catalogs: make(map[string]Catalog, len(entries))}
+	for _, entry := range entries {
+		content, err := locales.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var catalog Catalog
+		if err := json.Unmarshal(content, &catalog); err != nil {
+			return nil, fmt.Errorf("catalog %s: %w", entry.Name(), err)
+		}
+		b.catalogs[strings.TrimSuffix(entry.Name(), ".json")] = catalog
+	}
+
+	if _, ok := b.catalogs[DefaultLocale]; !ok {
+		return nil, fmt.Errorf("missing catalog for default locale %s", DefaultLocale)
+	}
+	return b, nil
+}
+
+func (b *Bundle) Locales() []string {
+	tags := make([]string, 0, len(b.catalogs))
+	for tag := range b.catalogs {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// Match resolves a requested locale to a catalog: the exact tag, then the
+// same language (es-MX uses es, en uses en-US), then DefaultLocale. Matching
+// ignores case and accepts underscores.
+func (b *Bundle) Match(locale string) string {
+	locale = strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+	language, _, _ := strings.Cut(locale, "-")
+
+	var sameLanguage string
+	for _, tag := range b.Locales() {
+		if strings.EqualFold(tag, locale) {
+			return tag
+		}
+		tagLanguage, _, _ := strings.Cut(tag, "-")
+		if language != "" && strings.EqualFold(tagLanguage, language) && (sameLanguage == "" || strings.EqualFold(tag, language)) {
+			sameLanguage = tag
+		}
+	}
+	if sameLanguage != "" {
+		return sameLanguage
+	}
+	return DefaultLocale
+}
+
+func (b *Bundle) Localizer(locale string) *Localizer {
+	tag := b.Match(locale)
+	return &Localizer{
+		locale:   tag,
+		catalog:  b.catalogs[tag],
+		fallback: b.catalogs[DefaultLocale],
+	}
+}
+
+type Localizer struct {
+	locale   string
+	catalog  Catalog
+	fallback Catalog
+}
+
+func (l *Localizer) Locale() string {
+	return l.locale
+}
+
+// T returns the message for key formatted with args, falling back to the
+// default catalog and finally to the key itself.
+func (l *Localizer) T(key string, args ...any) string {
+	message := l.lookup(key)
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+func (l *Localizer) lookup(key string) string {
+	if message, ok := l.catalog[key]; ok {
+		return message
+	}
+	if message, ok := l.fallback[key]; ok {
+		return message
+	}
+	return key
+}
+
+// Number formats value with the locale's separators.
+func (l *Localizer) Number(value float64, decimals int) string {
+	digits := strconv.FormatFloat(math.Abs(value), 'f', decimals, 64)
+	integer, fraction, _ := strings.Cut(digits, ".")
+
+	var b strings.Builder
+	if value < 0 && strings.Trim(digits, "0.") != "" {
+		b.WriteByte('-')
+	}
+	group := l.lookup("format.group")
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(group)
+		}
+		b.WriteRune(digit)
+	}
+	if fraction != "" {
+		b.WriteString(l.lookup("format.decimal"))
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
+
+// Price formats value in currency, using the currency symbol when the
+// catalog knows it and the ISO code otherwise.
+func (l *Localizer) Price(value float64, currency string) string {
+	amount := l.Number(value, 2)
+	currency = strings.ToUpper(currency)
+	if symbol, ok := l.symbol(currency); ok {
+		return strings.NewReplacer("{symbol}", symbol, "{amount}", amount).Replace(l.lookup("format.currency"))
+	}
+	return strings.TrimSpace(strings.NewReplacer("{code}", currency, "{amount}", amount).Replace(l.lookup("format.currency_code")))
+}
+
+func (l *Localizer) symbol(currency string) (string, bool) {
+	key := "currency." + currency
+	if symbol, ok := l.catalog[key]; ok {
+		return symbol, true
+	}
+	symbol, ok := l.fallback[key]
+	return symbol, ok
+}
+
+func (l *Localizer) Percent(value float64) string {
+	return strings.ReplaceAll(l.lookup("format.percent"), "{value}", l.Number(value, 1))
+}
+
+func (l *Localizer) Date(t time.Time) string {
+	return t.Format(l.lookup("format.date"))
+}
@@ -0,0 +1,125 @@
+package i18n
+
+import (
+	"regexp"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBundle(t *testing.T) *Bundle {
+	t.Helper()
+	bundle, err := NewBundle()
+	require.NoError(t, err)
+	return bundle
+}
+
+var verb = regexp.MustCompile(`%[^%]`)
+
+func TestCatalogs_DefineEveryKey(t *testing.T) {
+	bundle := newTestBundle(t)
+	assert.Equal(t, []string{"en-US", "es", "pt-BR"}, bundle.Locales())
+
+	reference := bundle.catalogs[DefaultLocale]
+	var keys []string
+	for key := range reference {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, locale := range bundle.Locales() {
+		catalog := bundle.catalogs[locale]
+		t.Run(locale, func(t *testing.T) {
+			assert.Len(t, catalog, len(reference), "catalog has keys missing from %s", DefaultLocale)
+			for _, key := range keys {
+				message, ok := catalog[key]
+				if !assert.True(t, ok, "missing key %s", key) {
+					continue
+				}
+				assert.NotEmpty(t, message, "empty message for %s", key)
+				assert.Equal(t, len(verb.FindAllString(reference[key], -1)), len(verb.FindAllString(message, -1)),
+					"placeholders of %s differ from %s", key, DefaultLocale)
+			}
+		})
+	}
+}
+
+func TestBundle_Match(t *testing.T) {
+	bundle := newTestBundle(t)
+
+	testCases := map[string]string{
+		"pt-BR": "pt-BR",
+		"pt_br": "pt-BR",
+		"pt":    "pt-BR",
+		"pt-PT": "pt-BR",
+		"en-US": "en-US",
+		"en":    "en-US",
+		"en-GB": "en-US",
+		"es":    "es",
+		"es-MX": "es",
+		"ES-ar": "es",
+		"fr-FR": DefaultLocale,
+		"":      DefaultLocale,
+	}
+
+	for requested, expected := range testCases {
+		assert.Equal(t, expected, bundle.Match(requested), "locale %q", requested)
+	}
+}
+
+func TestLocalizer_T(t *testing.T) {
+	bundle := newTestBundle(t)
+
+	assert.Equal(t, "Hi Ana!", bundle.Localizer("en-US").T("email.greeting_name", "Ana"))
+	assert.Equal(t, "¡Hola!", bundle.Localizer("es").T("email.greeting"))
+	assert.Equal(t, "email.unknown", bundle.Localizer("es").T("email.unknown"))
+}
+
+func TestLocalizer_T_FallsBackToDefaultCatalog(t *testing.T) {
+	bundle := newTestBundle(t)
+	localizer := bundle.Localizer("es")
+	localizer.catalog = Catalog{}
+
+	assert.Equal(t, "Olá!", localizer.T("email.greeting"))
+}
+
+func TestLocalizer_Price(t *testing.T) {
+	bundle := newTestBundle(t)
+
+	testCases := []struct {
+		locale   string
+		value    float64
+		currency string
+		expected string
+	}{
+		{locale: "pt-BR", value: 1800, currency: "BRL", expected: "R$ 1.800,00"},
+		{locale: "en-US", value: 1800, currency: "USD", expected: "$1,800.00"},
+		{locale: "pt-BR", value: 1800, currency: "USD", expected: "US$ 1.800,00"},
+		{locale: "es", value: 1234567.891, currency: "EUR", expected: "1.234.567,89 €"},
+		{locale: "en-US", value: 99.5, currency: "brl", expected: "R$99.50"},
+		{locale: "en-US", value: 1800, currency: "ARS", expected: "1,800.00 ARS"},
+		{locale: "pt-BR", value: 0.004, currency: "BRL", expected: "R$ 0,00"},
+		{locale: "pt-BR", value: -12.5, currency: "BRL", expected: "R$ -12,50"},
+		{locale: "pt-BR", value: 100, currency: "", expected: "100,00"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, bundle.Localizer(tc.locale).Price(tc.value, tc.currency), "%s %v %s", tc.locale, tc.value, tc.currency)
+	}
+}
+
+func TestLocalizer_PercentAndDate(t *testing.T) {
+	bundle := newTestBundle(t)
+	date := time.Date(2025, 12, 5, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "28,0%", bundle.Localizer("pt-BR").Percent(28))
+	assert.Equal(t, "28.0%", bundle.Localizer("en-US").Percent(28))
+	assert.Equal(t, "12,3 %", bundle.Localizer("es").Percent(12.34))
+
+	assert.Equal(t, "05/12/2025", bundle.Localizer("pt-BR").Date(date))
+	assert.Equal(t, "12/05/2025", bundle.Localizer("en-US").Date(date))
+	assert.Equal(t, "05/12/2025", bundle.Localizer("es").Date(date))
+}
@@ -0,0 +1,223 @@
+package messenger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+type State int32
+
+const (
+	StateConnecting State = iota
+	StateConnected
+	StateClosed
+)
+
+func (s State) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateClosed:
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
+var ErrManagerClosed = errors.New("messenger connection manager closed")
+
+type Conn interface {
+	Channel() (*amqp091.Channel, error)
+	NotifyClose(receiver chan *amqp091.Error) chan *amqp091.Error
+	IsClosed() bool
+	Close() error
+}
+
+type Dialer func() (Conn, error)
+
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+}
+
+var DefaultBackoff = Backoff{Initial: 500 * time.Millisecond, Max: 30 * time.Second}
+
+// Delay returns a full-jitter exponential delay for the given attempt, so
+// replicas that lost the broker at the same time do not reconnect in lockstep.
+func (b Backoff) Delay(attempt int) time.Duration {
+	ceiling := b.Max
+	if attempt < 32 {
+		if exp := b.Initial << attempt; exp > 0 && exp < b.Max {
+			ceiling = exp
+		}
+	}
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
+
+type Manager struct {
+	dial    Dialer
+	backoff Backoff
+
+	mu     sync.RWMutex
+	conn   Conn
+	state  State
+	ready  chan struct{}
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewManager(dial Dialer, backoff Backoff) *Manager {
+	return &Manager{
+		dial:    dial,
+		backoff: backoff,
+		state:   StateConnecting,
+		ready:   make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+func (m *Manager) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	m.mu.Lock()
+	m.cancel = cancel
+	m.mu.Unlock()
+
+	go m.supervise(ctx)
+}
+
+func (m *Manager) State() State {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state
+}
+
+func (m *Manager) Connection(ctx context.Context) (Conn, error) {
+	for {
+		m.mu.RLock()
+		conn, state, ready := m.conn, m.state, m.ready
+		m.mu.RUnlock()
+
+		switch {
+		case state == StateClosed:
+			return nil, ErrManagerClosed
+		case state == StateConnected && !conn.IsClosed():
+			return conn, nil
+		}
+
+		select {
+		case <-ready:
+		case <-m.done:
+			return nil, ErrManagerClosed
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (m *Manager) Channel(ctx context.Context) (*amqp091.Channel, error) {
+	conn, err := m.Connection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return conn.Channel()
+}
+
+// Ping reports an error unless the broker is connected and accepts a new
+// channel, without waiting for a reconnection in progress.
+func (m *Manager) Ping(ctx context.Context) error {
+	if state := m.State(); state != StateConnected {
+		return fmt.Errorf("messenger connection is %s", state)
+	}
+
+	ch, err := m.Channel(ctx)
+	if err != nil {
+		return err
+	}
+	return ch.Close()
+}
+
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	cancel := m.cancel
+	m.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-m.done
+	}
+	return nil
+}
+
+func (m *Manager) supervise(ctx context.Context) {
+	defer close(m.done)
+
+	for {
+		conn, err := m.connect(ctx)
+		if err != nil {
+			m.setClosed()
+			return
+		}
+
+		closed := conn.NotifyClose(make(chan *amqp091.Error, 1))
+		m.setConnected(conn)
+		log.Printf("Conectado ao RabbitMQ")
+
+		select {
+		case <-ctx.Done():
+			conn.Close()
+			m.setClosed()
+			return
+		case amqpErr := <-closed:
+			log.Printf("Conexao com RabbitMQ perdida: %v", amqpErr)
+			m.setConnecting()
+		}
+	}
+}
+
+func (m *Manager) connect(ctx context.Context) (Conn, error) {
+	for attempt := 0; ; attempt++ {
+		conn, err := m.dial()
+		if err == nil {
+			return conn, nil
+		}
+
+		delay := m.backoff.Delay(attempt)
+		log.Printf("Falha conectando ao RabbitMQ (tentativa %d), nova tentativa em %s: %v", attempt+1, delay, err)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (m *Manager) setConnected(conn Conn) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.conn = conn
+	m.state = StateConnected
+	close(m.ready)
+}
+
+func (m *Manager) setConnecting() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state = StateConnecting
+	m.ready = make(chan struct{})
+}
+
+func (m *Manager) setClosed() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state = StateClosed
+}
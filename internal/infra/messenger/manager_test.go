@@ -0,0 +1,187 @@
+package messenger
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeConn struct {
+	mu       sync.Mutex
+	closed   bool
+	receiver chan *amqp091.Error
+}
+
+func (c *fakeConn) Channel() (*amqp091.Channel, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *fakeConn) NotifyClose(receiver chan *amqp091.Error) chan *amqp091.Error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.receiver = receiver
+	return receiver
+}
+
+func (c *fakeConn) IsClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+func (c *fakeConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
+
+func (c *fakeConn) drop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	c.receiver <- &amqp091.Error{Code: amqp091.ConnectionForced, Reason: "broker restarted"}
+}
+
+type fakeDialer struct {
+	mu       sync.Mutex
+	failures int
+	attempts atomic.Int32
+	conns    []*fakeConn
+}
+
+func (d *fakeDialer) dial() (Conn, error) {
+	d.attempts.Add(1)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.failures > 0 {
+		d.failures--
+		return nil, errors.New("connection refused")
+	}
+	conn := &fakeConn{}
+	d.conns = append(d.conns, conn)
+	return conn, nil
+}
+
+func (d *fakeDialer) conn(i int) *fakeConn {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.conns[i]
+}
+
+var testBackoff = Backoff{Initial: time.Millisecond, Max: 5 * time.Millisecond}
+
+func TestBackoff_Delay(t *testing.T) {
+	b := Backoff{Initial: 100 * time.Millisecond, Max: time.Second}
+
+	for attempt := 0; attempt < 64; attempt++ {
+		delay := b.Delay(attempt)
+		assert.GreaterOrEqual(t, delay, time.Duration(0))
+		assert.LessOrEqual(t, delay, time.Second)
+	}
+	assert.LessOrEqual(t, b.Delay(0), 100*time.Millisecond)
+}
+
+func TestManager_ConnectsAfterFailures(t *testing.T) {
+	dialer := &fakeDialer{failures: 3}
+	manager := NewManager(dialer.dial, testBackoff)
+	assert.Equal(t, StateConnecting, manager.State())
+
+	manager.Start(context.Background())
+	defer manager.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	conn, err := manager.Connection(ctx)
+
+	require.NoError(t, err)
+	assert.Same(t, dialer.conn(0), conn)
+	assert.Equal(t, StateConnected, manager.State())
+	assert.Equal(t, int32(4), dialer.attempts.Load())
+}
+
+func TestManager_ReconnectsWhenConnectionDrops(t *testing.T) {
+	dialer := &fakeDialer{}
+	manager := NewManager(dialer.dial, testBackoff)
+	manager.Start(context.Background())
+	defer manager.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	first, err := manager.Connection(ctx)
+	require.NoError(t, err)
+
+	dialer.conn(0).drop()
+
+	require.Eventually(t, func() bool {
+		conn, err := manager.Connection(ctx)
+		return err == nil && conn != first
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, StateConnected, manager.State())
+	assert.Equal(t, int32(2), dialer.attempts.Load())
+}
+
+func TestManager_ConnectionWaitsForContext(t *testing.T) {
+	dialer := &fakeDialer{failures: 1 << 30}
+	manager := NewManager(dialer.dial, testBackoff)
+	manager.Start(context.Background())
+	defer manager.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	conn, err := manager.Connection(ctx)
+
+	assert.Nil(t, conn)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, StateConnecting, manager.State())
+}
+
+func TestManager_Close(t *testing.T) {
+	dialer := &fakeDialer{}
+	manager := NewManager(dialer.dial, testBackoff)
+	manager.Start(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := manager.Connection(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, manager.Close())
+
+	assert.Equal(t, StateClosed, manager.State())
+	assert.True(t, dialer.conn(0).IsClosed())
+
+	_, err = manager.Connection(ctx)
+	assert.ErrorIs(t, err, ErrManagerClosed)
+}
+
+func TestManager_CloseWhileConnecting(t *testing.T) {
+	dialer := &fakeDialer{failures: 1 << 30}
+	manager := NewManager(dialer.dial, testBackoff)
+	manager.Start(context.Background())
+
+	require.NoError(t, manager.Close())
+
+	assert.Equal(t, StateClosed, manager.State())
+	_, err := manager.Channel(context.Background())
+	assert.ErrorIs(t, err, ErrManagerClosed)
+}
+
+func TestManager_Ping_NotConnected(t *testing.T) {
+	manager := NewManager((&fakeDialer{}).dial, testBackoff)
+
+	err := manager.Ping(context.Background())
+
+	assert.EqualError(t, err, "messenger connection is connecting")
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBPoolCollector(t *testing.T) {
+	collector := NewDBPoolCollector(func() DBPoolStats {
+		return DBPoolStats{
+			AcquiredConns:   3,
+			IdleConns:       2,
+			TotalConns:      5,
+			MaxConns:        10,
+			AcquireCount:    42,
+			AcquireDuration: 1500 * time.Millisecond,
+		}
+	})
+
+	expected := `
+# HELP alert_service_db_pool_acquired_connections Connections currently in use.
+# TYPE alert_service_db_pool_acquired_connections gauge
+alert_service_db_pool_acquired_connections 3
+# HELP alert_service_db_pool_max_connections Maximum size of the pool.
+# TYPE alert_service_db_pool_max_connections gauge
+alert_service_db_pool_max_connections 10
+# HELP alert_service_db_pool_acquires_total Successful connection acquisitions.
+# TYPE alert_service_db_pool_acquires_total counter
+alert_service_db_pool_acquires_total 42
+# HELP alert_service_db_pool_acquire_duration_seconds_total Time spent waiting to acquire connections.
+# TYPE alert_service_db_pool_acquire_duration_seconds_total counter
+alert_service_db_pool_acquire_duration_seconds_total 1.5
+`
+
+	err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"alert_service_db_pool_acquired_connections",
+		"alert_service_db_pool_max_connections",
+		"alert_service_db_pool_acquires_total",
+		"alert_service_db_pool_acquire_duration_seconds_total",
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, testutil.CollectAndCount(collector))
+}
@@ -0,0 +1,162 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "alert_service"
+
+var Registry = prometheus.NewRegistry()
+
+var (
+	MessagesConsumed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_consumed_total",
+		Help:      "Deliveries received from the broker.",
+	}, []string{"queue"})
+
+	MessagesAcked = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_acked_total",
+		Help:      "Deliveries acknowledged after successful processing.",
+	}, []string{"queue"})
+
+	MessagesNacked = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_nacked_total",
+		Help:      "Deliveries negatively acknowledged back to the broker.",
+	}, []string{"queue", "requeue"})
+
+	MessagesRetried = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_retried_total",
+		Help:      "Failed deliveries republished to a retry queue.",
+	}, []string{"queue", "delay"})
+
+	MessagesDeadLettered = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_dead_lettered_total",
+		Help:      "Deliveries moved to the dead-letter queue.",
+	}, []string{"queue", "reason"})
+
+	HandlerDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "handler_duration_seconds",
+		Help:      "Time spent handling a single message.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"outcome"})
+
+	NotificationsSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notifications_sent_total",
+		Help:      "Notifications delivered, by the rule that triggered them.",
+	}, []string{"trigger"})
+
+	NotificationsSuppressed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notifications_suppressed_total",
+		Help:      "Notifications not sent, by reason.",
+	}, []string{"reason"})
+
+	NotificationsDeferred = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notifications_deferred_total",
+		Help:      "Notifications held back until the recipient's quiet hours end.",
+	})
+
+	ScheduledAlertsProcessed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "scheduled_alerts_processed_total",
+		Help:      "Deferred alerts claimed for processing, by outcome.",
+	}, []string{"outcome"})
+
+	DigestItemsQueued = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "digest_items_queued_total",
+		Help:      "Alerts queued for a digest instead of sent, by frequency.",
+	}, []string{"frequency"})
+
+	DigestsProcessed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "digests_processed_total",
+		Help:      "Digests claimed for sending, by outcome.",
+	}, []string{"outcome"})
+
+	PriceConversions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "price_conversions_total",
+		Help:      "Lookups of the rate to show prices in the recipient's currency, by result.",
+	}, []string{"result"})
+
+	NotificationDeliveryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "notification_delivery_duration_seconds",
+		Help:      "Time spent delivering a notification, by channel and result.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"channel", "result"})
+
+	SMTPSendDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "smtp_send_duration_seconds",
+		Help:      "Time spent delivering an email to the SMTP server.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"result"})
+
+	SMTPSendErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "smtp_send_errors_total",
+		Help:      "Failed SMTP deliveries, by error class.",
+	}, []string{"class"})
+
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Latency of database lookups.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"query", "result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		MessagesConsumed,
+		MessagesAcked,
+		MessagesNacked,
+		MessagesRetried,
+		MessagesDeadLettered,
+		HandlerDuration,
+		NotificationsSent,
+		NotificationsSuppressed,
+		NotificationsDeferred,
+		ScheduledAlertsProcessed,
+		DigestItemsQueued,
+		DigestsProcessed,
+		PriceConversions,
+		NotificationDeliveryDuration,
+		SMTPSendDuration,
+		SMTPSendErrors,
+		DBQueryDuration,
+	)
+}
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Since returns the seconds elapsed since start, for Observe calls.
+func Since(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
+
+func Result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
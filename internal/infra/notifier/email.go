@@ -0,0 +1,27 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+)
+
+// Email delivers the rendered alert through an email sender, usually the
+// SMTP connection.
+type Email struct {
+	sender domain.TempEmailSender
+}
+
+func NewEmail(sender domain.TempEmailSender) *Email {
+	return &Email{sender: sender}
+}
+
+func (e *Email) Channel() domain.Channel {
+	return domain.ChannelEmail
+}
+
+func (e *Email) Notify(ctx context.Context, msg domain.Message) (string, error) {
+	email := *msg.Content
+	email.To = msg.Address
+	return e.sender.Send(ctx, &email)
+}
@@ -0,0 +1,33 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSender struct {
+	sent []*domain.AlertEmail
+}
+
+func (s *recordingSender) Send(ctx context.Context, email *domain.AlertEmail) (string, error) {
+	s.sent = append(s.sent, email)
+	return "250 2.0.0 ok queued as ABC123", nil
+}
+
+func TestEmail_Notify(t *testing.T) {
+	sender := &recordingSender{}
+	msg := testMessage("user@example.com")
+
+	response, err := NewEmail(sender).Notify(context.Background(), msg)
+
+	require.NoError(t, err)
+	assert.Equal(t, "250 2.0.0 ok queued as ABC123", response)
+	require.Len(t, sender.sent, 1)
+	assert.Equal(t, "user@example.com", sender.sent[0].To)
+	assert.Equal(t, msg.Content.Subject, sender.sent[0].Subject)
+	assert.Empty(t, msg.Content.To)
+}
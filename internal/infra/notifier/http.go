@@ -0,0 +1,82 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultTimeout = 10 * time.Second
+	maxResponse    = 4 << 10
+)
+
+var (
+	ErrInsecureURL      = errors.New("notifier url must use https")
+	ErrUnexpectedStatus = errors.New("notifier unexpected response status")
+)
+
+type Option func(*poster)
+
+// WithHTTPClient replaces the default client, which times out after
+// DefaultTimeout.
+func WithHTTPClient(client *http.Client) Option {
+	return func(p *poster) {
+		p.client = client
+	}
+}
+
+// poster sends JSON over HTTPS. Webhook URLs and bot tokens are secrets, so
+// errors never include the request URL.
+type poster struct {
+	client *http.Client
+}
+
+func newPoster(opts []Option) poster {
+	p := poster{client: &http.Client{Timeout: DefaultTimeout}}
+	for _, opt := range opts {
+		opt(&p)
+	}
+	return p
+}
+
+func (p poster) post(ctx context.Context, rawURL string, body []byte, header http.Header) (string, []byte, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil || target.Scheme != "https" || target.Host == "" {
+		return "", nil, ErrInsecureURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return "", nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return "", nil, fmt.Errorf("notifier request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponse))
+	if err != nil {
+		return "", nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", respBody, fmt.Errorf("%w: %s: %s", ErrUnexpectedStatus, resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	return resp.Status, respBody, nil
+}
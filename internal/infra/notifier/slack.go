@@ -0,0 +1,34 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+)
+
+// Slack posts the alert to the incoming webhook URL each user configured.
+type Slack struct {
+	poster
+}
+
+func NewSlack(opts ...Option) *Slack {
+	return &Slack{poster: newPoster(opts)}
+}
+
+func (s *Slack) Channel() domain.Channel {
+	return domain.ChannelSlack
+}
+
+func (s *Slack) Notify(ctx context.Context, msg domain.Message) (string, error) {
+	body, err := json.Marshal(map[string]any{
+		"text":         "*" + msg.Content.Subject + "*\n\n" + msg.Content.TextBody,
+		"unfurl_links": false,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	status, _, err := s.post(ctx, msg.Address, body, nil)
+	return status, err
+}
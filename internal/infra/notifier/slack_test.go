@@ -0,0 +1,37 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlack_Notify(t *testing.T) {
+	server, captured := newTestServer(t, http.StatusOK, "ok")
+	slack := NewSlack(WithHTTPClient(server.Client()))
+
+	response, err := slack.Notify(context.Background(), testMessage(server.URL+"/services/T000/B000/XXXX"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "200 OK", response)
+	assert.Equal(t, "/services/T000/B000/XXXX", captured.path)
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(captured.body, &body))
+	assert.Equal(t, "*Preço caiu: GRU → JFK*\n\nOlá!\n\nNovo preço: R$ 1.800,00", body["text"])
+	assert.Equal(t, false, body["unfurl_links"])
+}
+
+func TestSlack_Notify_InvalidWebhook(t *testing.T) {
+	server, _ := newTestServer(t, http.StatusNotFound, "no_service")
+	slack := NewSlack(WithHTTPClient(server.Client()))
+
+	_, err := slack.Notify(context.Background(), testMessage(server.URL))
+
+	require.ErrorIs(t, err, ErrUnexpectedStatus)
+	assert.Contains(t, err.Error(), "no_service")
+}
@@ -0,0 +1,76 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+)
+
+const (
+	DefaultTelegramAPIURL = "https://api.telegram.org"
+	telegramMaxText       = 4096
+)
+
+var ErrTelegramRejected = errors.New("telegram rejected message")
+
+// Telegram sends the alert through the Bot API to the chat ID each user
+// configured. Users must start a conversation with the bot first.
+type Telegram struct {
+	poster
+	apiURL string
+	token  string
+}
+
+func NewTelegram(apiURL, token string, opts ...Option) *Telegram {
+	if apiURL == "" {
+		apiURL = DefaultTelegramAPIURL
+	}
+	return &Telegram{poster: newPoster(opts), apiURL: strings.TrimRight(apiURL, "/"), token: token}
+}
+
+func (t *Telegram) Channel() domain.Channel {
+	return domain.ChannelTelegram
+}
+
+func (t *Telegram) Notify(ctx context.Context, msg domain.Message) (string, error) {
+	body, err := json.Marshal(map[string]any{
+		"chat_id":                  msg.Address,
+		"text":                     truncate(msg.Content.Subject+"\n\n"+msg.Content.TextBody, telegramMaxText),
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	status, respBody, err := t.post(ctx, t.apiURL+"/bot"+t.token+"/sendMessage", body, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+		Result      struct {
+			MessageID int64 `json:"message_id"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return "", fmt.Errorf("decoding telegram response: %w", err)
+	}
+	if !resp.OK {
+		return "", fmt.Errorf("%w: %s", ErrTelegramRejected, resp.Description)
+	}
+	return fmt.Sprintf("%s message_id=%d", status, resp.Result.MessageID), nil
+}
+
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
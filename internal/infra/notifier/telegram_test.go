@@ -0,0 +1,67 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelegram_Notify(t *testing.T) {
+	server, captured := newTestServer(t, http.StatusOK, `{"ok":true,"result":{"message_id":321}}`)
+	telegram := NewTelegram(server.URL+"/", "123:bot-token", WithHTTPClient(server.Client()))
+
+	response, err := telegram.Notify(context.Background(), testMessage("987654"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "200 OK message_id=321", response)
+	assert.Equal(t, "/bot123:bot-token/sendMessage", captured.path)
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(captured.body, &body))
+	assert.Equal(t, "987654", body["chat_id"])
+	assert.Equal(t, "Preço caiu: GRU → JFK\n\nOlá!\n\nNovo preço: R$ 1.800,00", body["text"])
+	assert.Equal(t, true, body["disable_web_page_preview"])
+}
+
+func TestTelegram_Notify_ChatNotFound(t *testing.T) {
+	server, _ := newTestServer(t, http.StatusBadRequest, `{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`)
+	telegram := NewTelegram(server.URL, "123:bot-token", WithHTTPClient(server.Client()))
+
+	_, err := telegram.Notify(context.Background(), testMessage("987654"))
+
+	require.ErrorIs(t, err, ErrUnexpectedStatus)
+	assert.Contains(t, err.Error(), "chat not found")
+	assert.NotContains(t, err.Error(), "bot-token")
+}
+
+func TestTelegram_Notify_NotOK(t *testing.T) {
+	server, _ := newTestServer(t, http.StatusOK, `{"ok":false,"description":"Forbidden: bot was blocked by the user"}`)
+	telegram := NewTelegram(server.URL, "123:bot-token", WithHTTPClient(server.Client()))
+
+	_, err := telegram.Notify(context.Background(), testMessage("987654"))
+
+	assert.ErrorIs(t, err, ErrTelegramRejected)
+}
+
+func TestTelegram_Notify_TruncatesLongText(t *testing.T) {
+	server, captured := newTestServer(t, http.StatusOK, `{"ok":true,"result":{"message_id":1}}`)
+	telegram := NewTelegram(server.URL, "123:bot-token", WithHTTPClient(server.Client()))
+	msg := testMessage("987654")
+	msg.Content.TextBody = strings.Repeat("é", 5000)
+
+	_, err := telegram.Notify(context.Background(), msg)
+
+	require.NoError(t, err)
+	var body struct {
+		Text string `json:"text"`
+	}
+	require.NoError(t, json.Unmarshal(captured.body, &body))
+	assert.Equal(t, telegramMaxText, utf8.RuneCountInString(body.Text))
+	assert.True(t, strings.HasSuffix(body.Text, "…"))
+}
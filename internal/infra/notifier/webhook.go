@@ -0,0 +1,131 @@
+package notifier
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+)
+
+const (
+	SignatureHeader = "X-Alert-Signature"
+	TimestampHeader = "X-Alert-Timestamp"
+	MessageIDHeader = "X-Alert-Message-Id"
+	MinSecretBytes  = 32
+)
+
+var ErrWeakSecret = errors.New("webhook signing secret must be at least 32 bytes")
+
+const dateLayout = "2006-01-02"
+
+// WebhookPayload is the JSON body posted to user webhooks.
+type WebhookPayload struct {
+	Event        string        `json:"event"`
+	AlertID      int64         `json:"alertId"`
+	MessageID    string        `json:"messageId"`
+	Trigger      string        `json:"trigger"`
+	Origin       string        `json:"origin"`
+	Destination  string        `json:"destination"`
+	OutboundDate string        `json:"outboundDate"`
+	ReturnDate   string        `json:"returnDate,omitempty"`
+	OldPrice     float64       `json:"oldPrice"`
+	NewPrice     float64       `json:"newPrice"`
+	TargetPrice  float64       `json:"targetPrice"`
+	Currency     string        `json:"currency"`
+	DropPercent  float64       `json:"dropPercent"`
+	Link         string        `json:"link"`
+	Links        []WebhookLink `json:"links"`
+	Subject      string        `json:"subject"`
+	Text         string        `json:"text"`
+	SentAt       time.Time     `json:"sentAt"`
+}
+
+type WebhookLink struct {
+	Provider string `json:"provider"`
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+}
+
+// Webhook posts the alert as JSON to the URL each user configured. Every
+// request carries an HMAC-SHA256 of "<timestamp>.<body>" in SignatureHeader,
+// so receivers can check it came from us and reject replays.
+type Webhook struct {
+	poster
+	secret []byte
+	now    func() time.Time
+}
+
+func NewWebhook(secret []byte, opts ...Option) (*Webhook, error) {
+	if len(secret) < MinSecretBytes {
+		return nil, ErrWeakSecret
+	}
+	return &Webhook{poster: newPoster(opts), secret: secret, now: time.Now}, nil
+}
+
+func (w *Webhook) Channel() domain.Channel {
+	return domain.ChannelWebhook
+}
+
+func (w *Webhook) Notify(ctx context.Context, msg domain.Message) (string, error) {
+	sentAt := w.now().UTC()
+	body, err := json.Marshal(newWebhookPayload(msg, sentAt))
+	if err != nil {
+		return "", err
+	}
+
+	timestamp := strconv.FormatInt(sentAt.Unix(), 10)
+	header := http.Header{}
+	header.Set(TimestampHeader, timestamp)
+	header.Set(SignatureHeader, Sign(w.secret, timestamp, body))
+	header.Set(MessageIDHeader, msg.Alert.MessageID)
+
+	status, _, err := w.post(ctx, msg.Address, body, header)
+	return status, err
+}
+
+// Sign returns the SignatureHeader value for a webhook body.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(timestamp))
+	h.Write([]byte("."))
+	h.Write(body)
+	return "sha256=" + hex.EncodeToString(h.Sum(nil))
+}
+
+func newWebhookPayload(msg domain.Message, sentAt time.Time) WebhookPayload {
+	alert := msg.Alert
+	payload := WebhookPayload{
+		Event:        "price_alert",
+		AlertID:      alert.ID,
+		MessageID:    alert.MessageID,
+		Trigger:      string(msg.Trigger),
+		Origin:       alert.Origin,
+		Destination:  alert.Destination,
+		OutboundDate: alert.OutboundDate.Format(dateLayout),
+		OldPrice:     alert.OldPrice,
+		NewPrice:     alert.NewPrice,
+		TargetPrice:  alert.TargetPrice,
+		Currency:     alert.Currency,
+		DropPercent:  math.Round(alert.DropPercent()*100) / 100,
+		Link:         alert.Link,
+		Links:        make([]WebhookLink, 0, len(alert.Links)),
+		Subject:      msg.Content.Subject,
+		Text:         msg.Content.TextBody,
+		SentAt:       sentAt,
+	}
+	if !alert.ReturnDate.IsZero() {
+		payload.ReturnDate = alert.ReturnDate.Format(dateLayout)
+	}
+	for _, link := range alert.Links {
+		payload.Links = append(payload.Links, WebhookLink{Provider: link.Provider, Name: link.Name, URL: link.URL})
+	}
+	return payload
+}
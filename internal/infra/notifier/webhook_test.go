@@ -0,0 +1,149 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func testMessage(address string) domain.Message {
+	return domain.Message{
+		Alert: &domain.Alert{
+			ID:           42,
+			MessageID:    "abc-123",
+			Origin:       "GRU",
+			Destination:  "JFK",
+			OutboundDate: time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC),
+			OldPrice:     2500,
+			NewPrice:     1800,
+			TargetPrice:  2000,
+			Currency:     "BRL",
+			Link:         "https://alerts.example.com/r/token1",
+			Links:        []domain.BookingLink{{Provider: "google_flights", Name: "Google Flights", URL: "https://alerts.example.com/r/token1"}},
+		},
+		Recipient: domain.NewRecipient(42, "user@example.com"),
+		Trigger:   domain.TriggerTargetReached,
+		Content:   &domain.AlertEmail{Subject: "Preço caiu: GRU → JFK", TextBody: "Olá!\n\nNovo preço: R$ 1.800,00"},
+		Address:   address,
+	}
+}
+
+type capturedRequest struct {
+	header http.Header
+	path   string
+	body   []byte
+}
+
+func newTestServer(t *testing.T, status int, response string) (*httptest.Server, *capturedRequest) {
+	t.Helper()
+	captured := &capturedRequest{}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		captured.header = r.Header.Clone()
+		captured.path = r.URL.Path
+		captured.body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, response)
+	}))
+	t.Cleanup(server.Close)
+	return server, captured
+}
+
+func TestNewWebhook_WeakSecret(t *testing.T) {
+	_, err := NewWebhook([]byte("short"))
+
+	assert.ErrorIs(t, err, ErrWeakSecret)
+}
+
+func TestWebhook_Notify(t *testing.T) {
+	server, captured := newTestServer(t, http.StatusOK, "")
+	webhook, err := NewWebhook(testSecret, WithHTTPClient(server.Client()))
+	require.NoError(t, err)
+	webhook.now = func() time.Time { return time.Date(2025, 12, 2, 10, 0, 0, 0, time.UTC) }
+
+	response, err := webhook.Notify(context.Background(), testMessage(server.URL+"/alerts"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "200 OK", response)
+	assert.Equal(t, "/alerts", captured.path)
+	assert.Equal(t, "application/json", captured.header.Get("Content-Type"))
+	assert.Equal(t, "1764669600", captured.header.Get(TimestampHeader))
+	assert.Equal(t, "abc-123", captured.header.Get(MessageIDHeader))
+	assert.Equal(t, Sign(testSecret, "1764669600", captured.body), captured.header.Get(SignatureHeader))
+	assert.True(t, strings.HasPrefix(captured.header.Get(SignatureHeader), "sha256="))
+
+	var payload WebhookPayload
+	require.NoError(t, json.Unmarshal(captured.body, &payload))
+	assert.Equal(t, WebhookPayload{
+		Event:        "price_alert",
+		AlertID:      42,
+		MessageID:    "abc-123",
+		Trigger:      "target_reached",
+		Origin:       "GRU",
+		Destination:  "JFK",
+		OutboundDate: "2025-12-15",
+		OldPrice:     2500,
+		NewPrice:     1800,
+		TargetPrice:  2000,
+		Currency:     "BRL",
+		DropPercent:  28,
+		Link:         "https://alerts.example.com/r/token1",
+		Links:        []WebhookLink{{Provider: "google_flights", Name: "Google Flights", URL: "https://alerts.example.com/r/token1"}},
+		Subject:      "Preço caiu: GRU → JFK",
+		Text:         "Olá!\n\nNovo preço: R$ 1.800,00",
+		SentAt:       time.Date(2025, 12, 2, 10, 0, 0, 0, time.UTC),
+	}, payload)
+}
+
+func TestSign(t *testing.T) {
+	signature := Sign(testSecret, "1764669600", []byte(`{"event":"price_alert"}`))
+
+	assert.Equal(t, signature, Sign(testSecret, "1764669600", []byte(`{"event":"price_alert"}`)))
+	assert.NotEqual(t, signature, Sign(testSecret, "1764669601", []byte(`{"event":"price_alert"}`)))
+	assert.NotEqual(t, signature, Sign([]byte("another-secret-another-secret-xx"), "1764669600", []byte(`{"event":"price_alert"}`)))
+}
+
+func TestWebhook_Notify_RejectsInsecureURL(t *testing.T) {
+	webhook, err := NewWebhook(testSecret)
+	require.NoError(t, err)
+
+	for _, address := range []string{"http://hooks.example.com/alerts", "", "hooks.example.com/alerts"} {
+		_, err := webhook.Notify(context.Background(), testMessage(address))
+
+		assert.ErrorIs(t, err, ErrInsecureURL, address)
+	}
+}
+
+func TestWebhook_Notify_ErrorStatus(t *testing.T) {
+	server, _ := newTestServer(t, http.StatusGone, "hook removed")
+	webhook, err := NewWebhook(testSecret, WithHTTPClient(server.Client()))
+	require.NoError(t, err)
+
+	_, err = webhook.Notify(context.Background(), testMessage(server.URL))
+
+	require.ErrorIs(t, err, ErrUnexpectedStatus)
+	assert.Contains(t, err.Error(), "410 Gone: hook removed")
+}
+
+func TestWebhook_Notify_ErrorHidesURL(t *testing.T) {
+	server, _ := newTestServer(t, http.StatusOK, "")
+	address := server.URL + "/secret-token"
+	server.Close()
+	webhook, err := NewWebhook(testSecret, WithHTTPClient(server.Client()))
+	require.NoError(t, err)
+
+	_, err = webhook.Notify(context.Background(), testMessage(address))
+
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret-token")
+}
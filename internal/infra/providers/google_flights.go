@@ -2,13 +2,52 @@ package providers
 
 import (
 	"fmt"
-	"time"
+	"strings"
+
+	"github.com/Luzin7/alert-service/internal/domain"
 )
 
+const DefaultGoogleFlightsURL = "https://www.google.com/travel/flights"
+
+var googleFlightsCabins = map[domain.CabinClass]string{
+	domain.CabinPremiumEconomy: "premium economy",
+	domain.CabinBusiness:       "business class",
+	domain.CabinFirst:          "first class",
+}
+
 type GoogleFlightsGenerator struct {
 	BaseURL string
 }
 
-func (g GoogleFlightsGenerator) Generate(origin, dest string, out, ret time.Time) string {
-	return fmt.Sprintf("https://www.google.com/travel/flights?q=Flights%%20to%%20%s%%20from%%20%s...", dest, origin)
+func (g GoogleFlightsGenerator) Generate(req domain.LinkRequest) string {
+	base := parseBaseURL(g.BaseURL, DefaultGoogleFlightsURL)
+
+	query := base.Query()
+	query.Set("q", googleFlightsQuery(req))
+	base.RawQuery = query.Encode()
+
+	return base.String()
+}
+
+// googleFlightsQuery builds the natural-language search Google Flights
+// resolves into a concrete itinerary, e.g.
+// "Flights to JFK from GRU on 2025-12-15 through 2025-12-20 business class".
+func googleFlightsQuery(req domain.LinkRequest) string {
+	parts := []string{fmt.Sprintf("Flights to %s from %s on %s", req.Destination, req.Origin, req.OutboundDate.Format("2006-01-02"))}
+
+	if req.OneWay() {
+		parts = append(parts, "one way")
+	} else {
+		parts = append(parts, "through "+req.ReturnDate.Format("2006-01-02"))
+	}
+
+	if cabin, ok := googleFlightsCabins[req.Cabin]; ok {
+		parts = append(parts, cabin)
+	}
+
+	if req.Passengers > 1 {
+		parts = append(parts, fmt.Sprintf("%d passengers", req.Passengers))
+	}
+
+	return strings.Join(parts, " ")
 }
@@ -1,28 +1,38 @@
 package providers
 
 import (
+	"net/url"
 	"testing"
 	"time"
 
+	"github.com/Luzin7/alert-service/internal/domain"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+func newTestLinkRequest(origin, destination string) domain.LinkRequest {
+	return domain.LinkRequest{
+		Origin:       origin,
+		Destination:  destination,
+		OutboundDate: time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC),
+		ReturnDate:   time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC),
+		Cabin:        domain.CabinEconomy,
+		Passengers:   1,
+	}
+}
+
 func TestGoogleFlightsGenerator_Generate(t *testing.T) {
 	generator := GoogleFlightsGenerator{
 		BaseURL: "https://www.google.com/travel/flights",
 	}
 
-	origin := "GRU"
-	destination := "JFK"
-	outbound := time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC)
-	returnDate := time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC)
-
-	link := generator.Generate(origin, destination, outbound, returnDate)
+	link := generator.Generate(newTestLinkRequest("GRU", "JFK"))
 
 	assert.NotEmpty(t, link)
 	assert.Contains(t, link, "https://www.google.com/travel/flights")
-	assert.Contains(t, link, destination)
-	assert.Contains(t, link, origin)
+	assert.Contains(t, link, "JFK")
+	assert.Contains(t, link, "GRU")
+	assert.NotContains(t, link, "...")
 }
 
 func TestGoogleFlightsGenerator_Generate_DifferentCities(t *testing.T) {
@@ -54,10 +64,7 @@ func TestGoogleFlightsGenerator_Generate_DifferentCities(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			outbound := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
-			returnDate := time.Date(2025, 10, 10, 0, 0, 0, 0, time.UTC)
-
-			link := generator.Generate(tc.origin, tc.destination, outbound, returnDate)
+			link := generator.Generate(newTestLinkRequest(tc.origin, tc.destination))
 
 			assert.NotEmpty(t, link)
 			assert.Contains(t, link, tc.destination)
@@ -71,12 +78,7 @@ func TestGoogleFlightsGenerator_Generate_URLFormat(t *testing.T) {
 		BaseURL: "https://www.google.com/travel/flights",
 	}
 
-	origin := "GRU"
-	destination := "JFK"
-	outbound := time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC)
-	returnDate := time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC)
-
-	link := generator.Generate(origin, destination, outbound, returnDate)
+	link := generator.Generate(newTestLinkRequest("GRU", "JFK"))
 
 	assert.True(t, len(link) > 0, "Link should not be empty")
 	assert.Contains(t, link, "https://", "Link should use HTTPS protocol")
@@ -87,14 +89,102 @@ func TestGoogleFlightsGenerator_Generate_EmptyBaseURL(t *testing.T) {
 		BaseURL: "",
 	}
 
-	origin := "GRU"
-	destination := "JFK"
-	outbound := time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC)
-	returnDate := time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC)
-
-	link := generator.Generate(origin, destination, outbound, returnDate)
+	link := generator.Generate(newTestLinkRequest("GRU", "JFK"))
 
 	assert.NotEmpty(t, link)
-	assert.Contains(t, link, origin)
-	assert.Contains(t, link, destination)
+	assert.Contains(t, link, DefaultGoogleFlightsURL)
+	assert.Contains(t, link, "GRU")
+	assert.Contains(t, link, "JFK")
+}
+
+func TestGoogleFlightsGenerator_Generate_Query(t *testing.T) {
+	testCases := []struct {
+		name     string
+		baseURL  string
+		modify   func(req *domain.LinkRequest)
+		wantHost string
+		wantPath string
+		wantQ    string
+		wantHL   string
+	}{
+		{
+			name:     "round trip",
+			baseURL:  "https://www.google.com/travel/flights",
+			wantHost: "www.google.com",
+			wantPath: "/travel/flights",
+			wantQ:    "Flights to JFK from GRU on 2025-12-15 through 2025-12-20",
+		},
+		{
+			name:     "one way",
+			baseURL:  "https://www.google.com/travel/flights",
+			modify:   func(req *domain.LinkRequest) { req.ReturnDate = time.Time{} },
+			wantHost: "www.google.com",
+			wantPath: "/travel/flights",
+			wantQ:    "Flights to JFK from GRU on 2025-12-15 one way",
+		},
+		{
+			name:    "business class with passengers",
+			baseURL: "https://www.google.com/travel/flights",
+			modify: func(req *domain.LinkRequest) {
+				req.Cabin = domain.CabinBusiness
+				req.Passengers = 3
+			},
+			wantHost: "www.google.com",
+			wantPath: "/travel/flights",
+			wantQ:    "Flights to JFK from GRU on 2025-12-15 through 2025-12-20 business class 3 passengers",
+		},
+		{
+			name:     "premium economy",
+			baseURL:  "https://www.google.com/travel/flights",
+			modify:   func(req *domain.LinkRequest) { req.Cabin = domain.CabinPremiumEconomy },
+			wantHost: "www.google.com",
+			wantPath: "/travel/flights",
+			wantQ:    "Flights to JFK from GRU on 2025-12-15 through 2025-12-20 premium economy",
+		},
+		{
+			name:     "base URL query is preserved",
+			baseURL:  "https://flights.example.com/search?hl=pt-BR",
+			wantHost: "flights.example.com",
+			wantPath: "/search",
+			wantQ:    "Flights to JFK from GRU on 2025-12-15 through 2025-12-20",
+			wantHL:   "pt-BR",
+		},
+		{
+			name:     "invalid base URL falls back to default",
+			baseURL:  "://bad url",
+			wantHost: "www.google.com",
+			wantPath: "/travel/flights",
+			wantQ:    "Flights to JFK from GRU on 2025-12-15 through 2025-12-20",
+		},
+		{
+			name:     "special characters are escaped",
+			baseURL:  "https://www.google.com/travel/flights",
+			modify:   func(req *domain.LinkRequest) { req.Destination = "São Paulo&x=1" },
+			wantHost: "www.google.com",
+			wantPath: "/travel/flights",
+			wantQ:    "Flights to São Paulo&x=1 from GRU on 2025-12-15 through 2025-12-20",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := newTestLinkRequest("GRU", "JFK")
+			if tc.modify != nil {
+				tc.modify(&req)
+			}
+
+			link := GoogleFlightsGenerator{BaseURL: tc.baseURL}.Generate(req)
+
+			parsed, err := url.Parse(link)
+			require.NoError(t, err)
+			assert.Equal(t, "https", parsed.Scheme)
+			assert.Equal(t, tc.wantHost, parsed.Host)
+			assert.Equal(t, tc.wantPath, parsed.Path)
+
+			query := parsed.Query()
+			assert.Equal(t, tc.wantQ, query.Get("q"))
+			assert.Equal(t, tc.wantHL, query.Get("hl"))
+			assert.NotContains(t, query, "x")
+		})
+	}
 }
@@ -0,0 +1,63 @@
+package providers
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+)
+
+const (
+	DefaultKayakURL   = "https://www.kayak.com/flights"
+	DefaultMomondoURL = "https://www.momondo.com/flight-search"
+)
+
+var kayakCabins = map[domain.CabinClass]string{
+	domain.CabinPremiumEconomy: "premium",
+	domain.CabinBusiness:       "business",
+	domain.CabinFirst:          "first",
+}
+
+// KayakGenerator builds Kayak search links. Momondo belongs to the same group
+// and accepts the same path layout, so it reuses this generator with another
+// BaseURL.
+type KayakGenerator struct {
+	BaseURL string
+}
+
+func (g KayakGenerator) Generate(req domain.LinkRequest) string {
+	base := parseBaseURL(g.BaseURL, DefaultKayakURL)
+
+	segments := []string{
+		url.PathEscape(req.Origin) + "-" + url.PathEscape(req.Destination),
+		req.OutboundDate.Format("2006-01-02"),
+	}
+	if !req.OneWay() {
+		segments = append(segments, req.ReturnDate.Format("2006-01-02"))
+	}
+	if cabin, ok := kayakCabins[req.Cabin]; ok {
+		segments = append(segments, cabin)
+	}
+	if req.Passengers > 1 {
+		segments = append(segments, strconv.Itoa(req.Passengers)+"adults")
+	}
+
+	return joinPath(base, segments...)
+}
+
+func joinPath(base *url.URL, segments ...string) string {
+	escaped := strings.TrimSuffix(base.EscapedPath(), "/") + "/" + strings.Join(segments, "/")
+	path, _ := url.PathUnescape(escaped)
+	base.Path = path
+	base.RawPath = escaped
+	return base.String()
+}
+
+func parseBaseURL(raw, fallback string) *url.URL {
+	base, err := url.Parse(raw)
+	if raw == "" || err != nil {
+		base, _ = url.Parse(fallback)
+	}
+	return base
+}
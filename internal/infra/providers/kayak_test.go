@@ -0,0 +1,71 @@
+package providers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKayakGenerator_Generate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		baseURL  string
+		modify   func(req *domain.LinkRequest)
+		expected string
+	}{
+		{
+			name:     "round trip",
+			baseURL:  DefaultKayakURL,
+			expected: "https://www.kayak.com/flights/GRU-JFK/2025-12-15/2025-12-20",
+		},
+		{
+			name:     "one way",
+			baseURL:  DefaultKayakURL,
+			modify:   func(req *domain.LinkRequest) { req.ReturnDate = time.Time{} },
+			expected: "https://www.kayak.com/flights/GRU-JFK/2025-12-15",
+		},
+		{
+			name:    "cabin and passengers",
+			baseURL: DefaultKayakURL,
+			modify: func(req *domain.LinkRequest) {
+				req.Cabin = domain.CabinPremiumEconomy
+				req.Passengers = 2
+			},
+			expected: "https://www.kayak.com/flights/GRU-JFK/2025-12-15/2025-12-20/premium/2adults",
+		},
+		{
+			name:     "momondo base URL",
+			baseURL:  DefaultMomondoURL,
+			modify:   func(req *domain.LinkRequest) { req.Cabin = domain.CabinBusiness },
+			expected: "https://www.momondo.com/flight-search/GRU-JFK/2025-12-15/2025-12-20/business",
+		},
+		{
+			name:     "empty base URL",
+			expected: "https://www.kayak.com/flights/GRU-JFK/2025-12-15/2025-12-20",
+		},
+		{
+			name:     "base URL with trailing slash and query",
+			baseURL:  "https://www.kayak.com.br/flights/?sort=price_a",
+			expected: "https://www.kayak.com.br/flights/GRU-JFK/2025-12-15/2025-12-20?sort=price_a",
+		},
+		{
+			name:     "path segments are escaped",
+			baseURL:  DefaultKayakURL,
+			modify:   func(req *domain.LinkRequest) { req.Destination = "NYC/JFK?x" },
+			expected: "https://www.kayak.com/flights/GRU-NYC%2FJFK%3Fx/2025-12-15/2025-12-20",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := newTestLinkRequest("GRU", "JFK")
+			if tc.modify != nil {
+				tc.modify(&req)
+			}
+
+			assert.Equal(t, tc.expected, KayakGenerator{BaseURL: tc.baseURL}.Generate(req))
+		})
+	}
+}
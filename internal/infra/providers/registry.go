@@ -0,0 +1,85 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+)
+
+const (
+	GoogleFlights = "google_flights"
+	Kayak         = "kayak"
+	Skyscanner    = "skyscanner"
+	Momondo       = "momondo"
+)
+
+type Provider struct {
+	ID        string
+	Name      string
+	Generator domain.LinkGenerator
+}
+
+func DefaultProviders() []Provider {
+	return []Provider{
+		{ID: GoogleFlights, Name: "Google Flights", Generator: GoogleFlightsGenerator{BaseURL: DefaultGoogleFlightsURL}},
+		{ID: Kayak, Name: "Kayak", Generator: KayakGenerator{BaseURL: DefaultKayakURL}},
+		{ID: Skyscanner, Name: "Skyscanner", Generator: SkyscannerGenerator{BaseURL: DefaultSkyscannerURL}},
+		{ID: Momondo, Name: "Momondo", Generator: KayakGenerator{BaseURL: DefaultMomondoURL}},
+	}
+}
+
+// Registry generates one booking link per selected provider. Links follow
+// the order of the selection, so emails list sites consistently.
+type Registry struct {
+	providers map[string]Provider
+	defaults  []string
+}
+
+func NewRegistry(defaults []string, providers ...Provider) (*Registry, error) {
+	r := &Registry{providers: make(map[string]Provider, len(providers))}
+	for _, p := range providers {
+		r.providers[p.ID] = p
+	}
+
+	if len(defaults) == 0 {
+		return nil, errors.New("at least one default link provider is required")
+	}
+	for _, id := range defaults {
+		if _, ok := r.providers[id]; !ok {
+			return nil, fmt.Errorf("unknown link provider %q", id)
+		}
+	}
+	r.defaults = defaults
+
+	return r, nil
+}
+
+// Links generates the links for req.Providers, ignoring unknown or repeated
+// IDs, and falls back to the defaults when nothing valid was selected.
+func (r *Registry) Links(req domain.LinkRequest) []domain.BookingLink {
+	links := r.generate(req, req.Providers)
+	if len(links) == 0 {
+		links = r.generate(req, r.defaults)
+	}
+	return links
+}
+
+func (r *Registry) generate(req domain.LinkRequest, ids []string) []domain.BookingLink {
+	var links []domain.BookingLink
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		provider, ok := r.providers[id]
+		if !ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		links = append(links, domain.BookingLink{
+			Provider: provider.ID,
+			Name:     provider.Name,
+			URL:      provider.Generator.Generate(req),
+		})
+	}
+	return links
+}
@@ -0,0 +1,86 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fixedGenerator string
+
+func (g fixedGenerator) Generate(req domain.LinkRequest) string {
+	return string(g)
+}
+
+func newTestRegistry(t *testing.T, defaults ...string) *Registry {
+	t.Helper()
+
+	registry, err := NewRegistry(defaults,
+		Provider{ID: "a", Name: "A", Generator: fixedGenerator("https://a.example.com")},
+		Provider{ID: "b", Name: "B", Generator: fixedGenerator("https://b.example.com")},
+		Provider{ID: "c", Name: "C", Generator: fixedGenerator("https://c.example.com")},
+	)
+	require.NoError(t, err)
+	return registry
+}
+
+func providerIDs(links []domain.BookingLink) []string {
+	ids := make([]string, 0, len(links))
+	for _, link := range links {
+		ids = append(ids, link.Provider)
+	}
+	return ids
+}
+
+func TestRegistry_Links_Defaults(t *testing.T) {
+	registry := newTestRegistry(t, "c", "a")
+
+	links := registry.Links(newTestLinkRequest("GRU", "JFK"))
+
+	assert.Equal(t, []domain.BookingLink{
+		{Provider: "c", Name: "C", URL: "https://c.example.com"},
+		{Provider: "a", Name: "A", URL: "https://a.example.com"},
+	}, links)
+}
+
+func TestRegistry_Links_PerRequestSelection(t *testing.T) {
+	registry := newTestRegistry(t, "a")
+
+	req := newTestLinkRequest("GRU", "JFK")
+	req.Providers = []string{"b", "unknown", "c", "b"}
+
+	assert.Equal(t, []string{"b", "c"}, providerIDs(registry.Links(req)))
+}
+
+func TestRegistry_Links_OnlyUnknownFallsBackToDefaults(t *testing.T) {
+	registry := newTestRegistry(t, "a", "b")
+
+	req := newTestLinkRequest("GRU", "JFK")
+	req.Providers = []string{"removed"}
+
+	assert.Equal(t, []string{"a", "b"}, providerIDs(registry.Links(req)))
+}
+
+func TestNewRegistry_Validation(t *testing.T) {
+	_, err := NewRegistry(nil, DefaultProviders()...)
+	assert.Error(t, err)
+
+	_, err = NewRegistry([]string{"expedia"}, DefaultProviders()...)
+	assert.EqualError(t, err, `unknown link provider "expedia"`)
+}
+
+func TestDefaultProviders(t *testing.T) {
+	registry, err := NewRegistry([]string{GoogleFlights, Kayak, Skyscanner, Momondo}, DefaultProviders()...)
+	require.NoError(t, err)
+
+	links := registry.Links(newTestLinkRequest("GRU", "JFK"))
+
+	require.Len(t, links, 4)
+	assert.Equal(t, []string{GoogleFlights, Kayak, Skyscanner, Momondo}, providerIDs(links))
+	assert.Contains(t, links[0].URL, "https://www.google.com/travel/flights?")
+	assert.Contains(t, links[1].URL, "https://www.kayak.com/flights/GRU-JFK")
+	assert.Contains(t, links[2].URL, "https://www.skyscanner.com/transport/flights/gru/jfk")
+	assert.Contains(t, links[3].URL, "https://www.momondo.com/flight-search/GRU-JFK")
+}
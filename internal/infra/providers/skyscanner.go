@@ -0,0 +1,50 @@
+package providers
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+)
+
+const DefaultSkyscannerURL = "https://www.skyscanner.com/transport/flights"
+
+var skyscannerCabins = map[domain.CabinClass]string{
+	domain.CabinEconomy:        "economy",
+	domain.CabinPremiumEconomy: "premiumeconomy",
+	domain.CabinBusiness:       "business",
+	domain.CabinFirst:          "first",
+}
+
+type SkyscannerGenerator struct {
+	BaseURL string
+}
+
+func (g SkyscannerGenerator) Generate(req domain.LinkRequest) string {
+	base := parseBaseURL(g.BaseURL, DefaultSkyscannerURL)
+
+	query := base.Query()
+	passengers := max(req.Passengers, 1)
+	query.Set("adultsv2", strconv.Itoa(passengers))
+	if cabin, ok := skyscannerCabins[req.Cabin]; ok {
+		query.Set("cabinclass", cabin)
+	}
+	if req.OneWay() {
+		query.Set("rtn", "0")
+	} else {
+		query.Set("rtn", "1")
+	}
+	base.RawQuery = query.Encode()
+
+	segments := []string{
+		url.PathEscape(strings.ToLower(req.Origin)),
+		url.PathEscape(strings.ToLower(req.Destination)),
+		req.OutboundDate.Format("060102"),
+	}
+	if !req.OneWay() {
+		segments = append(segments, req.ReturnDate.Format("060102"))
+	}
+
+	return joinPath(base, append(segments, "")...)
+}
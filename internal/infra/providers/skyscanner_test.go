@@ -0,0 +1,64 @@
+package providers
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkyscannerGenerator_Generate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		modify    func(req *domain.LinkRequest)
+		wantPath  string
+		wantQuery url.Values
+	}{
+		{
+			name:      "round trip",
+			wantPath:  "/transport/flights/gru/jfk/251215/251220/",
+			wantQuery: url.Values{"adultsv2": {"1"}, "cabinclass": {"economy"}, "rtn": {"1"}},
+		},
+		{
+			name:      "one way",
+			modify:    func(req *domain.LinkRequest) { req.ReturnDate = time.Time{} },
+			wantPath:  "/transport/flights/gru/jfk/251215/",
+			wantQuery: url.Values{"adultsv2": {"1"}, "cabinclass": {"economy"}, "rtn": {"0"}},
+		},
+		{
+			name: "first class for three",
+			modify: func(req *domain.LinkRequest) {
+				req.Cabin = domain.CabinFirst
+				req.Passengers = 3
+			},
+			wantPath:  "/transport/flights/gru/jfk/251215/251220/",
+			wantQuery: url.Values{"adultsv2": {"3"}, "cabinclass": {"first"}, "rtn": {"1"}},
+		},
+		{
+			name:      "missing passengers defaults to one adult",
+			modify:    func(req *domain.LinkRequest) { req.Passengers = 0 },
+			wantPath:  "/transport/flights/gru/jfk/251215/251220/",
+			wantQuery: url.Values{"adultsv2": {"1"}, "cabinclass": {"economy"}, "rtn": {"1"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := newTestLinkRequest("GRU", "JFK")
+			if tc.modify != nil {
+				tc.modify(&req)
+			}
+
+			link := SkyscannerGenerator{BaseURL: DefaultSkyscannerURL}.Generate(req)
+
+			parsed, err := url.Parse(link)
+			require.NoError(t, err)
+			assert.Equal(t, "www.skyscanner.com", parsed.Host)
+			assert.Equal(t, tc.wantPath, parsed.Path)
+			assert.Equal(t, tc.wantQuery, parsed.Query())
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+)
+
+const (
+	AlertIDParam   = "alert_id"
+	MessageIDParam = "message_id"
+)
+
+// TrackingConfig holds the UTM and affiliate parameters appended to booking
+// links. Provider entries are merged over Default, key by key.
+type TrackingConfig struct {
+	Default   map[string]string            `json:"default"`
+	Providers map[string]map[string]string `json:"providers"`
+}
+
+func LoadTrackingConfig(path string) (TrackingConfig, error) {
+	var config TrackingConfig
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return config, fmt.Errorf("tracking config: %w", err)
+	}
+	if err := json.Unmarshal(content, &config); err != nil {
+		return config, fmt.Errorf("tracking config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+func (c TrackingConfig) params(provider string) map[string]string {
+	params := make(map[string]string, len(c.Default)+len(c.Providers[provider]))
+	for k, v := range c.Default {
+		params[k] = v
+	}
+	for k, v := range c.Providers[provider] {
+		params[k] = v
+	}
+	return params
+}
+
+// WithTracking wraps every provider generator in a TrackingGenerator using
+// the parameters configured for that provider.
+func WithTracking(providers []Provider, config TrackingConfig) []Provider {
+	tracked := make([]Provider, 0, len(providers))
+	for _, p := range providers {
+		p.Generator = NewTrackingGenerator(p.Generator, config.params(p.ID))
+		tracked = append(tracked, p)
+	}
+	return tracked
+}
+
+// TrackingGenerator decorates a LinkGenerator with attribution parameters
+// plus the alert and message IDs, keeping the rest of the generated URL.
+// Keys the provider already set are left alone: overwriting q or adultsv2
+// would break the deep link itself.
+type TrackingGenerator struct {
+	next   domain.LinkGenerator
+	params map[string]string
+}
+
+func NewTrackingGenerator(next domain.LinkGenerator, params map[string]string) *TrackingGenerator {
+	return &TrackingGenerator{
+		next:   next,
+		params: params,
+	}
+}
+
+func (g *TrackingGenerator) Generate(req domain.LinkRequest) string {
+	link := g.next.Generate(req)
+
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+
+	query := parsed.Query()
+	add := func(key, value string) {
+		if !query.Has(key) {
+			query.Set(key, value)
+		}
+	}
+	for k, v := range g.params {
+		add(k, v)
+	}
+	if req.AlertID != 0 {
+		add(AlertIDParam, strconv.FormatInt(req.AlertID, 10))
+	}
+	if req.MessageID != "" {
+		add(MessageIDParam, req.MessageID)
+	}
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String()
+}
@@ -0,0 +1,136 @@
+package providers
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackingGenerator_Generate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		link      string
+		params    map[string]string
+		alertID   int64
+		messageID string
+		wantPath  string
+		wantFrag  string
+		wantQuery url.Values
+	}{
+		{
+			name:      "preserves existing query",
+			link:      "https://www.google.com/travel/flights?q=Flights+to+JFK&hl=pt-BR",
+			params:    map[string]string{"utm_source": "alert-service", "utm_medium": "email"},
+			alertID:   42,
+			messageID: "msg-123",
+			wantPath:  "/travel/flights",
+			wantQuery: url.Values{
+				"q":          {"Flights to JFK"},
+				"hl":         {"pt-BR"},
+				"utm_source": {"alert-service"},
+				"utm_medium": {"email"},
+				"alert_id":   {"42"},
+				"message_id": {"msg-123"},
+			},
+		},
+		{
+			name:      "path only link with fragment",
+			link:      "https://www.kayak.com/flights/GRU-JFK/2025-12-15#results",
+			params:    map[string]string{"affiliate": "abc 123"},
+			alertID:   7,
+			wantPath:  "/flights/GRU-JFK/2025-12-15",
+			wantFrag:  "results",
+			wantQuery: url.Values{"affiliate": {"abc 123"}, "alert_id": {"7"}},
+		},
+		{
+			name:      "generator values win over configured params",
+			link:      "https://example.com/search?utm_source=other",
+			params:    map[string]string{"utm_source": "alert-service"},
+			wantPath:  "/search",
+			wantQuery: url.Values{"utm_source": {"other"}},
+		},
+		{
+			name:     "tracking keys never replace provider keys",
+			link:     "https://www.skyscanner.com/transport/flights/gru/jfk/251215/?adultsv2=2&cabinclass=business",
+			params:   map[string]string{"adultsv2": "1", "q": "spam", "utm_source": "alert-service"},
+			alertID:  42,
+			wantPath: "/transport/flights/gru/jfk/251215/",
+			wantQuery: url.Values{
+				"adultsv2":   {"2"},
+				"cabinclass": {"business"},
+				"q":          {"spam"},
+				"utm_source": {"alert-service"},
+				"alert_id":   {"42"},
+			},
+		},
+		{
+			name:      "no params and no ids",
+			link:      "https://example.com/search?q=1",
+			wantPath:  "/search",
+			wantQuery: url.Values{"q": {"1"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			generator := NewTrackingGenerator(fixedGenerator(tc.link), tc.params)
+
+			link := generator.Generate(domain.LinkRequest{AlertID: tc.alertID, MessageID: tc.messageID})
+
+			parsed, err := url.Parse(link)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantPath, parsed.Path)
+			assert.Equal(t, tc.wantFrag, parsed.Fragment)
+			assert.Equal(t, tc.wantQuery, parsed.Query())
+		})
+	}
+}
+
+func TestWithTracking_PerProviderParams(t *testing.T) {
+	config := TrackingConfig{
+		Default:   map[string]string{"utm_source": "alert-service", "utm_medium": "email"},
+		Providers: map[string]map[string]string{"b": {"utm_medium": "partner", "aff": "b-1"}},
+	}
+
+	registry, err := NewRegistry([]string{"a", "b"}, WithTracking([]Provider{
+		{ID: "a", Name: "A", Generator: fixedGenerator("https://a.example.com/")},
+		{ID: "b", Name: "B", Generator: fixedGenerator("https://b.example.com/")},
+	}, config)...)
+	require.NoError(t, err)
+
+	links := registry.Links(domain.LinkRequest{AlertID: 1, MessageID: "m"})
+
+	require.Len(t, links, 2)
+	assert.Equal(t, "https://a.example.com/?alert_id=1&message_id=m&utm_medium=email&utm_source=alert-service", links[0].URL)
+	assert.Equal(t, "https://b.example.com/?aff=b-1&alert_id=1&message_id=m&utm_medium=partner&utm_source=alert-service", links[1].URL)
+}
+
+func TestLoadTrackingConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tracking.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"default": {"utm_source": "alert-service"},
+		"providers": {"kayak": {"affiliate_id": "123"}}
+	}`), 0o644))
+
+	config, err := LoadTrackingConfig(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"utm_source": "alert-service", "affiliate_id": "123"}, config.params(Kayak))
+	assert.Equal(t, map[string]string{"utm_source": "alert-service"}, config.params(GoogleFlights))
+}
+
+func TestLoadTrackingConfig_Errors(t *testing.T) {
+	_, err := LoadTrackingConfig(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "tracking.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"default": [`), 0o644))
+
+	_, err = LoadTrackingConfig(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,192 @@
+package rates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+)
+
+const (
+	DefaultTTL     = time.Hour
+	DefaultTimeout = 10 * time.Second
+	maxTable       = 1 << 20
+)
+
+// Source loads the current rate table.
+type Source interface {
+	Load(ctx context.Context) (Table, error)
+}
+
+// FileSource reads a JSON or CSV table, by extension, on every load, so
+// edits to the file are picked up once the cache expires.
+type FileSource struct {
+	path string
+}
+
+func NewFileSource(path string) *FileSource {
+	return &FileSource{path: path}
+}
+
+func (s *FileSource) Load(ctx context.Context) (Table, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return Table{}, fmt.Errorf("exchange rates: %w", err)
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(s.path), ".csv") {
+		return ParseCSV(file)
+	}
+	return ParseJSON(file)
+}
+
+// HTTPSource fetches the table from a URL, such as a local stand-in for a
+// rates API. Responses served as text/csv are read as CSV, others as JSON.
+type HTTPSource struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPSource(url string, client *http.Client) *HTTPSource {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+	return &HTTPSource{url: url, client: client}
+}
+
+func (s *HTTPSource) Load(ctx context.Context) (Table, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return Table{}, fmt.Errorf("exchange rates: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return Table{}, fmt.Errorf("exchange rates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Table{}, fmt.Errorf("exchange rates: unexpected status %d", resp.StatusCode)
+	}
+	body := io.LimitReader(resp.Body, maxTable)
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType == "text/csv" {
+		return ParseCSV(body)
+	}
+	return ParseJSON(body)
+}
+
+// Provider serves rates from the last table its source loaded, reloading it
+// once older than the TTL. Only one reload runs at a time, and callers keep
+// getting the stale table while it does. When a reload fails the previous
+// table is kept for another TTL: an old rate, shown with its age, beats no
+// conversion.
+type Provider struct {
+	source  Source
+	ttl     time.Duration
+	timeout time.Duration
+	now     func() time.Time
+
+	mu       sync.Mutex
+	table    Table
+	loaded   bool
+	loadedAt time.Time
+	inFlight *reload
+}
+
+// reload is a load in progress; err is set before done is closed.
+type reload struct {
+	done chan struct{}
+	err  error
+}
+
+func NewProvider(source Source, ttl time.Duration) *Provider {
+	return &Provider{source: source, ttl: ttl, timeout: DefaultTimeout, now: time.Now}
+}
+
+func (p *Provider) Rate(ctx context.Context, from, to string) (domain.ExchangeRate, error) {
+	table, err := p.current(ctx)
+	if err != nil {
+		return domain.ExchangeRate{}, err
+	}
+	return table.Rate(from, to)
+}
+
+// Refresh reloads the table now, regardless of the TTL, or waits for the
+// reload already in progress.
+func (p *Provider) Refresh(ctx context.Context) error {
+	return p.reload(ctx)
+}
+
+func (p *Provider) current(ctx context.Context) (Table, error) {
+	p.mu.Lock()
+	table, loaded := p.table, p.loaded
+	stale := !loaded || p.now().Sub(p.loadedAt) >= p.ttl
+	reloading := p.inFlight != nil
+	p.mu.Unlock()
+
+	if !stale || (loaded && reloading) {
+		return table, nil
+	}
+	if err := p.reload(ctx); err != nil {
+		if !loaded {
+			return Table{}, err
+		}
+		log.Printf("failed to reload exchange rates, keeping the previous ones: %v", err)
+		return table, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.table, nil
+}
+
+// reload loads the table from the source without holding the lock. A caller
+// that finds a reload in progress waits for it instead of starting another.
+func (p *Provider) reload(ctx context.Context) error {
+	p.mu.Lock()
+	if call := p.inFlight; call != nil {
+		p.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	call := &reload{done: make(chan struct{})}
+	p.inFlight = call
+	p.mu.Unlock()
+
+	// The load is shared by every caller waiting on it, so it must not fail
+	// because the message that happened to start it was cancelled.
+	loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), p.timeout)
+	table, err := p.source.Load(loadCtx)
+	cancel()
+
+	p.mu.Lock()
+	switch {
+	case err == nil:
+		p.table, p.loaded, p.loadedAt = table, true, p.now()
+	case !errors.Is(err, context.Canceled):
+		// After a failure the previous table, if any, is served for another
+		// TTL; a cancelled load is retried by the next caller instead.
+		p.loadedAt = p.now()
+	}
+	p.inFlight = nil
+	p.mu.Unlock()
+
+	call.err = err
+	close(call.done)
+	return err
+}
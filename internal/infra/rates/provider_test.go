@@ -0,0 +1,213 @@
+package rates
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubSource struct {
+	tables []Table
+	errs   []error
+	loads  int
+}
+
+func (s *stubSource) Load(ctx context.Context) (Table, error) {
+	i := min(s.loads, len(s.tables)-1)
+	s.loads++
+	return s.tables[i], s.errs[i]
+}
+
+func usdTable(brl float64) Table {
+	return Table{Base: "USD", Rates: map[string]Quote{"BRL": {Rate: brl}}}
+}
+
+func TestProvider_CachesUntilTTL(t *testing.T) {
+	source := &stubSource{tables: []Table{usdTable(5), usdTable(6)}, errs: []error{nil, nil}}
+	now := time.Date(2025, 12, 2, 12, 0, 0, 0, time.UTC)
+	provider := NewProvider(source, time.Hour)
+	provider.now = func() time.Time { return now }
+
+	rate, err := provider.Rate(context.Background(), "USD", "BRL")
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, rate.Rate)
+
+	now = now.Add(59 * time.Minute)
+	rate, err = provider.Rate(context.Background(), "USD", "BRL")
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, rate.Rate)
+	assert.Equal(t, 1, source.loads)
+
+	now = now.Add(time.Minute)
+	rate, err = provider.Rate(context.Background(), "USD", "BRL")
+	require.NoError(t, err)
+	assert.Equal(t, 6.0, rate.Rate)
+	assert.Equal(t, 2, source.loads)
+}
+
+func TestProvider_KeepsPreviousTableWhenReloadFails(t *testing.T) {
+	source := &stubSource{tables: []Table{usdTable(5), {}}, errs: []error{nil, errors.New("connection refused")}}
+	now := time.Date(2025, 12, 2, 12, 0, 0, 0, time.UTC)
+	provider := NewProvider(source, time.Hour)
+	provider.now = func() time.Time { return now }
+
+	require.NoError(t, provider.Refresh(context.Background()))
+	now = now.Add(2 * time.Hour)
+
+	rate, err := provider.Rate(context.Background(), "USD", "BRL")
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, rate.Rate)
+
+	_, err = provider.Rate(context.Background(), "USD", "BRL")
+	require.NoError(t, err)
+	assert.Equal(t, 2, source.loads, "a failed reload waits another TTL")
+}
+
+// gatedSource blocks every load after the first until release is closed.
+type gatedSource struct {
+	loads   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *gatedSource) Load(ctx context.Context) (Table, error) {
+	if s.loads.Add(1) == 1 {
+		return usdTable(5), nil
+	}
+	close(s.started)
+	<-s.release
+	return usdTable(6), nil
+}
+
+func TestProvider_ServesStaleTableWhileReloading(t *testing.T) {
+	source := &gatedSource{started: make(chan struct{}), release: make(chan struct{})}
+	now := time.Date(2025, 12, 2, 12, 0, 0, 0, time.UTC)
+	provider := NewProvider(source, time.Hour)
+	provider.now = func() time.Time { return now }
+	require.NoError(t, provider.Refresh(context.Background()))
+	now = now.Add(2 * time.Hour)
+
+	reloaded := make(chan float64)
+	go func() {
+		rate, _ := provider.Rate(context.Background(), "USD", "BRL")
+		reloaded <- rate.Rate
+	}()
+	<-source.started
+
+	rate, err := provider.Rate(context.Background(), "USD", "BRL")
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, rate.Rate, "the stale table is served during the reload")
+
+	close(source.release)
+	assert.Equal(t, 6.0, <-reloaded)
+	assert.Equal(t, int32(2), source.loads.Load())
+
+	rate, err = provider.Rate(context.Background(), "USD", "BRL")
+	require.NoError(t, err)
+	assert.Equal(t, 6.0, rate.Rate)
+}
+
+type ctxSource struct {
+	err      error
+	deadline bool
+}
+
+func (s *ctxSource) Load(ctx context.Context) (Table, error) {
+	s.err = ctx.Err()
+	_, s.deadline = ctx.Deadline()
+	return usdTable(5), nil
+}
+
+func TestProvider_LoadOutlivesCallerContext(t *testing.T) {
+	source := &ctxSource{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rate, err := NewProvider(source, time.Hour).Rate(ctx, "USD", "BRL")
+
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, rate.Rate)
+	assert.NoError(t, source.err)
+	assert.True(t, source.deadline)
+}
+
+func TestProvider_CancelledLoadIsRetried(t *testing.T) {
+	source := &stubSource{tables: []Table{usdTable(5), {}, usdTable(6)}, errs: []error{nil, context.Canceled, nil}}
+	now := time.Date(2025, 12, 2, 12, 0, 0, 0, time.UTC)
+	provider := NewProvider(source, time.Hour)
+	provider.now = func() time.Time { return now }
+	require.NoError(t, provider.Refresh(context.Background()))
+	now = now.Add(2 * time.Hour)
+
+	rate, err := provider.Rate(context.Background(), "USD", "BRL")
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, rate.Rate)
+
+	rate, err = provider.Rate(context.Background(), "USD", "BRL")
+	require.NoError(t, err)
+	assert.Equal(t, 6.0, rate.Rate, "a cancelled load does not keep the stale table for another TTL")
+	assert.Equal(t, 3, source.loads)
+}
+
+func TestProvider_NoTable(t *testing.T) {
+	source := &stubSource{tables: []Table{{}}, errs: []error{errors.New("connection refused")}}
+
+	_, err := NewProvider(source, time.Hour).Rate(context.Background(), "USD", "BRL")
+
+	assert.EqualError(t, err, "connection refused")
+}
+
+func TestFileSource(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "rates.json")
+	csvPath := filepath.Join(dir, "rates.CSV")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`{"base": "USD", "rates": {"BRL": 5.35}}`), 0o600))
+	require.NoError(t, os.WriteFile(csvPath, []byte("base,currency,rate,as_of\nUSD,BRL,5.4,2025-12-01\n"), 0o600))
+
+	table, err := NewFileSource(jsonPath).Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 5.35, table.Rates["BRL"].Rate)
+
+	table, err = NewFileSource(csvPath).Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 5.4, table.Rates["BRL"].Rate)
+
+	_, err = NewFileSource(filepath.Join(dir, "missing.json")).Load(context.Background())
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestHTTPSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rates.json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"base": "USD", "rates": {"BRL": 5.35}}`))
+		case "/rates.csv":
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			w.Write([]byte("base,currency,rate,as_of\nUSD,BRL,5.4,2025-12-01\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	table, err := NewHTTPSource(server.URL+"/rates.json", nil).Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 5.35, table.Rates["BRL"].Rate)
+
+	table, err = NewHTTPSource(server.URL+"/rates.csv", server.Client()).Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 5.4, table.Rates["BRL"].Rate)
+
+	_, err = NewHTTPSource(server.URL+"/missing", nil).Load(context.Background())
+	assert.EqualError(t, err, "exchange rates: unexpected status 404")
+}
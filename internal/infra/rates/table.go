@@ -0,0 +1,132 @@
+package rates
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+)
+
+// Table holds exchange rates against a base currency: one unit of Base buys
+// Rates[code].Rate units of code.
+type Table struct {
+	Base  string
+	Rates map[string]Quote
+}
+
+type Quote struct {
+	Rate float64
+	AsOf time.Time
+}
+
+// Rate returns the rate from one currency to another, crossing through the
+// base. A cross rate is as old as the older of its two quotes.
+func (t Table) Rate(from, to string) (domain.ExchangeRate, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	fromQuote, err := t.quote(from)
+	if err != nil {
+		return domain.ExchangeRate{}, err
+	}
+	toQuote, err := t.quote(to)
+	if err != nil {
+		return domain.ExchangeRate{}, err
+	}
+
+	asOf := fromQuote.AsOf
+	if asOf.IsZero() || (!toQuote.AsOf.IsZero() && toQuote.AsOf.Before(asOf)) {
+		asOf = toQuote.AsOf
+	}
+	return domain.ExchangeRate{From: from, To: to, Rate: toQuote.Rate / fromQuote.Rate, AsOf: asOf}, nil
+}
+
+func (t Table) quote(code string) (Quote, error) {
+	if code == t.Base {
+		return Quote{Rate: 1}, nil
+	}
+	quote, ok := t.Rates[code]
+	if !ok {
+		return Quote{}, fmt.Errorf("%w: %s", domain.ErrUnknownCurrency, code)
+	}
+	return quote, nil
+}
+
+func (t Table) validate() error {
+	if t.Base == "" {
+		return errors.New("exchange rates: missing base currency")
+	}
+	for code, quote := range t.Rates {
+		if quote.Rate <= 0 {
+			return fmt.Errorf("exchange rates: invalid rate %v for %s", quote.Rate, code)
+		}
+	}
+	return nil
+}
+
+type jsonTable struct {
+	Base  string             `json:"base"`
+	AsOf  time.Time          `json:"asOf"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// ParseJSON reads a table in the format most rate APIs use:
+//
+//	{"base": "USD", "asOf": "2025-12-02T12:00:00Z", "rates": {"BRL": 5.35, "EUR": 0.92}}
+func ParseJSON(r io.Reader) (Table, error) {
+	var raw jsonTable
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return Table{}, fmt.Errorf("exchange rates: %w", err)
+	}
+
+	table := Table{Base: strings.ToUpper(raw.Base), Rates: make(map[string]Quote, len(raw.Rates))}
+	for code, rate := range raw.Rates {
+		table.Rates[strings.ToUpper(code)] = Quote{Rate: rate, AsOf: raw.AsOf}
+	}
+	return table, table.validate()
+}
+
+// ParseCSV reads a table with a base,currency,rate,as_of header and one rate
+// per line, all against the same base. as_of is RFC 3339 or a date.
+func ParseCSV(r io.Reader) (Table, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return Table{}, fmt.Errorf("exchange rates: %w", err)
+	}
+	if len(records) == 0 || strings.Join(records[0], ",") != "base,currency,rate,as_of" {
+		return Table{}, errors.New("exchange rates: expected base,currency,rate,as_of header")
+	}
+
+	table := Table{Rates: make(map[string]Quote, len(records)-1)}
+	for i, record := range records[1:] {
+		line := i + 2
+		base := strings.ToUpper(record[0])
+		if table.Base == "" {
+			table.Base = base
+		}
+		if base != table.Base {
+			return Table{}, fmt.Errorf("exchange rates: line %d: base %s differs from %s", line, base, table.Base)
+		}
+		rate, err := strconv.ParseFloat(record[2], 64)
+		if err != nil {
+			return Table{}, fmt.Errorf("exchange rates: line %d: %w", line, err)
+		}
+		asOf, err := parseAsOf(record[3])
+		if err != nil {
+			return Table{}, fmt.Errorf("exchange rates: line %d: %w", line, err)
+		}
+		table.Rates[strings.ToUpper(record[1])] = Quote{Rate: rate, AsOf: asOf}
+	}
+	return table, table.validate()
+}
+
+func parseAsOf(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
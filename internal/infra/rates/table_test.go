@@ -0,0 +1,101 @@
+package rates
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJSON(t *testing.T) {
+	table, err := ParseJSON(strings.NewReader(`{"base": "usd", "asOf": "2025-12-02T12:00:00Z", "rates": {"brl": 5.35, "EUR": 0.92}}`))
+
+	require.NoError(t, err)
+	asOf := time.Date(2025, 12, 2, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, Table{Base: "USD", Rates: map[string]Quote{
+		"BRL": {Rate: 5.35, AsOf: asOf},
+		"EUR": {Rate: 0.92, AsOf: asOf},
+	}}, table)
+}
+
+func TestParseCSV(t *testing.T) {
+	table, err := ParseCSV(strings.NewReader("base,currency,rate,as_of\nUSD,BRL,5.35,2025-12-02T12:00:00Z\nUSD,eur,0.92,2025-12-01\n"))
+
+	require.NoError(t, err)
+	assert.Equal(t, Table{Base: "USD", Rates: map[string]Quote{
+		"BRL": {Rate: 5.35, AsOf: time.Date(2025, 12, 2, 12, 0, 0, 0, time.UTC)},
+		"EUR": {Rate: 0.92, AsOf: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)},
+	}}, table)
+}
+
+func TestParse_Errors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		parse    func(string) (Table, error)
+		input    string
+		expected string
+	}{
+		{name: "JSON malformed", parse: parseJSONString, input: `{"base":`, expected: "exchange rates: unexpected EOF"},
+		{name: "JSON without base", parse: parseJSONString, input: `{"rates": {"BRL": 5.35}}`, expected: "exchange rates: missing base currency"},
+		{name: "JSON zero rate", parse: parseJSONString, input: `{"base": "USD", "rates": {"BRL": 0}}`, expected: "exchange rates: invalid rate 0 for BRL"},
+		{name: "CSV without header", parse: parseCSVString, input: "USD,BRL,5.35,2025-12-01\n", expected: "exchange rates: expected base,currency,rate,as_of header"},
+		{name: "CSV mixed bases", parse: parseCSVString, input: "base,currency,rate,as_of\nUSD,BRL,5.35,2025-12-01\nEUR,BRL,5.8,2025-12-01\n", expected: "exchange rates: line 3: base EUR differs from USD"},
+		{name: "CSV bad rate", parse: parseCSVString, input: "base,currency,rate,as_of\nUSD,BRL,abc,2025-12-01\n", expected: `exchange rates: line 2: strconv.ParseFloat: parsing "abc": invalid syntax`},
+		{name: "CSV bad date", parse: parseCSVString, input: "base,currency,rate,as_of\nUSD,BRL,5.35,yesterday\n", expected: `exchange rates: line 2: parsing time "yesterday" as "2006-01-02": cannot parse "yesterday" as "2006"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.parse(tc.input)
+			assert.EqualError(t, err, tc.expected)
+		})
+	}
+}
+
+func parseJSONString(input string) (Table, error) { return ParseJSON(strings.NewReader(input)) }
+
+func parseCSVString(input string) (Table, error) { return ParseCSV(strings.NewReader(input)) }
+
+func TestTable_Rate(t *testing.T) {
+	older := time.Date(2025, 12, 1, 12, 0, 0, 0, time.UTC)
+	newer := time.Date(2025, 12, 2, 12, 0, 0, 0, time.UTC)
+	table := Table{Base: "USD", Rates: map[string]Quote{
+		"BRL": {Rate: 5, AsOf: newer},
+		"EUR": {Rate: 0.9, AsOf: older},
+	}}
+
+	testCases := []struct {
+		name     string
+		from     string
+		to       string
+		expected domain.ExchangeRate
+	}{
+		{name: "From base", from: "USD", to: "BRL", expected: domain.ExchangeRate{From: "USD", To: "BRL", Rate: 5, AsOf: newer}},
+		{name: "To base", from: "brl", to: "usd", expected: domain.ExchangeRate{From: "BRL", To: "USD", Rate: 0.2, AsOf: newer}},
+		{name: "Cross rate takes the older quote", from: "BRL", to: "EUR", expected: domain.ExchangeRate{From: "BRL", To: "EUR", Rate: 0.18, AsOf: older}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rate, err := table.Rate(tc.from, tc.to)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected.From, rate.From)
+			assert.Equal(t, tc.expected.To, rate.To)
+			assert.InDelta(t, tc.expected.Rate, rate.Rate, 1e-9)
+			assert.Equal(t, tc.expected.AsOf, rate.AsOf)
+		})
+	}
+}
+
+func TestTable_Rate_UnknownCurrency(t *testing.T) {
+	table := Table{Base: "USD", Rates: map[string]Quote{"BRL": {Rate: 5}}}
+
+	_, err := table.Rate("BRL", "XYZ")
+
+	assert.ErrorIs(t, err, domain.ErrUnknownCurrency)
+	assert.EqualError(t, err, "unknown currency: XYZ")
+}
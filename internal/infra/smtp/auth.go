@@ -0,0 +1,83 @@
+package smtp
+
+import (
+	"errors"
+	"fmt"
+	"net/smtp"
+	"strings"
+)
+
+const (
+	AuthPlain   = "PLAIN"
+	AuthLogin   = "LOGIN"
+	AuthCRAMMD5 = "CRAM-MD5"
+)
+
+var preferredMechanisms = []string{AuthPlain, AuthLogin, AuthCRAMMD5}
+
+func (c *SMTPClient) auth(advertised string) (smtp.Auth, error) {
+	mechanism := strings.ToUpper(c.AuthMechanism)
+	if mechanism == "" {
+		mechanism = negotiateMechanism(advertised)
+	}
+
+	switch mechanism {
+	case AuthPlain:
+		return smtp.PlainAuth("", c.Username, c.Password, c.Server), nil
+	case AuthLogin:
+		return &loginAuth{username: c.Username, password: c.Password, host: c.Server}, nil
+	case AuthCRAMMD5:
+		return smtp.CRAMMD5Auth(c.Username, c.Password), nil
+	case "":
+		return nil, fmt.Errorf("%w: no supported mechanism in %q", ErrAuthFailed, advertised)
+	default:
+		return nil, fmt.Errorf("%w: unsupported mechanism %q", ErrAuthFailed, mechanism)
+	}
+}
+
+func negotiateMechanism(advertised string) string {
+	offered := strings.Fields(strings.ToUpper(advertised))
+	for _, preferred := range preferredMechanisms {
+		for _, mechanism := range offered {
+			if mechanism == preferred {
+				return preferred
+			}
+		}
+	}
+	return ""
+}
+
+type loginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return AuthLogin, nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected LOGIN challenge %q", fromServer)
+	}
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
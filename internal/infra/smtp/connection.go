@@ -1,19 +1,79 @@
 package smtp
 
+import (
+	"crypto/tls"
+	"errors"
+	"time"
+)
+
+type Security int
+
+const (
+	SecurityAuto Security = iota
+	SecurityStartTLS
+	SecurityImplicitTLS
+	SecurityNone
+)
+
+const defaultTimeout = 30 * time.Second
+
 type SMTPClient struct {
-	Server   string
-	Port     int
-	Username string
-	Password string
+	Server        string
+	Port          int
+	Username      string
+	Password      string
+	From          string
+	Security      Security
+	AuthMechanism string
+	TLSConfig     *tls.Config
+	Timeout       time.Duration
 }
 
-func SMTPConnection(server string, port int, username string, password string) (*Connection, error) {
+func SMTPConnection(server string, port int, username string, password string, from string) (*Connection, error) {
+	if server == "" {
+		return nil, errors.New("smtp server is not set")
+	}
+	if port <= 0 {
+		return nil, errors.New("smtp port must be greater than zero")
+	}
+
+	if from == "" {
+		from = username
+	}
+
 	client := &SMTPClient{
 		Server:   server,
 		Port:     port,
 		Username: username,
 		Password: password,
+		From:     from,
 	}
 
-	return NewConnection(client), nil
+	conn := NewConnection(client)
+	if conn.fromErr != nil {
+		return nil, conn.fromErr
+	}
+	return conn, nil
+}
+
+func (c *SMTPClient) implicitTLS() bool {
+	return c.Security == SecurityImplicitTLS || (c.Security == SecurityAuto && c.Port == 465)
+}
+
+func (c *SMTPClient) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return defaultTimeout
+}
+
+func (c *SMTPClient) tlsConfig() *tls.Config {
+	if c.TLSConfig != nil {
+		cfg := c.TLSConfig.Clone()
+		if cfg.ServerName == "" {
+			cfg.ServerName = c.Server
+		}
+		return cfg
+	}
+	return &tls.Config{ServerName: c.Server, MinVersion: tls.VersionTLS12}
 }
@@ -0,0 +1,73 @@
+package smtp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/textproto"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+)
+
+var (
+	ErrAuthFailed          = &permanentError{"smtp authentication failed"}
+	ErrRecipientRejected   = &permanentError{"smtp recipient rejected"}
+	ErrTransient           = errors.New("smtp transient failure")
+	ErrStartTLSUnsupported = errors.New("smtp server does not support STARTTLS")
+)
+
+// permanentError is a failure that fails the same way on every retry; it
+// matches domain.ErrPermanentDelivery.
+type permanentError struct {
+	msg string
+}
+
+func (e *permanentError) Error() string {
+	return e.msg
+}
+
+func (e *permanentError) Is(target error) bool {
+	return target == domain.ErrPermanentDelivery
+}
+
+// classify maps SMTP reply codes onto the package sentinel errors: 4xx is
+// always transient, 5xx becomes permanent when the caller knows what was
+// rejected.
+func classify(err error, permanent error) error {
+	var protoErr *textproto.Error
+	if !errors.As(err, &protoErr) {
+		return err
+	}
+
+	switch {
+	case protoErr.Code >= 400 && protoErr.Code < 500:
+		return fmt.Errorf("%w: %v", ErrTransient, err)
+	case protoErr.Code >= 500 && permanent != nil:
+		return fmt.Errorf("%w: %v", permanent, err)
+	default:
+		return err
+	}
+}
+
+func errorClass(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, ErrAuthFailed):
+		return "auth"
+	case errors.Is(err, ErrRecipientRejected):
+		return "recipient_rejected"
+	case errors.Is(err, ErrTransient):
+		return "transient"
+	case errors.Is(err, ErrStartTLSUnsupported):
+		return "starttls_unsupported"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.As(err, &netErr):
+		return "network"
+	default:
+		return "other"
+	}
+}
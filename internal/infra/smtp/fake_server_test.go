@@ -0,0 +1,328 @@
+package smtp
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMessage struct {
+	From string
+	To   []string
+	Data string
+}
+
+type fakeServer struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+
+	implicitTLS bool
+	startTLS    bool
+	authMechs   []string
+	username    string
+	password    string
+	rejectRcpt  map[string]bool
+	mailReply   string
+	authReply   string
+	quitReply   string
+	silent      bool
+
+	mu       sync.Mutex
+	messages []fakeMessage
+	authUsed string
+	usedTLS  bool
+}
+
+func newFakeServer(t *testing.T, configure func(*fakeServer)) *fakeServer {
+	t.Helper()
+
+	s := &fakeServer{
+		tlsConfig:  newTestCertificate(t),
+		authMechs:  []string{AuthPlain, AuthLogin, AuthCRAMMD5},
+		username:   "user@example.com",
+		password:   "secret",
+		rejectRcpt: map[string]bool{},
+	}
+	if configure != nil {
+		configure(s)
+	}
+
+	var err error
+	if s.implicitTLS {
+		s.listener, err = tls.Listen("tcp", "127.0.0.1:0", s.tlsConfig)
+	} else {
+		s.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	}
+	require.NoError(t, err)
+
+	go s.serve()
+	t.Cleanup(func() { s.listener.Close() })
+
+	return s
+}
+
+func (s *fakeServer) client() *SMTPClient {
+	host, portStr, _ := net.SplitHostPort(s.listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(s.tlsConfig.Certificates[0].Leaf)
+
+	security := SecurityNone
+	switch {
+	case s.implicitTLS:
+		security = SecurityImplicitTLS
+	case s.startTLS:
+		security = SecurityStartTLS
+	}
+
+	return &SMTPClient{
+		Server:    host,
+		Port:      port,
+		Username:  s.username,
+		Password:  s.password,
+		From:      "alerts@example.com",
+		Security:  security,
+		TLSConfig: &tls.Config{RootCAs: pool},
+		Timeout:   5 * time.Second,
+	}
+}
+
+func (s *fakeServer) received() []fakeMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]fakeMessage(nil), s.messages...)
+}
+
+func (s *fakeServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeServer) handle(conn net.Conn) {
+	defer func() { conn.Close() }()
+
+	_, isTLS := conn.(*tls.Conn)
+	reader := bufio.NewReader(conn)
+	reply := func(format string, args ...any) {
+		fmt.Fprintf(conn, format+"\r\n", args...)
+	}
+	readLine := func() (string, bool) {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return "", false
+		}
+		return strings.TrimRight(line, "\r\n"), true
+	}
+
+	if s.silent {
+		reader.ReadString('\n')
+		return
+	}
+
+	reply("220 fake.smtp ready")
+
+	var current fakeMessage
+	for {
+		line, ok := readLine()
+		if !ok {
+			return
+		}
+		verb := strings.ToUpper(strings.Fields(line + " ")[0])
+
+		switch verb {
+		case "EHLO", "HELO":
+			reply("250-fake.smtp")
+			if s.startTLS && !isTLS {
+				reply("250-STARTTLS")
+			}
+			if len(s.authMechs) > 0 {
+				reply("250-AUTH %s", strings.Join(s.authMechs, " "))
+			}
+			reply("250 8BITMIME")
+		case "STARTTLS":
+			reply("220 go ahead")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			reader = bufio.NewReader(conn)
+			isTLS = true
+		case "AUTH":
+			s.handleAuth(line, reply, readLine)
+		case "MAIL":
+			if s.mailReply != "" {
+				reply("%s", s.mailReply)
+				continue
+			}
+			current = fakeMessage{From: extractAddress(line)}
+			reply("250 ok")
+		case "RCPT":
+			addr := extractAddress(line)
+			if s.rejectRcpt[addr] {
+				reply("550 5.1.1 mailbox unavailable")
+				continue
+			}
+			current.To = append(current.To, addr)
+			reply("250 ok")
+		case "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, ok := readLine()
+				if !ok {
+					return
+				}
+				if dataLine == "." {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(dataLine, "."))
+				data.WriteString("\r\n")
+			}
+			current.Data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, current)
+			s.usedTLS = isTLS
+			s.mu.Unlock()
+			reply("250 2.0.0 ok queued as fake123")
+		case "RSET", "NOOP":
+			reply("250 ok")
+		case "QUIT":
+			if s.quitReply != "" {
+				reply("%s", s.quitReply)
+				return
+			}
+			reply("221 bye")
+			return
+		case "*":
+			reply("501 auth aborted")
+		default:
+			reply("502 command not implemented")
+		}
+	}
+}
+
+func (s *fakeServer) handleAuth(line string, reply func(string, ...any), readLine func() (string, bool)) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		reply("501 syntax error")
+		return
+	}
+
+	mechanism := strings.ToUpper(fields[1])
+	s.mu.Lock()
+	s.authUsed = mechanism
+	s.mu.Unlock()
+
+	if s.authReply != "" {
+		reply("%s", s.authReply)
+		return
+	}
+
+	var username, password string
+	switch mechanism {
+	case AuthPlain:
+		decoded, _ := base64.StdEncoding.DecodeString(fields[len(fields)-1])
+		parts := strings.Split(string(decoded), "\x00")
+		if len(parts) == 3 {
+			username, password = parts[1], parts[2]
+		}
+	case AuthLogin:
+		reply("334 %s", base64.StdEncoding.EncodeToString([]byte("Username:")))
+		userLine, _ := readLine()
+		reply("334 %s", base64.StdEncoding.EncodeToString([]byte("Password:")))
+		passLine, _ := readLine()
+		user, _ := base64.StdEncoding.DecodeString(userLine)
+		pass, _ := base64.StdEncoding.DecodeString(passLine)
+		username, password = string(user), string(pass)
+	case AuthCRAMMD5:
+		challenge := "<123.456@fake.smtp>"
+		reply("334 %s", base64.StdEncoding.EncodeToString([]byte(challenge)))
+		respLine, _ := readLine()
+		decoded, _ := base64.StdEncoding.DecodeString(respLine)
+		parts := strings.SplitN(string(decoded), " ", 2)
+		mac := hmac.New(md5.New, []byte(s.password))
+		mac.Write([]byte(challenge))
+		if len(parts) == 2 && parts[1] == hex.EncodeToString(mac.Sum(nil)) {
+			username, password = parts[0], s.password
+		}
+	default:
+		reply("504 unrecognized authentication type")
+		return
+	}
+
+	if username != s.username || password != s.password {
+		reply("535 5.7.8 authentication credentials invalid")
+		return
+	}
+
+	reply("235 2.7.0 authentication successful")
+}
+
+func extractAddress(line string) string {
+	start := strings.Index(line, "<")
+	end := strings.LastIndex(line, ">")
+	if start < 0 || end <= start {
+		return ""
+	}
+	return line[start+1 : end]
+}
+
+func newTestCertificate(t *testing.T) *tls.Config {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "fake.smtp"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{{
+			Certificate: [][]byte{der},
+			PrivateKey:  key,
+			Leaf:        leaf,
+		}},
+	}
+}
@@ -1,14 +1,229 @@
 package smtp
 
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/Luzin7/alert-service/internal/infra/metrics"
+)
+
 type Connection struct {
-	client *SMTPClient
+	client  *SMTPClient
+	from    *mail.Address
+	fromErr error
 }
 
+// NewConnection parses the sender address once; when it is invalid every Send
+// fails with that error. SMTPConnection rejects it up front instead.
 func NewConnection(client *SMTPClient) *Connection {
-	return &Connection{client: client}
+	from, err := mail.ParseAddress(client.From)
+	if err != nil {
+		return &Connection{client: client, fromErr: fmt.Errorf("invalid sender address %q: %w", client.From, err)}
+	}
+	return &Connection{client: client, from: from}
+}
+
+// Send delivers email and returns the server's final reply to DATA, which
+// usually carries the queue ID of the accepted message.
+func (c *Connection) Send(ctx context.Context, email *domain.AlertEmail) (response string, err error) {
+	start := time.Now()
+	defer func() {
+		metrics.SMTPSendDuration.WithLabelValues(metrics.Result(err)).Observe(metrics.Since(start))
+		if err != nil {
+			metrics.SMTPSendErrors.WithLabelValues(errorClass(err)).Inc()
+		}
+	}()
+
+	if c.fromErr != nil {
+		return "", c.fromErr
+	}
+
+	rcpt, err := mail.ParseAddress(email.To)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrRecipientRejected, err)
+	}
+
+	msg, err := buildMessage(c.from, rcpt.Address, email, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	client, stop, err := c.dial(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer stop()
+	defer client.Close()
+
+	if err := c.startTLS(client); err != nil {
+		return "", err
+	}
+
+	if err := c.authenticate(client); err != nil {
+		return "", err
+	}
+
+	if err := client.Mail(c.from.Address); err != nil {
+		return "", classify(err, nil)
+	}
+
+	if err := client.Rcpt(rcpt.Address); err != nil {
+		return "", classify(err, ErrRecipientRejected)
+	}
+
+	response, err = sendData(client, msg)
+	if err != nil {
+		return "", err
+	}
+
+	// The message is already queued once DATA is accepted, so a failed QUIT
+	// must not turn the send into a failure and get it retried.
+	if err := client.Quit(); err != nil {
+		log.Printf("smtp quit after delivery failed: %v", err)
+	}
+	return response, nil
+}
+
+// Ping checks that the server is reachable and accepts a session, without
+// authenticating or sending mail.
+func (c *Connection) Ping(ctx context.Context) error {
+	client, stop, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer stop()
+	defer client.Close()
+
+	if err := c.startTLS(client); err != nil {
+		return err
+	}
+
+	if err := client.Noop(); err != nil {
+		return classify(err, nil)
+	}
+
+	return client.Quit()
+}
+
+func (c *Connection) dial(ctx context.Context) (*smtp.Client, func() bool, error) {
+	addr := net.JoinHostPort(c.client.Server, strconv.Itoa(c.client.Port))
+	dialer := &net.Dialer{Timeout: c.client.timeout()}
+
+	var conn net.Conn
+	var err error
+	if c.client.implicitTLS() {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: c.client.tlsConfig()}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("smtp dial %s: %w", addr, err)
+	}
+
+	deadline := time.Now().Add(c.client.timeout())
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+
+	client, err := smtp.NewClient(conn, c.client.Server)
+	if err != nil {
+		stop()
+		conn.Close()
+		return nil, nil, classify(err, nil)
+	}
+
+	return client, stop, nil
+}
+
+func (c *Connection) startTLS(client *smtp.Client) error {
+	if c.client.implicitTLS() || c.client.Security == SecurityNone {
+		return nil
+	}
+
+	if ok, _ := client.Extension("STARTTLS"); !ok {
+		if c.client.Security == SecurityStartTLS {
+			return ErrStartTLSUnsupported
+		}
+		return nil
+	}
+
+	if err := client.StartTLS(c.client.tlsConfig()); err != nil {
+		return classify(err, nil)
+	}
+
+	return nil
 }
 
-func (c *Connection) Send(to, subject, body string) error {
-	// TODO: Implementar client e lógica de envio de email de vdd
+func (c *Connection) authenticate(client *smtp.Client) error {
+	if c.client.Username == "" {
+		return nil
+	}
+
+	ok, mechanisms := client.Extension("AUTH")
+	if !ok {
+		return fmt.Errorf("%w: server does not support AUTH", ErrAuthFailed)
+	}
+
+	auth, err := c.client.auth(mechanisms)
+	if err != nil {
+		return err
+	}
+
+	if err := client.Auth(auth); err != nil {
+		err = classify(err, ErrAuthFailed)
+		if errors.Is(err, ErrTransient) || errors.Is(err, ErrAuthFailed) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", ErrAuthFailed, err)
+	}
+
 	return nil
 }
+
+// sendData runs DATA by hand because smtp.Client.Data discards the server's
+// final reply.
+func sendData(client *smtp.Client, msg []byte) (string, error) {
+	id, err := client.Text.Cmd("DATA")
+	if err != nil {
+		return "", err
+	}
+	client.Text.StartResponse(id)
+	_, _, err = client.Text.ReadResponse(354)
+	client.Text.EndResponse(id)
+	if err != nil {
+		return "", classify(err, nil)
+	}
+
+	w := client.Text.DotWriter()
+	if _, err := w.Write(msg); err != nil {
+		w.Close()
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	code, message, err := client.Text.ReadResponse(250)
+	if err != nil {
+		return "", classify(err, nil)
+	}
+	return fmt.Sprintf("%d %s", code, message), nil
+}
@@ -0,0 +1,354 @@
+package smtp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/Luzin7/alert-service/internal/infra/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnection_Send_StartTLS(t *testing.T) {
+	server := newFakeServer(t, func(s *fakeServer) {
+		s.startTLS = true
+	})
+
+	conn := NewConnection(server.client())
+
+	response, err := conn.Send(context.Background(), &domain.AlertEmail{To: "user@example.com", Subject: "Price Alert Updated", TextBody: "Novo preço: 1200.00 BRL"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "250 2.0.0 ok queued as fake123", response)
+	messages := server.received()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "alerts@example.com", messages[0].From)
+	assert.Equal(t, []string{"user@example.com"}, messages[0].To)
+	assert.Contains(t, messages[0].Data, "Subject: Price Alert Updated")
+	assert.Contains(t, messages[0].Data, "Novo pre=C3=A7o: 1200.00 BRL")
+	assert.True(t, server.usedTLS)
+}
+
+func TestConnection_Send_ImplicitTLS(t *testing.T) {
+	server := newFakeServer(t, func(s *fakeServer) {
+		s.implicitTLS = true
+	})
+
+	conn := NewConnection(server.client())
+
+	_, err := conn.Send(context.Background(), &domain.AlertEmail{To: "user@example.com", Subject: "Price Alert Updated", TextBody: "body"})
+
+	require.NoError(t, err)
+	assert.Len(t, server.received(), 1)
+	assert.True(t, server.usedTLS)
+}
+
+func TestConnection_Send_MultipartAlternative(t *testing.T) {
+	server := newFakeServer(t, func(s *fakeServer) {
+		s.startTLS = true
+	})
+
+	conn := NewConnection(server.client())
+
+	_, err := conn.Send(context.Background(), &domain.AlertEmail{
+		To:       "user@example.com",
+		Subject:  "Alerta de preço: GRU → JFK",
+		TextBody: "Novo preço: 1800.00 BRL",
+		HTMLBody: "<p>Novo preço: <strong>1800.00 BRL</strong></p>",
+	})
+	require.NoError(t, err)
+
+	messages := server.received()
+	require.Len(t, messages, 1)
+
+	msg, err := mail.ReadMessage(strings.NewReader(messages[0].Data))
+	require.NoError(t, err)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Alerta de preço: GRU → JFK", subject)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	var contentTypes, bodies []string
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(quotedprintable.NewReader(part))
+		require.NoError(t, err)
+		contentTypes = append(contentTypes, part.Header.Get("Content-Type"))
+		bodies = append(bodies, string(content))
+	}
+
+	assert.Equal(t, []string{`text/plain; charset=utf-8`, `text/html; charset=utf-8`}, contentTypes)
+	assert.Equal(t, "Novo preço: 1800.00 BRL", bodies[0])
+	assert.Equal(t, "<p>Novo preço: <strong>1800.00 BRL</strong></p>", bodies[1])
+}
+
+func TestConnection_Send_DisplayNameSender(t *testing.T) {
+	server := newFakeServer(t, nil)
+	client := server.client()
+	client.From = "Alertas <alerts@example.com>"
+
+	_, err := NewConnection(client).Send(context.Background(), &domain.AlertEmail{To: "user@example.com", Subject: "subject", TextBody: "body"})
+
+	require.NoError(t, err)
+	messages := server.received()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "alerts@example.com", messages[0].From)
+	assert.Contains(t, messages[0].Data, `From: "Alertas" <alerts@example.com>`)
+}
+
+func TestConnection_Send_QuitFailureAfterDelivery(t *testing.T) {
+	server := newFakeServer(t, func(s *fakeServer) {
+		s.quitReply = "421 closing connection"
+	})
+
+	response, err := NewConnection(server.client()).Send(context.Background(), &domain.AlertEmail{To: "user@example.com", Subject: "subject", TextBody: "body"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "250 2.0.0 ok queued as fake123", response)
+	assert.Len(t, server.received(), 1)
+}
+
+func TestConnection_Send_StartTLSRequiredButUnsupported(t *testing.T) {
+	server := newFakeServer(t, nil)
+
+	client := server.client()
+	client.Security = SecurityStartTLS
+	conn := NewConnection(client)
+
+	_, err := conn.Send(context.Background(), &domain.AlertEmail{To: "user@example.com", Subject: "subject", TextBody: "body"})
+
+	assert.ErrorIs(t, err, ErrStartTLSUnsupported)
+	assert.Empty(t, server.received())
+}
+
+func TestConnection_Send_AuthMechanisms(t *testing.T) {
+	testCases := []struct {
+		name       string
+		advertised []string
+		configured string
+		expected   string
+	}{
+		{
+			name:       "Negotiates PLAIN first",
+			advertised: []string{AuthCRAMMD5, AuthLogin, AuthPlain},
+			expected:   AuthPlain,
+		},
+		{
+			name:       "Falls back to LOGIN",
+			advertised: []string{AuthLogin, AuthCRAMMD5},
+			expected:   AuthLogin,
+		},
+		{
+			name:       "Falls back to CRAM-MD5",
+			advertised: []string{AuthCRAMMD5},
+			expected:   AuthCRAMMD5,
+		},
+		{
+			name:       "Configured mechanism wins",
+			advertised: []string{AuthPlain, AuthLogin, AuthCRAMMD5},
+			configured: "cram-md5",
+			expected:   AuthCRAMMD5,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newFakeServer(t, func(s *fakeServer) {
+				s.startTLS = true
+				s.authMechs = tc.advertised
+			})
+
+			client := server.client()
+			client.AuthMechanism = tc.configured
+			conn := NewConnection(client)
+
+			_, err := conn.Send(context.Background(), &domain.AlertEmail{To: "user@example.com", Subject: "subject", TextBody: "body"})
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, server.authUsed)
+			assert.Len(t, server.received(), 1)
+		})
+	}
+}
+
+func TestConnection_Send_AuthFailure(t *testing.T) {
+	server := newFakeServer(t, func(s *fakeServer) {
+		s.startTLS = true
+	})
+
+	client := server.client()
+	client.Password = "wrong"
+	conn := NewConnection(client)
+
+	_, err := conn.Send(context.Background(), &domain.AlertEmail{To: "user@example.com", Subject: "subject", TextBody: "body"})
+
+	assert.ErrorIs(t, err, ErrAuthFailed)
+	assert.NotErrorIs(t, err, ErrTransient)
+	assert.Empty(t, server.received())
+}
+
+func TestConnection_Send_AuthTemporaryFailure(t *testing.T) {
+	server := newFakeServer(t, func(s *fakeServer) {
+		s.startTLS = true
+		s.authReply = "454 4.7.0 temporary authentication failure"
+	})
+
+	conn := NewConnection(server.client())
+
+	_, err := conn.Send(context.Background(), &domain.AlertEmail{To: "user@example.com", Subject: "subject", TextBody: "body"})
+
+	assert.ErrorIs(t, err, ErrTransient)
+	assert.NotErrorIs(t, err, ErrAuthFailed)
+}
+
+func TestConnection_Send_RecipientRejected(t *testing.T) {
+	server := newFakeServer(t, func(s *fakeServer) {
+		s.startTLS = true
+		s.rejectRcpt["unknown@example.com"] = true
+	})
+
+	conn := NewConnection(server.client())
+
+	_, err := conn.Send(context.Background(), &domain.AlertEmail{To: "unknown@example.com", Subject: "subject", TextBody: "body"})
+
+	assert.ErrorIs(t, err, ErrRecipientRejected)
+	assert.ErrorIs(t, err, domain.ErrPermanentDelivery)
+	assert.Empty(t, server.received())
+}
+
+func TestConnection_Send_InvalidRecipient(t *testing.T) {
+	conn := NewConnection(&SMTPClient{Server: "127.0.0.1", Port: 1, From: "alerts@example.com"})
+
+	_, err := conn.Send(context.Background(), &domain.AlertEmail{To: "not-an-address", Subject: "subject", TextBody: "body"})
+
+	assert.ErrorIs(t, err, ErrRecipientRejected)
+}
+
+func TestConnection_Send_TransientFailure(t *testing.T) {
+	server := newFakeServer(t, func(s *fakeServer) {
+		s.startTLS = true
+		s.mailReply = "451 4.3.0 mailbox temporarily unavailable"
+	})
+
+	conn := NewConnection(server.client())
+	transientErrors := metrics.SMTPSendErrors.WithLabelValues("transient")
+	before := testutil.ToFloat64(transientErrors)
+
+	_, err := conn.Send(context.Background(), &domain.AlertEmail{To: "user@example.com", Subject: "subject", TextBody: "body"})
+
+	assert.ErrorIs(t, err, ErrTransient)
+	assert.Empty(t, server.received())
+	assert.Equal(t, before+1, testutil.ToFloat64(transientErrors))
+}
+
+func TestConnection_Send_DialError(t *testing.T) {
+	server := newFakeServer(t, nil)
+	client := server.client()
+	server.listener.Close()
+
+	_, err := NewConnection(client).Send(context.Background(), &domain.AlertEmail{To: "user@example.com", Subject: "subject", TextBody: "body"})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "smtp dial")
+}
+
+func TestConnection_Send_ContextDeadline(t *testing.T) {
+	server := newFakeServer(t, func(s *fakeServer) {
+		s.silent = true
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := NewConnection(server.client()).Send(ctx, &domain.AlertEmail{To: "user@example.com", Subject: "subject", TextBody: "body"})
+
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestConnection_Send_ContextCanceled(t *testing.T) {
+	server := newFakeServer(t, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewConnection(server.client()).Send(ctx, &domain.AlertEmail{To: "user@example.com", Subject: "subject", TextBody: "body"})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, server.received())
+}
+
+func TestSMTPConnection_Validation(t *testing.T) {
+	_, err := SMTPConnection("", 587, "user", "pass", "")
+	assert.Error(t, err)
+
+	_, err = SMTPConnection("smtp.example.com", 0, "user", "pass", "")
+	assert.Error(t, err)
+
+	conn, err := SMTPConnection("smtp.example.com", 465, "user@example.com", "pass", "")
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", conn.from.Address)
+	assert.True(t, conn.client.implicitTLS())
+
+	_, err = SMTPConnection("smtp.sendgrid.net", 587, "apikey", "pass", "")
+	assert.ErrorContains(t, err, `invalid sender address "apikey"`)
+
+	conn, err = SMTPConnection("smtp.sendgrid.net", 587, "apikey", "pass", "Alertas <alerts@example.com>")
+	require.NoError(t, err)
+	assert.Equal(t, "alerts@example.com", conn.from.Address)
+}
+
+func TestConnection_Ping(t *testing.T) {
+	server := newFakeServer(t, func(s *fakeServer) {
+		s.startTLS = true
+	})
+
+	err := NewConnection(server.client()).Ping(context.Background())
+
+	assert.NoError(t, err)
+	assert.Empty(t, server.received())
+}
+
+func TestConnection_Ping_Unreachable(t *testing.T) {
+	server := newFakeServer(t, nil)
+	client := server.client()
+	server.listener.Close()
+
+	err := NewConnection(client).Ping(context.Background())
+
+	assert.Error(t, err)
+}
+
+func TestErrorClass(t *testing.T) {
+	assert.Equal(t, "auth", errorClass(fmt.Errorf("%w: bad credentials", ErrAuthFailed)))
+	assert.Equal(t, "recipient_rejected", errorClass(ErrRecipientRejected))
+	assert.Equal(t, "transient", errorClass(ErrTransient))
+	assert.Equal(t, "starttls_unsupported", errorClass(ErrStartTLSUnsupported))
+	assert.Equal(t, "timeout", errorClass(context.DeadlineExceeded))
+	assert.Equal(t, "timeout", errorClass(&net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}))
+	assert.Equal(t, "network", errorClass(&net.OpError{Op: "dial", Err: errors.New("connection refused")}))
+	assert.Equal(t, "other", errorClass(errors.New("boom")))
+}
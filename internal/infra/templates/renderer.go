@@ -0,0 +1,300 @@
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/Luzin7/alert-service/internal/infra/i18n"
+)
+
+const (
+	SubjectTemplate       = "subject.tmpl"
+	TextTemplate          = "body.txt.tmpl"
+	HTMLTemplate          = "body.html.tmpl"
+	DigestSubjectTemplate = "digest_subject.tmpl"
+	DigestTextTemplate    = "digest_body.txt.tmpl"
+	DigestHTMLTemplate    = "digest_body.html.tmpl"
+)
+
+//go:embed default/*.tmpl
+var defaultTemplates embed.FS
+
+// Renderer keeps one template set per catalog locale, with the t, price,
+// date, percent and locale functions bound to that locale.
+type Renderer struct {
+	bundle  *i18n.Bundle
+	sets    map[string]templateSet
+	digests map[string]templateSet
+	now     func() time.Time
+}
+
+type templateSet struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+type alertView struct {
+	Name         string
+	Origin       string
+	Destination  string
+	OutboundDate time.Time
+	ReturnDate   time.Time
+	OldPrice     float64
+	NewPrice     float64
+	TargetPrice  float64
+	DropPercent  float64
+	Currency     string
+	Link         string
+	Links        []linkView
+	Trend        *trendView
+	Converted    *convertedView
+}
+
+// trendView is the price history of the alert; it is nil when there are not
+// enough prices to compare with.
+type trendView struct {
+	Days         int
+	Lowest       bool
+	BelowAverage float64
+	Min          float64
+	Max          float64
+	Average      float64
+	Sparkline    string
+}
+
+// convertedView holds the prices in the recipient's display currency and
+// the rate used, already formatted; it is nil when there is no conversion.
+// AsOf is zero and Age empty when the rate source gave no quote date.
+type convertedView struct {
+	Currency    string
+	OldPrice    float64
+	NewPrice    float64
+	TargetPrice float64
+	Rate        string
+	AsOf        time.Time
+	Age         string
+}
+
+type linkView struct {
+	Name string
+	URL  string
+}
+
+type digestView struct {
+	Name      string
+	Frequency string
+	Items     []digestItemView
+}
+
+type digestItemView struct {
+	Origin       string
+	Destination  string
+	OutboundDate time.Time
+	ReturnDate   time.Time
+	OldPrice     float64
+	NewPrice     float64
+	DropPercent  float64
+	Currency     string
+	Link         string
+}
+
+func funcs(localizer *i18n.Localizer) map[string]any {
+	return map[string]any{
+		"t":       localizer.T,
+		"price":   localizer.Price,
+		"date":    localizer.Date,
+		"percent": localizer.Percent,
+		"locale":  localizer.Locale,
+	}
+}
+
+func NewRenderer(overrideDir string) (*Renderer, error) {
+	bundle, err := i18n.NewBundle()
+	if err != nil {
+		return nil, err
+	}
+
+	if overrideDir != "" {
+		info, err := os.Stat(overrideDir)
+		if err != nil {
+			return nil, fmt.Errorf("template directory: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("template directory %s is not a directory", overrideDir)
+		}
+	}
+
+	sources := make(map[string]string)
+	for _, name := range []string{SubjectTemplate, TextTemplate, HTMLTemplate, DigestSubjectTemplate, DigestTextTemplate, DigestHTMLTemplate} {
+		if sources[name], err = load(overrideDir, name); err != nil {
+			return nil, err
+		}
+	}
+
+	r := &Renderer{bundle: bundle, sets: make(map[string]templateSet), digests: make(map[string]templateSet), now: time.Now}
+	for _, locale := range bundle.Locales() {
+		localized := funcs(bundle.Localizer(locale))
+
+		if r.sets[locale], err = parseSet(localized, sources, SubjectTemplate, TextTemplate, HTMLTemplate); err != nil {
+			return nil, err
+		}
+		if r.digests[locale], err = parseSet(localized, sources, DigestSubjectTemplate, DigestTextTemplate, DigestHTMLTemplate); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+func parseSet(localized map[string]any, sources map[string]string, subjectName, textName, htmlName string) (templateSet, error) {
+	subject, err := texttemplate.New(subjectName).Funcs(localized).Option("missingkey=error").Parse(sources[subjectName])
+	if err != nil {
+		return templateSet{}, err
+	}
+	text, err := texttemplate.New(textName).Funcs(localized).Option("missingkey=error").Parse(sources[textName])
+	if err != nil {
+		return templateSet{}, err
+	}
+	html, err := htmltemplate.New(htmlName).Funcs(localized).Option("missingkey=error").Parse(sources[htmlName])
+	if err != nil {
+		return templateSet{}, err
+	}
+	return templateSet{subject: subject, text: text, html: html}, nil
+}
+
+// Render builds the email in the recipient's locale, falling back through
+// the i18n chain to the default locale.
+func (r *Renderer) Render(alert *domain.Alert, recipient domain.Recipient) (*domain.AlertEmail, error) {
+	locale := r.bundle.Match(recipient.Locale)
+	set := r.sets[locale]
+
+	view := alertView{
+		Name:         recipient.Name,
+		Origin:       alert.Origin,
+		Destination:  alert.Destination,
+		OutboundDate: alert.OutboundDate,
+		ReturnDate:   alert.ReturnDate,
+		OldPrice:     alert.OldPrice,
+		NewPrice:     alert.NewPrice,
+		TargetPrice:  alert.TargetPrice,
+		DropPercent:  alert.DropPercent(),
+		Currency:     alert.Currency,
+		Link:         alert.Link,
+	}
+	for _, link := range alert.Links {
+		view.Links = append(view.Links, linkView{Name: link.Name, URL: link.URL})
+	}
+	if trend := alert.Trend; trend.Comparable() {
+		view.Trend = &trendView{
+			Days:         trend.Days,
+			Lowest:       trend.Lowest(alert.NewPrice),
+			BelowAverage: trend.BelowAverage(alert.NewPrice),
+			Min:          trend.Stats.Min,
+			Max:          trend.Stats.Max,
+			Average:      trend.Stats.Average,
+			Sparkline:    sparkline(trend.DailyPrices()),
+		}
+	}
+	if rate := alert.DisplayRate; !rate.IsZero() {
+		localizer := r.bundle.Localizer(locale)
+		view.Converted = &convertedView{
+			Currency:    rate.To,
+			OldPrice:    rate.Convert(alert.OldPrice),
+			NewPrice:    rate.Convert(alert.NewPrice),
+			TargetPrice: rate.Convert(alert.TargetPrice),
+			Rate:        localizer.Number(rate.Rate, 4),
+			AsOf:        rate.AsOf,
+		}
+		if !rate.AsOf.IsZero() {
+			view.Converted.Age = rateAge(localizer, rate.Age(r.now()))
+		}
+	}
+
+	return set.execute(view)
+}
+
+// RenderDigest builds the summary email of a digest in the recipient's
+// locale. Digests flushed after the recipient turned them off use the daily
+// wording.
+func (r *Renderer) RenderDigest(digest domain.Digest, recipient domain.Recipient) (*domain.AlertEmail, error) {
+	set := r.digests[r.bundle.Match(recipient.Locale)]
+
+	view := digestView{Name: recipient.Name, Frequency: string(digest.Frequency)}
+	if !digest.Frequency.Enabled() {
+		view.Frequency = string(domain.DigestDaily)
+	}
+	for _, item := range digest.Items {
+		view.Items = append(view.Items, digestItemView{
+			Origin:       item.Origin,
+			Destination:  item.Destination,
+			OutboundDate: item.OutboundDate,
+			ReturnDate:   item.ReturnDate,
+			OldPrice:     item.OldPrice,
+			NewPrice:     item.NewPrice,
+			DropPercent:  item.DropPercent(),
+			Currency:     item.Currency,
+			Link:         item.Link,
+		})
+	}
+
+	return set.execute(view)
+}
+
+func rateAge(localizer *i18n.Localizer, age time.Duration) string {
+	switch {
+	case age < time.Hour:
+		return localizer.T("email.rate_age_recent")
+	case age < 48*time.Hour:
+		return localizer.T("email.rate_age_hours", int(age.Hours()))
+	default:
+		return localizer.T("email.rate_age_days", int(age.Hours()/24))
+	}
+}
+
+func (set templateSet) execute(view any) (*domain.AlertEmail, error) {
+	var subject, text, html bytes.Buffer
+	if err := set.subject.Execute(&subject, view); err != nil {
+		return nil, fmt.Errorf("render subject: %w", err)
+	}
+	if err := set.text.Execute(&text, view); err != nil {
+		return nil, fmt.Errorf("render text body: %w", err)
+	}
+	if err := set.html.Execute(&html, view); err != nil {
+		return nil, fmt.Errorf("render html body: %w", err)
+	}
+
+	return &domain.AlertEmail{
+		Subject:  strings.Join(strings.Fields(subject.String()), " "),
+		TextBody: text.String(),
+		HTMLBody: html.String(),
+	}, nil
+}
+
+func load(dir, name string) (string, error) {
+	if dir != "" {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			return string(content), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+
+	content, err := defaultTemplates.ReadFile("default/" + name)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
@@ -0,0 +1,468 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAlert() *domain.Alert {
+	return &domain.Alert{
+		ID:           42,
+		Origin:       "GRU",
+		Destination:  "JFK",
+		OutboundDate: time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC),
+		ReturnDate:   time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC),
+		OldPrice:     2500.00,
+		NewPrice:     1800.00,
+		TargetPrice:  2000.00,
+		Currency:     "BRL",
+		Link:         "https://www.google.com/travel/flights?q=GRU&x=1",
+	}
+}
+
+func newTestRecipient() domain.Recipient {
+	return domain.NewRecipient(42, "user@example.com")
+}
+
+func TestRenderer_Render_DefaultTemplates(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+
+	email, err := renderer.Render(newTestAlert(), newTestRecipient())
+
+	require.NoError(t, err)
+	assert.Equal(t, "Alerta de preço: GRU → JFK por R$ 1.800,00", email.Subject)
+	assert.Empty(t, email.To)
+
+	for _, body := range []string{email.TextBody, email.HTMLBody} {
+		assert.Contains(t, body, "GRU → JFK")
+		assert.Contains(t, body, "15/12/2025")
+		assert.Contains(t, body, "20/12/2025")
+		assert.Contains(t, body, "R$ 2.500,00")
+		assert.Contains(t, body, "R$ 1.800,00")
+		assert.Contains(t, body, "R$ 2.000,00")
+		assert.Contains(t, body, "28,0%")
+	}
+	assert.Contains(t, email.TextBody, "https://www.google.com/travel/flights?q=GRU&x=1")
+	assert.Contains(t, email.HTMLBody, `href="https://www.google.com/travel/flights?q=GRU&amp;x=1"`)
+}
+
+func TestRenderer_Render_NoDropHidesPercent(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+
+	alert := newTestAlert()
+	alert.NewPrice = 2600.00
+
+	email, err := renderer.Render(alert, newTestRecipient())
+
+	require.NoError(t, err)
+	assert.NotContains(t, email.TextBody, "mais barato")
+	assert.NotContains(t, email.HTMLBody, "mais barato")
+}
+
+func TestRenderer_Render_NoTargetPrice(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+
+	alert := newTestAlert()
+	alert.TargetPrice = 0
+	alert.DisplayRate = domain.ExchangeRate{From: "BRL", To: "USD", Rate: 0.1869}
+
+	email, err := renderer.Render(alert, newTestRecipient())
+
+	require.NoError(t, err)
+	for _, body := range []string{email.TextBody, email.HTMLBody} {
+		assert.NotContains(t, body, "Preço desejado")
+		assert.NotContains(t, body, "R$ 0,00")
+		assert.NotContains(t, body, "US$ 0,00")
+	}
+	assert.Contains(t, email.TextBody, "(28,0% mais barato)\nCâmbio:")
+}
+
+func TestRenderer_Render_MultipleLinks(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+
+	alert := newTestAlert()
+	alert.Links = []domain.BookingLink{
+		{Provider: "google_flights", Name: "Google Flights", URL: alert.Link},
+		{Provider: "kayak", Name: "Kayak", URL: "https://www.kayak.com/flights/GRU-JFK/2025-12-15/2025-12-20"},
+		{Provider: "skyscanner", Name: "Skyscanner", URL: "https://www.skyscanner.com/transport/flights/gru/jfk/251215/251220/"},
+	}
+
+	email, err := renderer.Render(alert, newTestRecipient())
+
+	require.NoError(t, err)
+	assert.Contains(t, email.TextBody, "- Google Flights: https://www.google.com/travel/flights?q=GRU&x=1\n- Kayak: https://www.kayak.com/flights/GRU-JFK/2025-12-15/2025-12-20\n- Skyscanner:")
+	kayak := strings.Index(email.HTMLBody, `>Kayak</a>`)
+	skyscanner := strings.Index(email.HTMLBody, `>Skyscanner</a>`)
+	assert.Positive(t, kayak)
+	assert.Greater(t, skyscanner, kayak)
+}
+
+func TestRenderer_Render_SingleLinkHidesComparison(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+
+	alert := newTestAlert()
+	alert.Links = []domain.BookingLink{{Provider: "google_flights", Name: "Google Flights", URL: alert.Link}}
+
+	email, err := renderer.Render(alert, newTestRecipient())
+
+	require.NoError(t, err)
+	assert.NotContains(t, email.TextBody, "Compare em outros sites")
+	assert.NotContains(t, email.HTMLBody, "Compare em outros sites")
+}
+
+func TestRenderer_Render_OneWay(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+
+	alert := newTestAlert()
+	alert.ReturnDate = time.Time{}
+
+	email, err := renderer.Render(alert, newTestRecipient())
+
+	require.NoError(t, err)
+	assert.Contains(t, email.TextBody, "Volta: Somente ida")
+	assert.Contains(t, email.HTMLBody, "Somente ida")
+	assert.NotContains(t, email.TextBody, "01/01/0001")
+}
+
+func newTestTrend() domain.PriceTrend {
+	day := time.Date(2025, 11, 28, 0, 0, 0, 0, time.UTC)
+	return domain.PriceTrend{
+		Days:  30,
+		Stats: domain.PriceStats{Min: 1800, Max: 2500, Average: 2100, Count: 12},
+		Daily: []domain.PricePoint{
+			{Price: 2500, CheckedAt: day},
+			{Price: 2100, CheckedAt: day.AddDate(0, 0, 1)},
+			{Price: 1800, CheckedAt: day.AddDate(0, 0, 2)},
+		},
+	}
+}
+
+func TestRenderer_Render_Trend(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+
+	alert := newTestAlert()
+	alert.Trend = newTestTrend()
+
+	email, err := renderer.Render(alert, newTestRecipient())
+
+	require.NoError(t, err)
+	for _, body := range []string{email.TextBody, email.HTMLBody} {
+		assert.Contains(t, body, "Últimos 30 dias")
+		assert.Contains(t, body, "█▄▁")
+		assert.Contains(t, body, "Mínimo R$ 1.800,00, máximo R$ 2.500,00, média R$ 2.100,00")
+		assert.Contains(t, body, "Menor preço dos últimos 30 dias!")
+		assert.NotContains(t, body, "abaixo da média")
+	}
+	assert.Contains(t, email.TextBody, "Últimos 30 dias: █▄▁\n")
+}
+
+func TestRenderer_Render_TrendBelowAverage(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+
+	alert := newTestAlert()
+	alert.NewPrice = 1890
+	alert.Trend = newTestTrend()
+	alert.Trend.Daily = nil
+
+	email, err := renderer.Render(alert, newTestRecipient())
+
+	require.NoError(t, err)
+	for _, body := range []string{email.TextBody, email.HTMLBody} {
+		assert.Contains(t, body, "10,0% abaixo da média")
+		assert.NotContains(t, body, "Menor preço")
+	}
+	assert.Contains(t, email.TextBody, "Últimos 30 dias\n")
+}
+
+func TestRenderer_Render_NoTrend(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+
+	alert := newTestAlert()
+	alert.Trend = domain.PriceTrend{Days: 30, Stats: domain.PriceStats{Min: 1800, Max: 1800, Average: 1800, Count: 1}}
+
+	email, err := renderer.Render(alert, newTestRecipient())
+
+	require.NoError(t, err)
+	assert.NotContains(t, email.TextBody, "Últimos 30 dias")
+	assert.NotContains(t, email.HTMLBody, "Últimos 30 dias")
+}
+
+func TestRenderer_Render_ConvertedPrices(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+	asOf := time.Date(2025, 12, 2, 12, 0, 0, 0, time.UTC)
+	renderer.now = func() time.Time { return asOf.Add(3 * time.Hour) }
+
+	alert := newTestAlert()
+	alert.DisplayRate = domain.ExchangeRate{From: "BRL", To: "USD", Rate: 0.1869, AsOf: asOf}
+
+	email, err := renderer.Render(alert, newTestRecipient())
+
+	require.NoError(t, err)
+	assert.Contains(t, email.TextBody, "Preço anterior: R$ 2.500,00 (≈ US$ 467,25)\n")
+	assert.Contains(t, email.TextBody, "Novo preço: R$ 1.800,00 (≈ US$ 336,42) (28,0% mais barato)\n")
+	assert.Contains(t, email.TextBody, "Preço desejado: R$ 2.000,00 (≈ US$ 373,80)\n")
+	for _, body := range []string{email.TextBody, email.HTMLBody} {
+		assert.Contains(t, body, "Câmbio: 1 BRL = 0,1869 USD, cotação de 02/12/2025 (há 3 h)")
+	}
+	assert.Equal(t, "Alerta de preço: GRU → JFK por R$ 1.800,00", email.Subject)
+}
+
+func TestRenderer_Render_RateAge(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+	asOf := time.Date(2025, 12, 2, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		age      time.Duration
+		expected string
+	}{
+		{age: 20 * time.Minute, expected: "(há menos de 1 h)"},
+		{age: 47 * time.Hour, expected: "(há 47 h)"},
+		{age: 80 * time.Hour, expected: "(há 3 dias)"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.age.String(), func(t *testing.T) {
+			renderer.now = func() time.Time { return asOf.Add(tc.age) }
+			alert := newTestAlert()
+			alert.DisplayRate = domain.ExchangeRate{From: "BRL", To: "USD", Rate: 0.1869, AsOf: asOf}
+
+			email, err := renderer.Render(alert, newTestRecipient())
+
+			require.NoError(t, err)
+			assert.Contains(t, email.TextBody, tc.expected)
+		})
+	}
+}
+
+func TestRenderer_Render_UndatedRate(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+
+	alert := newTestAlert()
+	alert.DisplayRate = domain.ExchangeRate{From: "BRL", To: "USD", Rate: 0.1869}
+
+	email, err := renderer.Render(alert, newTestRecipient())
+
+	require.NoError(t, err)
+	for _, body := range []string{email.TextBody, email.HTMLBody} {
+		assert.Contains(t, body, "Câmbio: 1 BRL = 0,1869 USD")
+		assert.NotContains(t, body, "cotação de")
+		assert.NotContains(t, body, "01/01/0001")
+	}
+}
+
+func TestRenderer_Render_NoConversion(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+
+	email, err := renderer.Render(newTestAlert(), newTestRecipient())
+
+	require.NoError(t, err)
+	assert.NotContains(t, email.TextBody, "≈")
+	assert.NotContains(t, email.HTMLBody, "Câmbio")
+}
+
+func TestRenderer_Render_EscapesHTML(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+
+	alert := newTestAlert()
+	alert.Destination = "<script>alert(1)</script>"
+	alert.Link = "javascript:alert(1)"
+
+	email, err := renderer.Render(alert, newTestRecipient())
+
+	require.NoError(t, err)
+	assert.NotContains(t, email.HTMLBody, "<script>")
+	assert.NotContains(t, email.HTMLBody, `href="javascript:`)
+}
+
+func TestRenderer_Render_OverrideDirectory(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, SubjectTemplate), []byte("Custom {{.Origin}}-{{.Destination}}\n"), 0o644))
+
+	renderer, err := NewRenderer(dir)
+	require.NoError(t, err)
+
+	email, err := renderer.Render(newTestAlert(), newTestRecipient())
+
+	require.NoError(t, err)
+	assert.Equal(t, "Custom GRU-JFK", email.Subject)
+	assert.Contains(t, email.TextBody, "Novo preço: R$ 1.800,00")
+}
+
+func TestRenderer_NewRenderer_InvalidOverride(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, HTMLTemplate), []byte("{{.Origin"), 0o644))
+
+	renderer, err := NewRenderer(dir)
+
+	assert.Error(t, err)
+	assert.Nil(t, renderer)
+}
+
+func TestRenderer_NewRenderer_MissingDirectory(t *testing.T) {
+	renderer, err := NewRenderer(filepath.Join(t.TempDir(), "missing"))
+
+	assert.Error(t, err)
+	assert.Nil(t, renderer)
+}
+
+func TestRenderer_Render_UnknownFieldFails(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, TextTemplate), []byte("{{.Unknown}}"), 0o644))
+
+	renderer, err := NewRenderer(dir)
+	require.NoError(t, err)
+
+	_, err = renderer.Render(newTestAlert(), newTestRecipient())
+
+	assert.Error(t, err)
+}
+
+func TestRenderer_Render_Locales(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		locale   string
+		subject  string
+		contains []string
+	}{
+		{
+			locale:   "en-US",
+			subject:  "Price alert: GRU → JFK for R$1,800.00",
+			contains: []string{"Hi Ana!", "Departure", "12/15/2025", "R$2,500.00", "28.0% cheaper", "Compare on other sites:", "Lowest price in 30 days!"},
+		},
+		{
+			locale:   "es-MX",
+			subject:  "Alerta de precio: GRU → JFK por 1.800,00 R$",
+			contains: []string{"¡Hola, Ana!", "Vuelta", "15/12/2025", "2.500,00 R$", "28,0 % más barato", "Compara en otros sitios:", "¡El precio más bajo en 30 días!"},
+		},
+		{
+			locale:   "fr-FR",
+			subject:  "Alerta de preço: GRU → JFK por R$ 1.800,00",
+			contains: []string{"Olá, Ana!", "Volta", "15/12/2025", "R$ 2.500,00", "28,0% mais barato", "Compare em outros sites:", "Menor preço dos últimos 30 dias!"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.locale, func(t *testing.T) {
+			recipient := newTestRecipient()
+			recipient.Name = "Ana"
+			recipient.Locale = tc.locale
+
+			alert := newTestAlert()
+			alert.Links = []domain.BookingLink{
+				{Provider: "google_flights", Name: "Google Flights", URL: alert.Link},
+				{Provider: "kayak", Name: "Kayak", URL: "https://www.kayak.com/flights/GRU-JFK/2025-12-15/2025-12-20"},
+			}
+			alert.Trend = newTestTrend()
+
+			email, err := renderer.Render(alert, recipient)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.subject, email.Subject)
+			for _, body := range []string{email.TextBody, email.HTMLBody} {
+				for _, text := range tc.contains {
+					assert.Contains(t, body, text)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderer_Render_HTMLLang(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+
+	recipient := newTestRecipient()
+	recipient.Locale = "en"
+
+	email, err := renderer.Render(newTestAlert(), recipient)
+
+	require.NoError(t, err)
+	assert.Contains(t, email.HTMLBody, `<html lang="en-US">`)
+}
+
+func newTestDigest(frequency domain.DigestFrequency) domain.Digest {
+	outbound := time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC)
+	return domain.Digest{
+		ID:        5,
+		Email:     "user@example.com",
+		Frequency: frequency,
+		Items: []domain.DigestItem{
+			{Origin: "GRU", Destination: "JFK", OutboundDate: outbound, ReturnDate: outbound.AddDate(0, 0, 5), OldPrice: 2500, NewPrice: 1800, Currency: "BRL", Link: "https://example.com/r/a?x=1&y=2"},
+			{Origin: "GRU", Destination: "LIS", OutboundDate: outbound, OldPrice: 3000, NewPrice: 2900, Currency: "EUR"},
+		},
+	}
+}
+
+func TestRenderer_RenderDigest(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+
+	email, err := renderer.RenderDigest(newTestDigest(domain.DigestDaily), newTestRecipient())
+
+	require.NoError(t, err)
+	assert.Equal(t, "Resumo diário dos seus alertas (2)", email.Subject)
+	for _, body := range []string{email.TextBody, email.HTMLBody} {
+		assert.Contains(t, body, "do último dia")
+		assert.Contains(t, body, "GRU → JFK")
+		assert.Contains(t, body, "15/12/2025 - 20/12/2025")
+		assert.Contains(t, body, "R$ 1.800,00")
+		assert.Contains(t, body, "28,0% mais barato")
+		assert.Contains(t, body, "GRU → LIS")
+		assert.Contains(t, body, "€ 2.900,00")
+	}
+	assert.Less(t, strings.Index(email.TextBody, "GRU → JFK"), strings.Index(email.TextBody, "GRU → LIS"))
+	assert.Contains(t, email.TextBody, "Ver voo: https://example.com/r/a?x=1&y=2")
+	assert.Contains(t, email.HTMLBody, `href="https://example.com/r/a?x=1&amp;y=2"`)
+	assert.Equal(t, 1, strings.Count(email.HTMLBody, "Ver voo"))
+}
+
+func TestRenderer_RenderDigest_Frequencies(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		frequency domain.DigestFrequency
+		locale    string
+		subject   string
+	}{
+		{frequency: domain.DigestWeekly, locale: "pt-BR", subject: "Resumo semanal dos seus alertas (2)"},
+		{frequency: domain.DigestWeekly, locale: "en-US", subject: "Your weekly alert digest (2)"},
+		{frequency: domain.DigestDaily, locale: "es", subject: "Tu resumen diario de alertas (2)"},
+		{frequency: domain.DigestOff, locale: "en-US", subject: "Your daily alert digest (2)"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.frequency)+"/"+tc.locale, func(t *testing.T) {
+			recipient := newTestRecipient()
+			recipient.Locale = tc.locale
+
+			email, err := renderer.RenderDigest(newTestDigest(tc.frequency), recipient)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.subject, email.Subject)
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package templates
+
+import "strings"
+
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws prices as a row of bars scaled between the lowest and the
+// highest, so it reads the same in plain text and HTML emails. It is empty
+// with fewer than two prices.
+func sparkline(prices []float64) string {
+	if len(prices) < 2 {
+		return ""
+	}
+
+	low, high := prices[0], prices[0]
+	for _, price := range prices[1:] {
+		low = min(low, price)
+		high = max(high, price)
+	}
+
+	top := len(sparkBars) - 1
+	var b strings.Builder
+	for _, price := range prices {
+		bar := top / 2
+		if high > low {
+			bar = int((price-low)/(high-low)*float64(top) + 0.5)
+		}
+		b.WriteRune(sparkBars[bar])
+	}
+	return b.String()
+}
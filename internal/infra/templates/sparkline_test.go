@@ -0,0 +1,26 @@
+package templates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSparkline(t *testing.T) {
+	testCases := []struct {
+		name     string
+		prices   []float64
+		expected string
+	}{
+		{name: "Scaled between low and high", prices: []float64{2500, 2100, 1800, 2150, 2500}, expected: "█▄▁▅█"},
+		{name: "Flat", prices: []float64{1800, 1800, 1800}, expected: "▄▄▄"},
+		{name: "Single price", prices: []float64{1800}, expected: ""},
+		{name: "No prices", prices: nil, expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, sparkline(tc.prices))
+		})
+	}
+}
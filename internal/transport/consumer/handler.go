@@ -3,29 +3,81 @@ package consumer
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
 
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/Luzin7/alert-service/internal/infra/metrics"
 	"github.com/Luzin7/alert-service/internal/usecases"
 )
 
+var (
+	ErrMessageInProgress = errors.New("mensagem em processamento por outro consumidor")
+	ErrInvalidPayload    = errors.New("payload invalido")
+)
+
 type Handler struct {
-	useCase *usecases.ProcessAlert
+	useCase     *usecases.ProcessAlert
+	idempotency domain.IdempotencyStore
 }
 
-func NewHandler(uc *usecases.ProcessAlert) *Handler {
-	return &Handler{useCase: uc}
+func NewHandler(uc *usecases.ProcessAlert, idempotency domain.IdempotencyStore) *Handler {
+	return &Handler{
+		useCase:     uc,
+		idempotency: idempotency,
+	}
 }
 
-func (h *Handler) Handle(msgBody []byte) error {
+func (h *Handler) Handle(ctx context.Context, msgBody []byte) error {
+	start := time.Now()
+	outcome := "error"
+	defer func() {
+		metrics.HandlerDuration.WithLabelValues(outcome).Observe(metrics.Since(start))
+	}()
+
 	var payload PriceUpdatedPayload
 
 	if err := json.Unmarshal(msgBody, &payload); err != nil {
-		return err
+		outcome = "invalid_payload"
+		return fmt.Errorf("%w: %w", ErrInvalidPayload, err)
 	}
 
 	alert, err := payload.ToDomain()
 	if err != nil {
+		outcome = "invalid_payload"
+		return fmt.Errorf("%w: %w", ErrInvalidPayload, err)
+	}
+
+	key := payload.IdempotencyKey(msgBody)
+
+	status, err := h.idempotency.Acquire(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case domain.IdempotencyCompleted:
+		log.Printf("Mensagem %s ja processada, ignorando", key)
+		outcome = "duplicate"
+		return nil
+	case domain.IdempotencyInProgress:
+		outcome = "in_progress"
+		return ErrMessageInProgress
+	}
+
+	if _, err := h.useCase.Execute(ctx, alert); err != nil {
+		if releaseErr := h.idempotency.Release(context.WithoutCancel(ctx), key); releaseErr != nil {
+			log.Printf("Erro liberando chave de idempotencia %s: %v", key, releaseErr)
+		}
 		return err
 	}
 
-	return h.useCase.Execute(context.Background(), alert)
+	if err := h.idempotency.Complete(context.WithoutCancel(ctx), key); err != nil {
+		log.Printf("Erro marcando mensagem %s como processada: %v", key, err)
+	}
+
+	outcome = "processed"
+	return nil
 }
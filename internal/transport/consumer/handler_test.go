@@ -1,19 +1,83 @@
 package consumer
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/Luzin7/alert-service/internal/infra/cache"
+	"github.com/Luzin7/alert-service/internal/usecases"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+type stubLinkGenerator struct{}
+
+func (stubLinkGenerator) Links(req domain.LinkRequest) []domain.BookingLink {
+	return []domain.BookingLink{{Provider: "google_flights", Name: "Google Flights", URL: "https://example.com/flights"}}
+}
+
+type stubRepository struct {
+	err error
+}
+
+func (r *stubRepository) GetRecipient(ctx context.Context, alertID int64) (domain.Recipient, error) {
+	return domain.NewRecipient(alertID, "user@example.com"), r.err
+}
+
+type stubRenderer struct{}
+
+func (stubRenderer) Render(alert *domain.Alert, recipient domain.Recipient) (*domain.AlertEmail, error) {
+	return &domain.AlertEmail{Subject: "subject", TextBody: "body"}, nil
+}
+
+type recordingNotifier struct {
+	sent []domain.Message
+	err  error
+}
+
+func (n *recordingNotifier) Channel() domain.Channel {
+	return domain.ChannelEmail
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, msg domain.Message) (string, error) {
+	if n.err != nil {
+		return "", n.err
+	}
+	n.sent = append(n.sent, msg)
+	return "250 ok", nil
+}
+
+const validMessage = `{
+	"messageId": "msg-123",
+	"alertId": 1,
+	"origin": "GRU",
+	"destination": "JFK",
+	"outboundDate": "2025-12-15",
+	"returnDate": "2025-12-20",
+	"oldPrice": 1500.00,
+	"newPrice": 1200.00,
+	"currency": "BRL",
+	"targetPrice": 1000.00,
+	"checkedAt": "2025-12-02T10:00:00Z"
+}`
+
+func newTestHandler(repo *stubRepository, notifier *recordingNotifier, store domain.IdempotencyStore) *Handler {
+	uc := usecases.NewProcessAlert(stubLinkGenerator{}, repo, []domain.Notifier{notifier}, stubRenderer{})
+	return NewHandler(uc, store)
+}
+
 func TestHandler_Handle_InvalidJSON(t *testing.T) {
 	handler := &Handler{}
 
 	invalidJSON := []byte(`{"invalid json}`)
 
-	err := handler.Handle(invalidJSON)
+	err := handler.Handle(context.Background(), invalidJSON)
 
 	assert.Error(t, err)
+	assert.ErrorIs(t, err, ErrInvalidPayload)
 }
 
 func TestHandler_Handle_InvalidDate(t *testing.T) {
@@ -33,8 +97,90 @@ func TestHandler_Handle_InvalidDate(t *testing.T) {
 		"checkedAt": "2025-12-02T10:00:00Z"
 	}`)
 
-	err := handler.Handle(invalidDateJSON)
+	err := handler.Handle(context.Background(), invalidDateJSON)
 
 	assert.Error(t, err)
+	assert.ErrorIs(t, err, ErrInvalidPayload)
 	assert.Contains(t, err.Error(), "data ida invalida")
 }
+
+func TestHandler_Handle_DuplicateMessageSentOnce(t *testing.T) {
+	notifier := &recordingNotifier{}
+	handler := newTestHandler(&stubRepository{}, notifier, cache.NewMemoryIdempotencyStore(time.Minute, time.Hour))
+
+	require.NoError(t, handler.Handle(context.Background(), []byte(validMessage)))
+	require.NoError(t, handler.Handle(context.Background(), []byte(validMessage)))
+
+	assert.Len(t, notifier.sent, 1)
+}
+
+func TestHandler_Handle_UpdatesWithoutMessageIDOrCheckedAt(t *testing.T) {
+	notifier := &recordingNotifier{}
+	handler := newTestHandler(&stubRepository{}, notifier, cache.NewMemoryIdempotencyStore(time.Minute, time.Hour))
+
+	first := []byte(`{"alertId": 1, "origin": "GRU", "destination": "JFK", "outboundDate": "2025-12-15", "oldPrice": 1500, "newPrice": 1200, "currency": "BRL"}`)
+	second := []byte(`{"alertId": 1, "origin": "GRU", "destination": "JFK", "outboundDate": "2025-12-15", "oldPrice": 1200, "newPrice": 1000, "currency": "BRL"}`)
+
+	require.NoError(t, handler.Handle(context.Background(), first))
+	require.NoError(t, handler.Handle(context.Background(), second))
+	require.NoError(t, handler.Handle(context.Background(), second))
+
+	assert.Len(t, notifier.sent, 2, "distinct updates are delivered, a redelivered copy is not")
+}
+
+func TestHandler_Handle_FailureAllowsRetry(t *testing.T) {
+	notifier := &recordingNotifier{err: errors.New("smtp down")}
+	handler := newTestHandler(&stubRepository{}, notifier, cache.NewMemoryIdempotencyStore(time.Minute, time.Hour))
+
+	err := handler.Handle(context.Background(), []byte(validMessage))
+	require.Error(t, err)
+
+	notifier.err = nil
+	err = handler.Handle(context.Background(), []byte(validMessage))
+
+	require.NoError(t, err)
+	assert.Len(t, notifier.sent, 1)
+}
+
+func TestHandler_Handle_InProgress(t *testing.T) {
+	notifier := &recordingNotifier{}
+	store := cache.NewMemoryIdempotencyStore(time.Minute, time.Hour)
+	handler := newTestHandler(&stubRepository{}, notifier, store)
+
+	_, err := store.Acquire(context.Background(), "msg-123")
+	require.NoError(t, err)
+
+	err = handler.Handle(context.Background(), []byte(validMessage))
+
+	assert.ErrorIs(t, err, ErrMessageInProgress)
+	assert.Empty(t, notifier.sent)
+}
+
+func TestHandler_Handle_SuppressedAlertIsCompleted(t *testing.T) {
+	notifier := &recordingNotifier{}
+	store := cache.NewMemoryIdempotencyStore(time.Minute, time.Hour)
+	handler := newTestHandler(&stubRepository{}, notifier, store)
+
+	priceIncrease := []byte(`{
+		"messageId": "msg-456",
+		"alertId": 1,
+		"origin": "GRU",
+		"destination": "JFK",
+		"outboundDate": "2025-12-15",
+		"returnDate": "2025-12-20",
+		"oldPrice": 1200.00,
+		"newPrice": 1500.00,
+		"currency": "BRL",
+		"targetPrice": 1000.00,
+		"toleranceUp": 100.00,
+		"checkedAt": "2025-12-02T10:00:00Z"
+	}`)
+
+	err := handler.Handle(context.Background(), priceIncrease)
+
+	require.NoError(t, err)
+	assert.Empty(t, notifier.sent)
+	status, err := store.Acquire(context.Background(), "msg-456")
+	require.NoError(t, err)
+	assert.Equal(t, domain.IdempotencyCompleted, status)
+}
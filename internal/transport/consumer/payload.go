@@ -1,6 +1,8 @@
 package consumer
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -19,17 +21,48 @@ type PriceUpdatedPayload struct {
 	Currency     string    `json:"currency"`
 	TargetPrice  float64   `json:"targetPrice"`
 	ToleranceUp  float64   `json:"toleranceUp"`
+	CabinClass   string    `json:"cabinClass"`
+	Passengers   int       `json:"passengers"`
 	CheckedAt    time.Time `json:"checkedAt"`
 }
 
+// IdempotencyKey identifies a message across redeliveries: its messageId,
+// else alertId:checkedAt. Without either, the key is a hash of the raw body,
+// so a redelivered copy is still caught while distinct updates of the same
+// alert are not.
+func (p *PriceUpdatedPayload) IdempotencyKey(body []byte) string {
+	if p.MessageID != "" {
+		return p.MessageID
+	}
+	if p.CheckedAt.IsZero() {
+		sum := sha256.Sum256(body)
+		return fmt.Sprintf("%d:%s", p.AlertID, hex.EncodeToString(sum[:]))
+	}
+	return fmt.Sprintf("%d:%s", p.AlertID, p.CheckedAt.UTC().Format(time.RFC3339Nano))
+}
+
 func (p *PriceUpdatedPayload) ToDomain() (*domain.Alert, error) {
 	out, err := time.Parse("2006-01-02", p.OutboundDate)
 	if err != nil {
 		return nil, fmt.Errorf("data ida invalida: %w", err)
 	}
-	ret, err := time.Parse("2006-01-02", p.ReturnDate)
+	var ret time.Time
+	if p.ReturnDate != "" {
+		ret, err = time.Parse("2006-01-02", p.ReturnDate)
+		if err != nil {
+			return nil, fmt.Errorf("data volta invalida: %w", err)
+		}
+	}
+	cabin, err := domain.ParseCabinClass(p.CabinClass)
 	if err != nil {
-		return nil, fmt.Errorf("data volta invalida: %w", err)
+		return nil, fmt.Errorf("classe invalida: %w", err)
+	}
+	passengers := p.Passengers
+	if passengers < 0 {
+		return nil, fmt.Errorf("numero de passageiros invalido: %d", passengers)
+	}
+	if passengers == 0 {
+		passengers = 1
 	}
 
 	return &domain.Alert{
@@ -43,6 +76,9 @@ func (p *PriceUpdatedPayload) ToDomain() (*domain.Alert, error) {
 		NewPrice:     p.NewPrice,
 		Currency:     p.Currency,
 		TargetPrice:  p.TargetPrice,
+		ToleranceUp:  p.ToleranceUp,
+		Cabin:        cabin,
+		Passengers:   passengers,
 		CheckedAt:    p.CheckedAt,
 	}, nil
 }
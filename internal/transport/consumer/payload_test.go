@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Luzin7/alert-service/internal/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -35,6 +36,7 @@ func TestPriceUpdatedPayload_ToDomain_Success(t *testing.T) {
 	assert.Equal(t, 1200.00, alert.NewPrice)
 	assert.Equal(t, "BRL", alert.Currency)
 	assert.Equal(t, 1000.00, alert.TargetPrice)
+	assert.Equal(t, 100.00, alert.ToleranceUp)
 
 	expectedOut, _ := time.Parse("2006-01-02", "2025-12-15")
 	expectedRet, _ := time.Parse("2006-01-02", "2025-12-20")
@@ -106,3 +108,59 @@ func TestPriceUpdatedPayload_ToDomain_WrongDateFormat(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, alert)
 }
+
+func TestPriceUpdatedPayload_IdempotencyKey(t *testing.T) {
+	checkedAt := time.Date(2025, 12, 2, 10, 0, 0, 0, time.UTC)
+
+	withMessageID := &PriceUpdatedPayload{MessageID: "msg-123", AlertID: 1, CheckedAt: checkedAt}
+	withoutMessageID := &PriceUpdatedPayload{AlertID: 1, CheckedAt: checkedAt}
+
+	assert.Equal(t, "msg-123", withMessageID.IdempotencyKey(nil))
+	assert.Equal(t, "1:2025-12-02T10:00:00Z", withoutMessageID.IdempotencyKey(nil))
+
+	withoutCheckedAt := &PriceUpdatedPayload{AlertID: 1}
+	first := withoutCheckedAt.IdempotencyKey([]byte(`{"alertId": 1, "newPrice": 1200}`))
+	assert.Equal(t, first, withoutCheckedAt.IdempotencyKey([]byte(`{"alertId": 1, "newPrice": 1200}`)))
+	assert.NotEqual(t, first, withoutCheckedAt.IdempotencyKey([]byte(`{"alertId": 1, "newPrice": 1100}`)))
+	assert.Regexp(t, "^1:[0-9a-f]{64}$", first)
+}
+
+func TestPriceUpdatedPayload_ToDomain_OneWayWithCabin(t *testing.T) {
+	payload := &PriceUpdatedPayload{
+		AlertID:      1,
+		Origin:       "GRU",
+		Destination:  "JFK",
+		OutboundDate: "2025-12-15",
+		CabinClass:   "business",
+		Passengers:   2,
+	}
+
+	alert, err := payload.ToDomain()
+
+	require.NoError(t, err)
+	assert.True(t, alert.ReturnDate.IsZero())
+	assert.True(t, alert.LinkRequest().OneWay())
+	assert.Equal(t, domain.CabinBusiness, alert.Cabin)
+	assert.Equal(t, 2, alert.Passengers)
+}
+
+func TestPriceUpdatedPayload_ToDomain_DefaultCabinAndPassengers(t *testing.T) {
+	payload := &PriceUpdatedPayload{
+		OutboundDate: "2025-12-15",
+		ReturnDate:   "2025-12-20",
+	}
+
+	alert, err := payload.ToDomain()
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.CabinEconomy, alert.Cabin)
+	assert.Equal(t, 1, alert.Passengers)
+}
+
+func TestPriceUpdatedPayload_ToDomain_InvalidCabinOrPassengers(t *testing.T) {
+	_, err := (&PriceUpdatedPayload{OutboundDate: "2025-12-15", CabinClass: "luxury"}).ToDomain()
+	assert.Error(t, err)
+
+	_, err = (&PriceUpdatedPayload{OutboundDate: "2025-12-15", Passengers: -1}).ToDomain()
+	assert.Error(t, err)
+}
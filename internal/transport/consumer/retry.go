@@ -0,0 +1,159 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/Luzin7/alert-service/internal/infra/metrics"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	retryCountHeader = "x-retry-count"
+	lastErrorHeader  = "x-last-error"
+	failedAtHeader   = "x-failed-at"
+)
+
+var DefaultRetryDelays = []time.Duration{10 * time.Second, time.Minute, 10 * time.Minute}
+
+type amqpChannel interface {
+	QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error)
+	PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+}
+
+type consumerChannel interface {
+	amqpChannel
+	Cancel(consumer string, noWait bool) error
+}
+
+func retryQueueName(queueName string, delay time.Duration) string {
+	return fmt.Sprintf("%s.retry.%s", queueName, formatDelay(delay))
+}
+
+func deadLetterQueueName(queueName string) string {
+	return queueName + ".dlq"
+}
+
+func formatDelay(delay time.Duration) string {
+	switch {
+	case delay%time.Hour == 0:
+		return fmt.Sprintf("%dh", delay/time.Hour)
+	case delay%time.Minute == 0:
+		return fmt.Sprintf("%dm", delay/time.Minute)
+	case delay%time.Second == 0:
+		return fmt.Sprintf("%ds", delay/time.Second)
+	default:
+		return fmt.Sprintf("%dms", delay/time.Millisecond)
+	}
+}
+
+// declareTopology declares one retry queue per backoff level, each dead
+// lettering back into the main queue once the message TTL expires, plus the
+// final dead-letter queue.
+func declareTopology(ch amqpChannel, queueName string, delays []time.Duration) error {
+	for _, delay := range delays {
+		_, err := ch.QueueDeclare(retryQueueName(queueName, delay), true, false, false, false, amqp.Table{
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": queueName,
+		})
+		if err != nil {
+			return fmt.Errorf("declarando fila de retry: %w", err)
+		}
+	}
+
+	if _, err := ch.QueueDeclare(deadLetterQueueName(queueName), true, false, false, false, nil); err != nil {
+		return fmt.Errorf("declarando DLQ: %w", err)
+	}
+
+	return nil
+}
+
+func retryCount(headers amqp.Table) int {
+	switch v := headers[retryCountHeader].(type) {
+	case int:
+		return v
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(v)
+		return n
+	default:
+		return 0
+	}
+}
+
+func (w *Worker) handleFailure(ctx context.Context, ch amqpChannel, queueName string, d amqp.Delivery, handleErr error) {
+	attempts := retryCount(d.Headers)
+	delays := w.config.RetryDelays
+
+	var target, expiration string
+	var delay time.Duration
+	deadLetterReason := ""
+	nextAttempts := attempts
+
+	switch {
+	case errors.Is(handleErr, ErrMessageInProgress) && len(delays) > 0:
+		delay = delays[0]
+	case errors.Is(handleErr, ErrInvalidPayload):
+		deadLetterReason = "invalid_payload"
+	case errors.Is(handleErr, domain.ErrPermanentDelivery):
+		deadLetterReason = "permanent_failure"
+	case attempts >= len(delays):
+		deadLetterReason = "retries_exhausted"
+	default:
+		delay = delays[attempts]
+		nextAttempts = attempts + 1
+	}
+
+	if deadLetterReason != "" {
+		target = deadLetterQueueName(queueName)
+	} else {
+		target = retryQueueName(queueName, delay)
+		expiration = strconv.FormatInt(delay.Milliseconds(), 10)
+	}
+
+	headers := amqp.Table{}
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+	headers[retryCountHeader] = int32(nextAttempts)
+	headers[lastErrorHeader] = handleErr.Error()
+	headers[failedAtHeader] = time.Now().UTC().Format(time.RFC3339)
+
+	err := ch.PublishWithContext(ctx, "", target, false, false, amqp.Publishing{
+		Headers:         headers,
+		ContentType:     d.ContentType,
+		ContentEncoding: d.ContentEncoding,
+		DeliveryMode:    amqp.Persistent,
+		CorrelationId:   d.CorrelationId,
+		MessageId:       d.MessageId,
+		Timestamp:       d.Timestamp,
+		Type:            d.Type,
+		AppId:           d.AppId,
+		Expiration:      expiration,
+		Body:            d.Body,
+	})
+	if err != nil {
+		log.Printf("Erro publicando mensagem %s em %s, devolvendo para a fila: %v", d.MessageId, target, err)
+		d.Nack(false, true)
+		metrics.MessagesNacked.WithLabelValues(queueName, "true").Inc()
+		return
+	}
+
+	if deadLetterReason != "" {
+		log.Printf("Mensagem %s enviada para a DLQ apos %d tentativas: %v", d.MessageId, attempts, handleErr)
+		metrics.MessagesDeadLettered.WithLabelValues(queueName, deadLetterReason).Inc()
+	} else {
+		log.Printf("Mensagem %s agendada para retry em %s (tentativa %d): %v", d.MessageId, target, nextAttempts, handleErr)
+		metrics.MessagesRetried.WithLabelValues(queueName, formatDelay(delay)).Inc()
+	}
+
+	d.Ack(false)
+}
@@ -0,0 +1,237 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/Luzin7/alert-service/internal/infra/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type declaredQueue struct {
+	name string
+	args amqp.Table
+}
+
+type publishedMessage struct {
+	key string
+	msg amqp.Publishing
+}
+
+type fakeChannel struct {
+	mu         sync.Mutex
+	declared   []declaredQueue
+	published  []publishedMessage
+	cancelled  []string
+	publishErr error
+}
+
+func (c *fakeChannel) QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error) {
+	c.declared = append(c.declared, declaredQueue{name: name, args: args})
+	return amqp.Queue{Name: name}, nil
+}
+
+func (c *fakeChannel) PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.publishErr != nil {
+		return c.publishErr
+	}
+	c.published = append(c.published, publishedMessage{key: key, msg: msg})
+	return nil
+}
+
+func (c *fakeChannel) Cancel(consumer string, noWait bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cancelled = append(c.cancelled, consumer)
+	return nil
+}
+
+type fakeAcknowledger struct {
+	mu       sync.Mutex
+	acked    int
+	nacked   int
+	requeued bool
+}
+
+func (a *fakeAcknowledger) Ack(tag uint64, multiple bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.acked++
+	return nil
+}
+
+func (a *fakeAcknowledger) Nack(tag uint64, multiple bool, requeue bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.nacked++
+	a.requeued = requeue
+	return nil
+}
+
+func (a *fakeAcknowledger) Reject(tag uint64, requeue bool) error {
+	return a.Nack(tag, false, requeue)
+}
+
+func newTestDelivery(ack *fakeAcknowledger, retries int) amqp.Delivery {
+	headers := amqp.Table{"x-custom": "keep"}
+	if retries > 0 {
+		headers[retryCountHeader] = int32(retries)
+	}
+	return amqp.Delivery{
+		Acknowledger: ack,
+		DeliveryTag:  1,
+		MessageId:    "msg-123",
+		ContentType:  "application/json",
+		Headers:      headers,
+		Body:         []byte(`{"messageId":"msg-123"}`),
+	}
+}
+
+func TestDeclareTopology(t *testing.T) {
+	ch := &fakeChannel{}
+
+	err := declareTopology(ch, "price-alerts", DefaultRetryDelays)
+
+	require.NoError(t, err)
+	require.Len(t, ch.declared, 4)
+	assert.Equal(t, "price-alerts.retry.10s", ch.declared[0].name)
+	assert.Equal(t, "price-alerts.retry.1m", ch.declared[1].name)
+	assert.Equal(t, "price-alerts.retry.10m", ch.declared[2].name)
+	assert.Equal(t, "price-alerts.dlq", ch.declared[3].name)
+	for _, q := range ch.declared[:3] {
+		assert.Equal(t, "", q.args["x-dead-letter-exchange"])
+		assert.Equal(t, "price-alerts", q.args["x-dead-letter-routing-key"])
+	}
+}
+
+func TestWorker_HandleFailure_SchedulesRetryWithBackoff(t *testing.T) {
+	testCases := []struct {
+		retries    int
+		queue      string
+		expiration string
+	}{
+		{retries: 0, queue: "price-alerts.retry.10s", expiration: "10000"},
+		{retries: 1, queue: "price-alerts.retry.1m", expiration: "60000"},
+		{retries: 2, queue: "price-alerts.retry.10m", expiration: "600000"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("attempt %d", tc.retries+1), func(t *testing.T) {
+			worker := NewWorker(nil, nil, DefaultWorkerConfig())
+			ch := &fakeChannel{}
+			ack := &fakeAcknowledger{}
+
+			worker.handleFailure(context.Background(), ch, "price-alerts", newTestDelivery(ack, tc.retries), errors.New("smtp down"))
+
+			require.Len(t, ch.published, 1)
+			published := ch.published[0]
+			assert.Equal(t, tc.queue, published.key)
+			assert.Equal(t, tc.expiration, published.msg.Expiration)
+			assert.Equal(t, int32(tc.retries+1), published.msg.Headers[retryCountHeader])
+			assert.Equal(t, "smtp down", published.msg.Headers[lastErrorHeader])
+			assert.Equal(t, "keep", published.msg.Headers["x-custom"])
+			assert.Equal(t, "msg-123", published.msg.MessageId)
+			assert.Equal(t, amqp.Persistent, published.msg.DeliveryMode)
+			assert.Equal(t, 1, ack.acked)
+			assert.Equal(t, 0, ack.nacked)
+		})
+	}
+}
+
+func TestWorker_HandleFailure_DeadLettersAfterMaxAttempts(t *testing.T) {
+	worker := NewWorker(nil, nil, DefaultWorkerConfig())
+	ch := &fakeChannel{}
+	ack := &fakeAcknowledger{}
+	deadLettered := metrics.MessagesDeadLettered.WithLabelValues("price-alerts", "retries_exhausted")
+	before := testutil.ToFloat64(deadLettered)
+
+	worker.handleFailure(context.Background(), ch, "price-alerts", newTestDelivery(ack, 3), errors.New("smtp down"))
+
+	require.Len(t, ch.published, 1)
+	assert.Equal(t, "price-alerts.dlq", ch.published[0].key)
+	assert.Empty(t, ch.published[0].msg.Expiration)
+	assert.Equal(t, int32(3), ch.published[0].msg.Headers[retryCountHeader])
+	assert.Equal(t, 1, ack.acked)
+	assert.Equal(t, before+1, testutil.ToFloat64(deadLettered))
+}
+
+func TestWorker_HandleFailure_PermanentErrorGoesStraightToDLQ(t *testing.T) {
+	worker := NewWorker(nil, nil, DefaultWorkerConfig())
+	ch := &fakeChannel{}
+	ack := &fakeAcknowledger{}
+	deadLettered := metrics.MessagesDeadLettered.WithLabelValues("price-alerts", "invalid_payload")
+	before := testutil.ToFloat64(deadLettered)
+
+	err := (&Handler{}).Handle(context.Background(), []byte(`{"invalid json}`))
+	worker.handleFailure(context.Background(), ch, "price-alerts", newTestDelivery(ack, 0), err)
+
+	require.Len(t, ch.published, 1)
+	assert.Equal(t, "price-alerts.dlq", ch.published[0].key)
+	assert.Equal(t, 1, ack.acked)
+	assert.Equal(t, before+1, testutil.ToFloat64(deadLettered))
+}
+
+func TestWorker_HandleFailure_PermanentDeliveryGoesStraightToDLQ(t *testing.T) {
+	worker := NewWorker(nil, nil, DefaultWorkerConfig())
+	ch := &fakeChannel{}
+	ack := &fakeAcknowledger{}
+	deadLettered := metrics.MessagesDeadLettered.WithLabelValues("price-alerts", "permanent_failure")
+	before := testutil.ToFloat64(deadLettered)
+
+	err := fmt.Errorf("%w: smtp recipient rejected: 550 no such user", domain.ErrPermanentDelivery)
+	worker.handleFailure(context.Background(), ch, "price-alerts", newTestDelivery(ack, 0), err)
+
+	require.Len(t, ch.published, 1)
+	assert.Equal(t, "price-alerts.dlq", ch.published[0].key)
+	assert.Equal(t, int32(0), ch.published[0].msg.Headers[retryCountHeader])
+	assert.Equal(t, 1, ack.acked)
+	assert.Equal(t, before+1, testutil.ToFloat64(deadLettered))
+}
+
+func TestWorker_HandleFailure_InProgressDelaysWithoutConsumingAttempt(t *testing.T) {
+	worker := NewWorker(nil, nil, DefaultWorkerConfig())
+	ch := &fakeChannel{}
+	ack := &fakeAcknowledger{}
+
+	worker.handleFailure(context.Background(), ch, "price-alerts", newTestDelivery(ack, 1), ErrMessageInProgress)
+
+	require.Len(t, ch.published, 1)
+	assert.Equal(t, "price-alerts.retry.10s", ch.published[0].key)
+	assert.Equal(t, int32(1), ch.published[0].msg.Headers[retryCountHeader])
+}
+
+func TestWorker_HandleFailure_PublishErrorRequeues(t *testing.T) {
+	worker := NewWorker(nil, nil, DefaultWorkerConfig())
+	ch := &fakeChannel{publishErr: errors.New("channel closed")}
+	ack := &fakeAcknowledger{}
+
+	worker.handleFailure(context.Background(), ch, "price-alerts", newTestDelivery(ack, 0), errors.New("smtp down"))
+
+	assert.Equal(t, 0, ack.acked)
+	assert.Equal(t, 1, ack.nacked)
+	assert.True(t, ack.requeued)
+}
+
+func TestRetryCount(t *testing.T) {
+	assert.Equal(t, 0, retryCount(nil))
+	assert.Equal(t, 2, retryCount(amqp.Table{retryCountHeader: int32(2)}))
+	assert.Equal(t, 3, retryCount(amqp.Table{retryCountHeader: int64(3)}))
+	assert.Equal(t, 4, retryCount(amqp.Table{retryCountHeader: "4"}))
+}
+
+func TestFormatDelay(t *testing.T) {
+	assert.Equal(t, "10s", formatDelay(10*time.Second))
+	assert.Equal(t, "1m", formatDelay(time.Minute))
+	assert.Equal(t, "2h", formatDelay(2*time.Hour))
+	assert.Equal(t, "1500ms", formatDelay(1500*time.Millisecond))
+}
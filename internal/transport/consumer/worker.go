@@ -1,49 +1,239 @@
 package consumer
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/Luzin7/alert-service/internal/infra/metrics"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+var (
+	ErrDeliveriesClosed = errors.New("canal de entregas fechado pelo broker")
+	ErrDrainTimeout     = errors.New("tempo esgotado aguardando mensagens em processamento")
+)
+
+type MessageHandler interface {
+	Handle(ctx context.Context, msgBody []byte) error
+}
+
+type ChannelProvider interface {
+	Channel(ctx context.Context) (*amqp.Channel, error)
+}
+
+type WorkerConfig struct {
+	RetryDelays      []time.Duration
+	HandlerTimeout   time.Duration
+	ShutdownTimeout  time.Duration
+	ResubscribeDelay time.Duration
+	Concurrency      int
+	Prefetch         int
+	// OrderByAlert routes every delivery of the same alertId to the same
+	// goroutine, so updates for one alert are processed in arrival order.
+	OrderByAlert bool
+}
+
+func DefaultWorkerConfig() WorkerConfig {
+	return WorkerConfig{
+		RetryDelays:      DefaultRetryDelays,
+		HandlerTimeout:   30 * time.Second,
+		ShutdownTimeout:  20 * time.Second,
+		ResubscribeDelay: time.Second,
+		Concurrency:      10,
+		Prefetch:         20,
+	}
+}
+
 type Worker struct {
-	conn    *amqp.Connection
-	handler *Handler
+	conn    ChannelProvider
+	handler MessageHandler
+	config  WorkerConfig
+
+	subscribed atomic.Bool
 }
 
-func NewWorker(conn *amqp.Connection, handler *Handler) *Worker {
+func NewWorker(conn ChannelProvider, handler MessageHandler, config WorkerConfig) *Worker {
 	return &Worker{
 		conn:    conn,
 		handler: handler,
+		config:  config,
+	}
+}
+
+// Subscribed reports whether the worker currently holds an active consumer.
+func (w *Worker) Subscribed() bool {
+	return w.subscribed.Load()
+}
+
+// Start consumes queueName until ctx is cancelled, re-subscribing whenever
+// the channel or the underlying connection is lost. After cancellation it
+// only reports a drain that timed out.
+func (w *Worker) Start(ctx context.Context, queueName string) error {
+	for {
+		err := w.subscribe(ctx, queueName)
+		if ctx.Err() != nil {
+			if errors.Is(err, ErrDrainTimeout) {
+				return err
+			}
+			return nil
+		}
+
+		log.Printf("Consumo da fila %s interrompido, reassinando: %v", queueName, err)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(w.config.ResubscribeDelay):
+		}
 	}
 }
 
-func (w *Worker) Start(queueName string) {
-	ch, _ := w.conn.Channel()
+func (w *Worker) subscribe(ctx context.Context, queueName string) error {
+	ch, err := w.conn.Channel(ctx)
+	if err != nil {
+		return fmt.Errorf("abrindo canal: %w", err)
+	}
 	defer ch.Close()
 
-	msgs, _ := ch.Consume(
-		queueName, "", false, false, false, false, nil,
+	if err := declareTopology(ch, queueName, w.config.RetryDelays); err != nil {
+		return err
+	}
+
+	if err := ch.Qos(w.config.Prefetch, 0, false); err != nil {
+		return fmt.Errorf("configurando prefetch: %w", err)
+	}
+
+	consumerTag := fmt.Sprintf("alert-service-%d", os.Getpid())
+	msgs, err := ch.Consume(
+		queueName, consumerTag, false, false, false, false, nil,
 	)
+	if err != nil {
+		return fmt.Errorf("consumindo fila %s: %w", queueName, err)
+	}
 
-	forever := make(chan struct{})
+	w.subscribed.Store(true)
+	defer w.subscribed.Store(false)
 
-	go func() {
-		for d := range msgs {
-			log.Printf("Recebi msg: %s", d.MessageId)
+	log.Printf("Esperando mensagens na fila %s...", queueName)
+
+	return w.consume(ctx, ch, queueName, consumerTag, msgs)
+}
 
-			err := w.handler.Handle(d.Body)
+func (w *Worker) consume(ctx context.Context, ch consumerChannel, queueName, consumerTag string, msgs <-chan amqp.Delivery) error {
+	// In-flight messages must outlive the shutdown signal so they can be
+	// drained; they are only cancelled once the drain deadline expires.
+	processCtx, cancelProcessing := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelProcessing()
 
-			if err != nil {
-				log.Printf("Erro processando: %v", err)
-				d.Nack(false, false)
-			} else {
-				d.Ack(false)
+	concurrency := max(w.config.Concurrency, 1)
+	lanes := make([]chan amqp.Delivery, 1)
+	if w.config.OrderByAlert {
+		lanes = make([]chan amqp.Delivery, concurrency)
+	}
+	for i := range lanes {
+		lanes[i] = make(chan amqp.Delivery)
+	}
+	closeLanes := func() {
+		for _, lane := range lanes {
+			close(lane)
+		}
+	}
+
+	var inFlight sync.WaitGroup
+	for i := range concurrency {
+		inFlight.Add(1)
+		go func(lane <-chan amqp.Delivery) {
+			defer inFlight.Done()
+			for d := range lane {
+				w.process(processCtx, ch, queueName, d)
+			}
+		}(lanes[i%len(lanes)])
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			closeLanes()
+			return w.drain(ch, consumerTag, &inFlight, cancelProcessing)
+		case d, ok := <-msgs:
+			if !ok {
+				closeLanes()
+				inFlight.Wait()
+				return ErrDeliveriesClosed
+			}
+
+			select {
+			case lanes[laneFor(d, len(lanes))] <- d:
+			case <-ctx.Done():
+				closeLanes()
+				return w.drain(ch, consumerTag, &inFlight, cancelProcessing)
 			}
 		}
-	}()
+	}
+}
 
-	log.Printf("Esperando mensagens na fila %s...", queueName)
+func laneFor(d amqp.Delivery, lanes int) int {
+	if lanes == 1 {
+		return 0
+	}
+
+	var key struct {
+		AlertID int64 `json:"alertId"`
+	}
+	if err := json.Unmarshal(d.Body, &key); err != nil {
+		return 0
+	}
+	return int(uint64(key.AlertID) % uint64(lanes))
+}
+
+func (w *Worker) process(ctx context.Context, ch amqpChannel, queueName string, d amqp.Delivery) {
+	log.Printf("Recebi msg: %s", d.MessageId)
+	metrics.MessagesConsumed.WithLabelValues(queueName).Inc()
+
+	msgCtx, cancel := context.WithTimeout(ctx, w.config.HandlerTimeout)
+	defer cancel()
+
+	err := w.handler.Handle(msgCtx, d.Body)
+
+	switch {
+	case err != nil && ctx.Err() != nil:
+		log.Printf("Processamento de %s interrompido no desligamento, devolvendo para a fila", d.MessageId)
+		d.Nack(false, true)
+		metrics.MessagesNacked.WithLabelValues(queueName, "true").Inc()
+	case err != nil:
+		w.handleFailure(ctx, ch, queueName, d, err)
+	default:
+		d.Ack(false)
+		metrics.MessagesAcked.WithLabelValues(queueName).Inc()
+	}
+}
+
+func (w *Worker) drain(ch consumerChannel, consumerTag string, inFlight *sync.WaitGroup, cancelProcessing context.CancelFunc) error {
+	log.Printf("Encerrando consumo, aguardando mensagens em processamento...")
+
+	if err := ch.Cancel(consumerTag, false); err != nil {
+		log.Printf("Erro cancelando consumidor %s: %v", consumerTag, err)
+	}
 
-	<-forever
+	done := make(chan struct{})
+	go func() {
+		inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(w.config.ShutdownTimeout):
+		cancelProcessing()
+		<-done
+		return ErrDrainTimeout
+	}
 }
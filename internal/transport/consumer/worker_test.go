@@ -0,0 +1,281 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type handlerFunc func(ctx context.Context, msgBody []byte) error
+
+func (f handlerFunc) Handle(ctx context.Context, msgBody []byte) error {
+	return f(ctx, msgBody)
+}
+
+func newTestWorker(handler MessageHandler) *Worker {
+	config := DefaultWorkerConfig()
+	config.HandlerTimeout = time.Second
+	config.ShutdownTimeout = 200 * time.Millisecond
+	return NewWorker(nil, handler, config)
+}
+
+func TestWorker_Consume_AcksAndStopsOnCancel(t *testing.T) {
+	processed := make(chan []byte, 1)
+	worker := newTestWorker(handlerFunc(func(ctx context.Context, msgBody []byte) error {
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline)
+		processed <- msgBody
+		return nil
+	}))
+
+	ch := &fakeChannel{}
+	ack := &fakeAcknowledger{}
+	msgs := make(chan amqp.Delivery, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	result := make(chan error, 1)
+	go func() {
+		result <- worker.consume(ctx, ch, "price-alerts", "tag-1", msgs)
+	}()
+
+	msgs <- newTestDelivery(ack, 0)
+	assert.Equal(t, []byte(`{"messageId":"msg-123"}`), <-processed)
+
+	cancel()
+
+	require.NoError(t, <-result)
+	assert.Equal(t, []string{"tag-1"}, ch.cancelled)
+	assert.Equal(t, 1, ack.acked)
+}
+
+func TestWorker_Consume_DrainsInFlightMessage(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	worker := newTestWorker(handlerFunc(func(ctx context.Context, msgBody []byte) error {
+		close(started)
+		<-release
+		return ctx.Err()
+	}))
+
+	ch := &fakeChannel{}
+	ack := &fakeAcknowledger{}
+	msgs := make(chan amqp.Delivery, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	result := make(chan error, 1)
+	go func() {
+		result <- worker.consume(ctx, ch, "price-alerts", "tag-1", msgs)
+	}()
+
+	msgs <- newTestDelivery(ack, 0)
+	<-started
+	cancel()
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	require.NoError(t, <-result)
+	assert.Equal(t, 1, ack.acked)
+	assert.Empty(t, ch.published)
+}
+
+func TestWorker_Consume_DrainTimeoutCancelsInFlight(t *testing.T) {
+	started := make(chan struct{})
+	worker := newTestWorker(handlerFunc(func(ctx context.Context, msgBody []byte) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+
+	ch := &fakeChannel{}
+	ack := &fakeAcknowledger{}
+	msgs := make(chan amqp.Delivery, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	result := make(chan error, 1)
+	go func() {
+		result <- worker.consume(ctx, ch, "price-alerts", "tag-1", msgs)
+	}()
+
+	msgs <- newTestDelivery(ack, 0)
+	<-started
+	cancel()
+
+	assert.ErrorIs(t, <-result, ErrDrainTimeout)
+	assert.Equal(t, 0, ack.acked)
+	assert.Equal(t, 1, ack.nacked)
+	assert.True(t, ack.requeued)
+	assert.Empty(t, ch.published)
+}
+
+func TestWorker_Consume_DeliveriesClosed(t *testing.T) {
+	worker := newTestWorker(handlerFunc(func(ctx context.Context, msgBody []byte) error {
+		return nil
+	}))
+
+	msgs := make(chan amqp.Delivery)
+	close(msgs)
+
+	err := worker.consume(context.Background(), &fakeChannel{}, "price-alerts", "tag-1", msgs)
+
+	assert.ErrorIs(t, err, ErrDeliveriesClosed)
+}
+
+func newAlertDelivery(ack *fakeAcknowledger, alertID int64, seq int) amqp.Delivery {
+	return amqp.Delivery{
+		Acknowledger: ack,
+		MessageId:    fmt.Sprintf("msg-%d-%d", alertID, seq),
+		Body:         []byte(fmt.Sprintf(`{"alertId":%d,"seq":%d}`, alertID, seq)),
+	}
+}
+
+func TestWorker_Consume_ProcessesConcurrently(t *testing.T) {
+	const concurrency = 3
+	started := make(chan struct{}, concurrency)
+	release := make(chan struct{})
+	worker := newTestWorker(handlerFunc(func(ctx context.Context, msgBody []byte) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}))
+	worker.config.Concurrency = concurrency
+
+	ack := &fakeAcknowledger{}
+	msgs := make(chan amqp.Delivery, concurrency)
+	for i := range concurrency {
+		msgs <- newAlertDelivery(ack, int64(i), 0)
+	}
+	close(msgs)
+
+	result := make(chan error, 1)
+	go func() {
+		result <- worker.consume(context.Background(), &fakeChannel{}, "price-alerts", "tag-1", msgs)
+	}()
+
+	for range concurrency {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("deliveries were not processed concurrently")
+		}
+	}
+	close(release)
+
+	assert.ErrorIs(t, <-result, ErrDeliveriesClosed)
+	assert.Equal(t, concurrency, ack.acked)
+}
+
+func TestWorker_Consume_OrderByAlert(t *testing.T) {
+	const alerts, updates = 4, 25
+
+	var mu sync.Mutex
+	seen := make(map[int64][]int)
+	worker := newTestWorker(handlerFunc(func(ctx context.Context, msgBody []byte) error {
+		var msg struct {
+			AlertID int64 `json:"alertId"`
+			Seq     int   `json:"seq"`
+		}
+		require.NoError(t, json.Unmarshal(msgBody, &msg))
+		time.Sleep(time.Duration(rand.IntN(200)) * time.Microsecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+		seen[msg.AlertID] = append(seen[msg.AlertID], msg.Seq)
+		return nil
+	}))
+	worker.config.Concurrency = 8
+	worker.config.OrderByAlert = true
+
+	ack := &fakeAcknowledger{}
+	msgs := make(chan amqp.Delivery, alerts*updates)
+	for seq := range updates {
+		for alertID := range alerts {
+			msgs <- newAlertDelivery(ack, int64(alertID+1), seq)
+		}
+	}
+	close(msgs)
+
+	err := worker.consume(context.Background(), &fakeChannel{}, "price-alerts", "tag-1", msgs)
+
+	assert.ErrorIs(t, err, ErrDeliveriesClosed)
+	assert.Equal(t, alerts*updates, ack.acked)
+	for alertID, order := range seen {
+		assert.IsIncreasing(t, order, "alert %d processed out of order", alertID)
+		assert.Len(t, order, updates)
+	}
+}
+
+func TestLaneFor(t *testing.T) {
+	ack := &fakeAcknowledger{}
+
+	assert.Equal(t, 0, laneFor(newAlertDelivery(ack, 7, 0), 1))
+	assert.Equal(t, laneFor(newAlertDelivery(ack, 7, 0), 4), laneFor(newAlertDelivery(ack, 7, 1), 4))
+	assert.Equal(t, 3, laneFor(newAlertDelivery(ack, 7, 0), 4))
+	assert.Equal(t, 0, laneFor(amqp.Delivery{Body: []byte("not json")}, 4))
+}
+
+func BenchmarkWorker_Consume(b *testing.B) {
+	log.SetOutput(io.Discard)
+	b.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	for _, concurrency := range []int{1, 4, 16} {
+		for _, ordered := range []bool{false, true} {
+			b.Run(fmt.Sprintf("concurrency=%d/ordered=%t", concurrency, ordered), func(b *testing.B) {
+				worker := newTestWorker(handlerFunc(func(ctx context.Context, msgBody []byte) error {
+					time.Sleep(100 * time.Microsecond)
+					return nil
+				}))
+				worker.config.Concurrency = concurrency
+				worker.config.OrderByAlert = ordered
+
+				ack := &fakeAcknowledger{}
+				msgs := make(chan amqp.Delivery, 64)
+				go func() {
+					for i := range b.N {
+						msgs <- newAlertDelivery(ack, int64(i%64), i)
+					}
+					close(msgs)
+				}()
+
+				b.ResetTimer()
+				err := worker.consume(context.Background(), &fakeChannel{}, "price-alerts", "tag-1", msgs)
+				b.StopTimer()
+
+				require.ErrorIs(b, err, ErrDeliveriesClosed)
+				require.Equal(b, b.N, ack.acked)
+				b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "msgs/s")
+			})
+		}
+	}
+}
+
+type blockingProvider struct{}
+
+func (blockingProvider) Channel(ctx context.Context) (*amqp.Channel, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestWorker_Start_CancelledWhileConnecting(t *testing.T) {
+	worker := NewWorker(blockingProvider{}, nil, DefaultWorkerConfig())
+	ctx, cancel := context.WithCancel(context.Background())
+
+	result := make(chan error, 1)
+	go func() {
+		result <- worker.Start(ctx, "price-alerts")
+	}()
+	cancel()
+
+	assert.NoError(t, <-result)
+}
@@ -0,0 +1,162 @@
+package http
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// DetailsReporter is implemented by checkers that add context, such as pool
+// usage, to their result.
+type DetailsReporter interface {
+	Details() map[string]any
+}
+
+type CheckFunc func(ctx context.Context) error
+
+func (f CheckFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+const (
+	// DefaultCachedCheckTTL is how long a CachedChecker reuses a result.
+	DefaultCachedCheckTTL = 30 * time.Second
+	// DefaultCachedCheckTimeout bounds a CachedChecker probe. It is longer
+	// than the /readyz check timeout, since the probe runs in the background.
+	DefaultCachedCheckTimeout = 10 * time.Second
+)
+
+// CachedChecker reuses the last result of a checker for a TTL, for
+// dependencies too expensive to probe on every /readyz, such as an SMTP
+// session with its TLS handshake. Once the result is stale it probes again in
+// the background, with its own timeout, and keeps answering with the last
+// result meanwhile; only the very first check waits for the probe. Failures
+// are cached as well, so a down server is not hammered by probes either.
+type CachedChecker struct {
+	checker Checker
+	ttl     time.Duration
+	timeout time.Duration
+	now     func() time.Time
+
+	mu        sync.Mutex
+	err       error
+	checkedAt time.Time
+	checked   bool
+	probing   chan struct{}
+}
+
+func NewCachedChecker(checker Checker, ttl, timeout time.Duration) *CachedChecker {
+	return &CachedChecker{checker: checker, ttl: ttl, timeout: timeout, now: time.Now}
+}
+
+func (c *CachedChecker) Check(ctx context.Context) error {
+	c.mu.Lock()
+	if c.checked && c.now().Sub(c.checkedAt) < c.ttl {
+		defer c.mu.Unlock()
+		return c.err
+	}
+	if c.probing == nil {
+		c.probing = make(chan struct{})
+		go c.probe(c.probing)
+	}
+	probing, checked, err := c.probing, c.checked, c.err
+	c.mu.Unlock()
+
+	if checked {
+		return err
+	}
+	select {
+	case <-probing:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *CachedChecker) probe(done chan struct{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	err := c.checker.Check(ctx)
+
+	c.mu.Lock()
+	c.err, c.checkedAt, c.checked, c.probing = err, c.now(), true, nil
+	c.mu.Unlock()
+	close(done)
+}
+
+// Check is a dependency probed by /readyz. An Optional check is reported
+// but never makes the service unready, for dependencies whose outage the
+// worker rides out, such as SMTP: messages are retried later, and the pod
+// must keep serving redirects meanwhile.
+type Check struct {
+	Name     string
+	Checker  Checker
+	Optional bool
+}
+
+type CheckResult struct {
+	Status    string         `json:"status"`
+	LatencyMs float64        `json:"latencyMs"`
+	Error     string         `json:"error,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+	Optional  bool           `json:"optional,omitempty"`
+}
+
+type HealthReport struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// runChecks runs every check concurrently, each bounded by timeout, so one
+// hung dependency cannot delay the report beyond that.
+func runChecks(ctx context.Context, checks []Check, timeout time.Duration) HealthReport {
+	report := HealthReport{Status: StatusOK, Checks: make(map[string]CheckResult, len(checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check.Checker.Check(checkCtx)
+			result := CheckResult{
+				Status:    StatusOK,
+				LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+				Optional:  check.Optional,
+			}
+			if err != nil {
+				result.Status = StatusFail
+				result.Error = err.Error()
+			}
+			if reporter, ok := check.Checker.(DetailsReporter); ok {
+				result.Details = reporter.Details()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[check.Name] = result
+			if err != nil && !check.Optional {
+				report.Status = StatusFail
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	return report
+}
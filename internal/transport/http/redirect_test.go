@@ -0,0 +1,86 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+type redirectorFunc func(ctx context.Context, token, userAgent string) (string, error)
+
+func (f redirectorFunc) Resolve(ctx context.Context, token, userAgent string) (string, error) {
+	return f(ctx, token, userAgent)
+}
+
+func TestServer_Redirect(t *testing.T) {
+	var gotToken, gotUserAgent string
+	server := newTestPublicServer(redirectorFunc(func(ctx context.Context, token, userAgent string) (string, error) {
+		gotToken, gotUserAgent = token, userAgent
+		return "https://www.kayak.com/flights/GRU-JFK/2025-12-15?utm_source=alert-service", nil
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/r/abc123", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "https://www.kayak.com/flights/GRU-JFK/2025-12-15?utm_source=alert-service", rec.Header().Get("Location"))
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, "abc123", gotToken)
+	assert.Equal(t, "Mozilla/5.0", gotUserAgent)
+}
+
+func TestServer_Redirect_Errors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "unknown token", err: domain.ErrTrackedLinkNotFound, expected: http.StatusNotFound},
+		{name: "storage failure", err: errors.New("database down"), expected: http.StatusServiceUnavailable},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestPublicServer(redirectorFunc(func(ctx context.Context, token, userAgent string) (string, error) {
+				return "", tc.err
+			}))
+
+			rec := httptest.NewRecorder()
+			server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/r/forged", nil))
+
+			assert.Equal(t, tc.expected, rec.Code)
+			assert.Empty(t, rec.Header().Get("Location"))
+		})
+	}
+}
+
+func TestServer_Redirect_NotOnOpsServer(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestServer().Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/r/abc123", nil))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestPublicServer_NoOpsEndpoints(t *testing.T) {
+	server := newTestPublicServer(redirectorFunc(func(ctx context.Context, token, userAgent string) (string, error) {
+		return "https://www.kayak.com", nil
+	}))
+
+	for _, path := range []string{"/metrics", "/readyz", "/livez", "/health"} {
+		rec := httptest.NewRecorder()
+		server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code, path)
+	}
+}
+
+func newTestPublicServer(redirector Redirector) *Server {
+	return NewPublicServer("127.0.0.1:0", DefaultServerConfig(), redirector)
+}
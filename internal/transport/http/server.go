@@ -1,16 +1,142 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"log"
+	"net"
 	"net/http"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/Luzin7/alert-service/internal/infra/metrics"
 )
 
-func Start() {
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+type ServerConfig struct {
+	CheckTimeout    time.Duration
+	ShutdownTimeout time.Duration
+}
+
+func DefaultServerConfig() ServerConfig {
+	return ServerConfig{
+		CheckTimeout:    2 * time.Second,
+		ShutdownTimeout: 5 * time.Second,
+	}
+}
+
+type Redirector interface {
+	Resolve(ctx context.Context, token, userAgent string) (string, error)
+}
+
+type Server struct {
+	server *http.Server
+	mux    *http.ServeMux
+	checks []Check
+	config ServerConfig
+}
+
+// NewServer serves the operational endpoints: health checks and metrics. It
+// is meant for the internal network and never carries public traffic.
+func NewServer(addr string, config ServerConfig, checks ...Check) *Server {
+	s := newServer(addr, config)
+	s.checks = checks
+
+	s.mux.HandleFunc("GET /livez", s.livez)
+	s.mux.HandleFunc("GET /health", s.livez)
+	s.mux.HandleFunc("GET /readyz", s.readyz)
+	s.mux.Handle("GET /metrics", metrics.Handler())
+	return s
+}
+
+// NewPublicServer serves only the click-tracking redirects at /r/{token}, on
+// a listener of its own so that exposing it does not expose the operational
+// endpoints.
+func NewPublicServer(addr string, config ServerConfig, redirector Redirector) *Server {
+	s := newServer(addr, config)
+	s.mux.HandleFunc("GET /r/{token}", func(w http.ResponseWriter, r *http.Request) {
+		target, err := redirector.Resolve(r.Context(), r.PathValue("token"), r.UserAgent())
+		switch {
+		case errors.Is(err, domain.ErrTrackedLinkNotFound):
+			http.NotFound(w, r)
+		case err != nil:
+			log.Printf("failed to resolve redirect: %v", err)
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		default:
+			w.Header().Set("Cache-Control", "no-store")
+			w.Header().Set("Referrer-Policy", "no-referrer")
+			http.Redirect(w, r, target, http.StatusFound)
+		}
 	})
+	return s
+}
+
+func newServer(addr string, config ServerConfig) *Server {
+	mux := http.NewServeMux()
+	return &Server{
+		mux:    mux,
+		config: config,
+		server: &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+	}
+}
+
+func (s *Server) Handler() http.Handler {
+	return s.server.Handler
+}
+
+// Start serves until ctx is cancelled and then shuts down gracefully. It
+// returns early only if the listener fails.
+func (s *Server) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, listener)
+}
+
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.server.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.config.ShutdownTimeout)
+	defer cancel()
 
-	go http.ListenAndServe(":8080", nil)
+	if err := s.server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) livez(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, HealthReport{Status: StatusOK})
+}
+
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, runChecks(r.Context(), s.checks, s.config.CheckTimeout))
+}
+
+func writeReport(w http.ResponseWriter, report HealthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status == StatusOK {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
 }
@@ -0,0 +1,215 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/infra/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(checks ...Check) *Server {
+	config := DefaultServerConfig()
+	config.CheckTimeout = 50 * time.Millisecond
+	return NewServer("127.0.0.1:0", config, checks...)
+}
+
+func get(t *testing.T, server *Server, path string) (*httptest.ResponseRecorder, HealthReport) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var report HealthReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	return rec, report
+}
+
+func TestServer_Livez(t *testing.T) {
+	server := newTestServer(Check{Name: "postgres", Checker: CheckFunc(func(ctx context.Context) error {
+		return errors.New("down")
+	})})
+
+	for _, path := range []string{"/livez", "/health"} {
+		rec, report := get(t, server, path)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.Equal(t, StatusOK, report.Status)
+		assert.Empty(t, report.Checks)
+	}
+}
+
+func TestServer_Readyz_AllHealthy(t *testing.T) {
+	ok := CheckFunc(func(ctx context.Context) error { return nil })
+	server := newTestServer(Check{Name: "postgres", Checker: ok}, Check{Name: "redis", Checker: ok})
+
+	rec, report := get(t, server, "/readyz")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, StatusOK, report.Status)
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, StatusOK, report.Checks["postgres"].Status)
+	assert.Empty(t, report.Checks["redis"].Error)
+}
+
+func TestServer_Readyz_FailingCheck(t *testing.T) {
+	server := newTestServer(
+		Check{Name: "postgres", Checker: CheckFunc(func(ctx context.Context) error { return nil })},
+		Check{Name: "smtp", Checker: CheckFunc(func(ctx context.Context) error {
+			return errors.New("connection refused")
+		})},
+	)
+
+	rec, report := get(t, server, "/readyz")
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, StatusOK, report.Checks["postgres"].Status)
+	assert.Equal(t, StatusFail, report.Checks["smtp"].Status)
+	assert.Equal(t, "connection refused", report.Checks["smtp"].Error)
+}
+
+func TestServer_Readyz_OptionalCheck(t *testing.T) {
+	server := newTestServer(
+		Check{Name: "postgres", Checker: CheckFunc(func(ctx context.Context) error { return nil })},
+		Check{Name: "smtp", Optional: true, Checker: CheckFunc(func(ctx context.Context) error {
+			return errors.New("connection refused")
+		})},
+	)
+
+	rec, report := get(t, server, "/readyz")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, StatusOK, report.Status)
+	assert.Equal(t, StatusFail, report.Checks["smtp"].Status)
+	assert.True(t, report.Checks["smtp"].Optional)
+	assert.False(t, report.Checks["postgres"].Optional)
+}
+
+func TestCachedChecker(t *testing.T) {
+	var mu sync.Mutex
+	now := time.Date(2025, 12, 2, 12, 0, 0, 0, time.UTC)
+	var probes atomic.Int32
+	results := make(chan error, 1)
+	checker := NewCachedChecker(CheckFunc(func(ctx context.Context) error {
+		probes.Add(1)
+		return <-results
+	}), 30*time.Second, time.Minute)
+	checker.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(d)
+	}
+
+	refused := errors.New("connection refused")
+	results <- refused
+	assert.Equal(t, refused, checker.Check(context.Background()), "the first check waits for the probe")
+
+	advance(29 * time.Second)
+	assert.Equal(t, refused, checker.Check(context.Background()), "failures are cached too")
+	assert.Equal(t, int32(1), probes.Load())
+
+	advance(time.Second)
+	assert.Equal(t, refused, checker.Check(context.Background()), "a stale result is served while probing")
+	results <- nil
+	assert.Eventually(t, func() bool {
+		return checker.Check(context.Background()) == nil
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(2), probes.Load())
+}
+
+func TestCachedChecker_FirstCheckBoundedByContext(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	checker := NewCachedChecker(CheckFunc(func(ctx context.Context) error {
+		<-release
+		return nil
+	}), time.Minute, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, checker.Check(ctx), context.DeadlineExceeded)
+}
+
+func TestServer_Readyz_CheckTimeout(t *testing.T) {
+	server := newTestServer(Check{Name: "amqp", Checker: CheckFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})})
+
+	start := time.Now()
+	rec, report := get(t, server, "/readyz")
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["amqp"].Error)
+	assert.GreaterOrEqual(t, report.Checks["amqp"].LatencyMs, float64(50))
+}
+
+type poolChecker struct{}
+
+func (poolChecker) Check(ctx context.Context) error { return nil }
+
+func (poolChecker) Details() map[string]any {
+	return map[string]any{"acquiredConns": 3, "maxConns": 10}
+}
+
+func TestServer_Readyz_Details(t *testing.T) {
+	server := newTestServer(Check{Name: "postgres", Checker: poolChecker{}})
+
+	rec, report := get(t, server, "/readyz")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, map[string]any{"acquiredConns": float64(3), "maxConns": float64(10)}, report.Checks["postgres"].Details)
+}
+
+func TestServer_Serve_GracefulShutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := newTestServer()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	result := make(chan error, 1)
+	go func() {
+		result <- server.Serve(ctx, listener)
+	}()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/livez")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	cancel()
+
+	assert.NoError(t, <-result)
+	_, err = http.Get("http://" + listener.Addr().String() + "/livez")
+	assert.Error(t, err)
+}
+
+func TestServer_Metrics(t *testing.T) {
+	metrics.MessagesConsumed.WithLabelValues("price-alerts").Inc()
+
+	rec := httptest.NewRecorder()
+	newTestServer().Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `alert_service_messages_consumed_total{queue="price-alerts"}`)
+	assert.Contains(t, rec.Body.String(), "go_goroutines")
+}
@@ -0,0 +1,131 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/Luzin7/alert-service/internal/infra/metrics"
+)
+
+const (
+	DefaultScheduledLease       = 5 * time.Minute
+	DefaultScheduledMaxAttempts = 5
+)
+
+// DeliverScheduled processes again the alerts deferred by quiet hours once
+// they are due. Each alert goes through ProcessAlert from the start, so the
+// recipient's current preferences apply. Several workers may run it at once;
+// a worker that stops mid-delivery lets its lease expire and the alert is
+// delivered by the next run.
+type DeliverScheduled struct {
+	scheduler   domain.AlertScheduler
+	processor   *ProcessAlert
+	lease       time.Duration
+	maxAttempts int
+}
+
+type ScheduledOption func(*DeliverScheduled)
+
+// WithScheduledLease sets how long a claimed alert stays hidden from other
+// workers. It must be longer than processing one alert takes.
+func WithScheduledLease(lease time.Duration) ScheduledOption {
+	return func(u *DeliverScheduled) {
+		u.lease = lease
+	}
+}
+
+// WithScheduledMaxAttempts sets how many deliveries are tried before an
+// alert is marked failed.
+func WithScheduledMaxAttempts(attempts int) ScheduledOption {
+	return func(u *DeliverScheduled) {
+		u.maxAttempts = attempts
+	}
+}
+
+func NewDeliverScheduled(scheduler domain.AlertScheduler, processor *ProcessAlert, opts ...ScheduledOption) *DeliverScheduled {
+	u := &DeliverScheduled{
+		scheduler:   scheduler,
+		processor:   processor,
+		lease:       DefaultScheduledLease,
+		maxAttempts: DefaultScheduledMaxAttempts,
+	}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
+}
+
+// Start runs the due alerts every interval until ctx is canceled.
+func (u *DeliverScheduled) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := u.Run(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Erro entregando alertas adiados: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run processes every due alert, returning how many were delivered.
+func (u *DeliverScheduled) Run(ctx context.Context) (int, error) {
+	delivered := 0
+	for ctx.Err() == nil {
+		scheduled, err := u.scheduler.ClaimScheduledAlert(ctx, u.lease)
+		if errors.Is(err, domain.ErrNoScheduledAlertDue) {
+			return delivered, nil
+		}
+		if err != nil {
+			return delivered, err
+		}
+		if u.deliver(ctx, scheduled) {
+			delivered++
+		}
+	}
+	return delivered, ctx.Err()
+}
+
+func (u *DeliverScheduled) deliver(ctx context.Context, scheduled domain.ScheduledAlert) bool {
+	// The outcome is recorded even when shutdown cancels ctx mid-delivery.
+	finishCtx := context.WithoutCancel(ctx)
+
+	alert := scheduled.Alert
+	decision, err := u.processor.Execute(ctx, &alert)
+	switch {
+	case err != nil:
+		log.Printf("Erro entregando alerta adiado %d (tentativa %d de %d): %v", alert.ID, scheduled.Attempts, u.maxAttempts, err)
+		if scheduled.Attempts >= u.maxAttempts {
+			u.finish("failed", u.scheduler.MarkScheduledAlertFailed(finishCtx, scheduled, err.Error()))
+		} else {
+			u.finish("retry", u.scheduler.RetryScheduledAlert(finishCtx, scheduled, err.Error()))
+		}
+		return false
+	case decision.Skip == domain.SkipDeferred:
+		// Still in quiet hours, e.g. the window changed: ProcessAlert
+		// already moved the alert to the new end.
+		metrics.ScheduledAlertsProcessed.WithLabelValues("deferred").Inc()
+		return false
+	case !decision.Notify:
+		u.finish("skipped", u.scheduler.MarkScheduledAlertSkipped(finishCtx, scheduled, string(decision.Skip)))
+		return false
+	}
+
+	u.finish("sent", u.scheduler.MarkScheduledAlertSent(finishCtx, scheduled))
+	return true
+}
+
+func (u *DeliverScheduled) finish(outcome string, err error) {
+	metrics.ScheduledAlertsProcessed.WithLabelValues(outcome).Inc()
+	if err != nil {
+		log.Printf("Erro atualizando alerta adiado: %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func testScheduledAlert(attempts int) domain.ScheduledAlert {
+	return domain.ScheduledAlert{
+		ID:        7,
+		Alert:     domain.Alert{ID: 1, MessageID: "abc-123", OldPrice: 1500, NewPrice: 1200, Currency: "BRL"},
+		DeliverAt: time.Date(2025, 12, 2, 10, 0, 0, 0, time.UTC),
+		Attempts:  attempts,
+	}
+}
+
+type scheduledFixture struct {
+	scheduler *MockAlertScheduler
+	repo      *MockAlertRepository
+	linkGen   *MockLinkGenerator
+	renderer  *MockEmailRenderer
+	email     *MockNotifier
+	useCase   *DeliverScheduled
+}
+
+func newScheduledFixture(now time.Time, scheduled domain.ScheduledAlert) *scheduledFixture {
+	f := &scheduledFixture{
+		scheduler: new(MockAlertScheduler),
+		repo:      new(MockAlertRepository),
+		linkGen:   new(MockLinkGenerator),
+		renderer:  new(MockEmailRenderer),
+		email:     newMockNotifier(domain.ChannelEmail),
+	}
+	processor := NewProcessAlert(f.linkGen, f.repo, []domain.Notifier{f.email}, f.renderer, WithAlertScheduler(f.scheduler))
+	processor.now = func() time.Time { return now }
+	f.useCase = NewDeliverScheduled(f.scheduler, processor)
+
+	f.scheduler.On("ClaimScheduledAlert", mock.Anything, DefaultScheduledLease).Return(scheduled, nil).Once()
+	f.scheduler.On("ClaimScheduledAlert", mock.Anything, DefaultScheduledLease).Return(domain.ScheduledAlert{}, domain.ErrNoScheduledAlertDue).Once()
+	f.linkGen.On("Links", mock.Anything).Return(nil)
+	f.renderer.On("Render", mock.Anything, mock.Anything).Return(&domain.AlertEmail{}, nil)
+	return f
+}
+
+// 10:30 UTC is 07:30 in São Paulo, after the quiet hours.
+var afterQuietHours = time.Date(2025, 12, 2, 10, 30, 0, 0, time.UTC)
+
+func TestDeliverScheduled_Run(t *testing.T) {
+	scheduled := testScheduledAlert(1)
+	f := newScheduledFixture(afterQuietHours, scheduled)
+	f.repo.On("GetRecipient", mock.Anything, int64(1)).Return(quietRecipient(), nil)
+	f.email.On("Notify", mock.Anything, mock.Anything).Return("250 ok", nil)
+	f.scheduler.On("MarkScheduledAlertSent", mock.Anything, scheduled).Return(nil)
+
+	delivered, err := f.useCase.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	f.email.AssertExpectations(t)
+	f.scheduler.AssertExpectations(t)
+}
+
+func TestDeliverScheduled_Run_Skipped(t *testing.T) {
+	scheduled := testScheduledAlert(1)
+	f := newScheduledFixture(afterQuietHours, scheduled)
+	recipient := quietRecipient()
+	recipient.OptedOut = true
+	f.repo.On("GetRecipient", mock.Anything, int64(1)).Return(recipient, nil)
+	f.scheduler.On("MarkScheduledAlertSkipped", mock.Anything, scheduled, "opted_out").Return(nil)
+
+	delivered, err := f.useCase.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	f.scheduler.AssertExpectations(t)
+}
+
+func TestDeliverScheduled_Run_DeferredAgain(t *testing.T) {
+	scheduled := testScheduledAlert(1)
+	f := newScheduledFixture(afterQuietHours, scheduled)
+	recipient := quietRecipient()
+	recipient.QuietHours = domain.QuietHours{Start: 22 * 60, End: 9 * 60}
+	f.repo.On("GetRecipient", mock.Anything, int64(1)).Return(recipient, nil)
+	f.scheduler.On("ScheduleAlert", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	delivered, err := f.useCase.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	f.scheduler.AssertNotCalled(t, "MarkScheduledAlertSent", mock.Anything, mock.Anything)
+	f.scheduler.AssertNotCalled(t, "MarkScheduledAlertSkipped", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeliverScheduled_Run_DeliveryFailure(t *testing.T) {
+	testCases := []struct {
+		name     string
+		attempts int
+		expected string
+	}{
+		{name: "retried", attempts: 1, expected: "RetryScheduledAlert"},
+		{name: "last attempt", attempts: DefaultScheduledMaxAttempts, expected: "MarkScheduledAlertFailed"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheduled := testScheduledAlert(tc.attempts)
+			f := newScheduledFixture(afterQuietHours, scheduled)
+			f.repo.On("GetRecipient", mock.Anything, int64(1)).Return(quietRecipient(), nil)
+			f.email.On("Notify", mock.Anything, mock.Anything).Return("", errors.New("421 try later"))
+			f.scheduler.On(tc.expected, mock.Anything, scheduled, "421 try later").Return(nil)
+
+			delivered, err := f.useCase.Run(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, 0, delivered)
+			f.scheduler.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeliverScheduled_Run_ClaimError(t *testing.T) {
+	scheduler := new(MockAlertScheduler)
+	useCase := NewDeliverScheduled(scheduler, nil)
+	scheduler.On("ClaimScheduledAlert", mock.Anything, DefaultScheduledLease).Return(domain.ScheduledAlert{}, errors.New("connection refused"))
+
+	_, err := useCase.Run(context.Background())
+
+	assert.EqualError(t, err, "connection refused")
+}
@@ -2,46 +2,490 @@ package usecases
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"log"
+	"strings"
+	"time"
 
 	"github.com/Luzin7/alert-service/internal/domain"
-	"github.com/Luzin7/alert-service/internal/infra/smtp"
+	"github.com/Luzin7/alert-service/internal/infra/metrics"
 )
 
 type ProcessAlert struct {
-	linkGen domain.LinkGenerator
-	repo    domain.AlertRepository
-	sender  *smtp.Connection
+	linkGen       domain.BookingLinkGenerator
+	repo          domain.AlertRepository
+	notifiers     map[domain.Channel]domain.Notifier
+	renderer      domain.EmailRenderer
+	rules         domain.NotificationRules
+	userLinks     bool
+	tracker       domain.LinkTracker
+	notifications domain.NotificationLog
+	digests       domain.DigestStore
+	cooldown      domain.CooldownStore
+	scheduler     domain.AlertScheduler
+	history       domain.PriceHistoryStore
+	trendDays     int
+	rates         domain.ExchangeRateProvider
+	now           func() time.Time
 }
 
-func NewProcessAlert(linkGen domain.LinkGenerator, repo domain.AlertRepository, sender *smtp.Connection) *ProcessAlert {
-	return &ProcessAlert{
-		linkGen: linkGen,
-		repo:    repo,
-		sender:  sender,
+// DefaultPriceTrendDays is the window of the price trend shown in emails.
+const DefaultPriceTrendDays = 30
+
+type Option func(*ProcessAlert)
+
+func WithNotificationRules(rules domain.NotificationRules) Option {
+	return func(u *ProcessAlert) {
+		u.rules = rules
 	}
 }
 
-func (u *ProcessAlert) Execute(ctx context.Context, alert *domain.Alert) error {
-	link := u.linkGen.Generate(alert.Origin, alert.Destination, alert.OutboundDate, alert.ReturnDate)
+// WithUserLinkProviders lets each user choose the booking sites in their
+// email through Recipient.LinkProviders; without it, or when the user chose
+// none, the generator defaults apply.
+func WithUserLinkProviders() Option {
+	return func(u *ProcessAlert) {
+		u.userLinks = true
+	}
+}
 
-	alert.Link = link
+// WithLinkTracker replaces each booking link with a tracked redirect. Links
+// that cannot be tracked are sent as they are.
+func WithLinkTracker(tracker domain.LinkTracker) Option {
+	return func(u *ProcessAlert) {
+		u.tracker = tracker
+	}
+}
+
+// WithNotificationLog records each delivery attempt and its outcome, one per
+// channel. Failures to write the log are logged and never block delivery.
+func WithNotificationLog(notifications domain.NotificationLog) Option {
+	return func(u *ProcessAlert) {
+		u.notifications = notifications
+	}
+}
 
-	userEmail, err := u.repo.GetUserEmail(ctx, alert.ID)
+// WithDigests queues the alerts of recipients in digest mode instead of
+// delivering them; SendDigests sends the summaries. Without it, every alert
+// is delivered right away.
+func WithDigests(digests domain.DigestStore) Option {
+	return func(u *ProcessAlert) {
+		u.digests = digests
+	}
+}
+
+// WithCooldown holds back repeat notifications of an alert as the store's
+// policy dictates. If the store is unavailable, alerts are sent anyway.
+func WithCooldown(cooldown domain.CooldownStore) Option {
+	return func(u *ProcessAlert) {
+		u.cooldown = cooldown
+	}
+}
+
+// WithAlertScheduler defers alerts that arrive during the recipient's quiet
+// hours to the end of the window; DeliverScheduled processes them again then.
+// Without it, those alerts are skipped.
+func WithAlertScheduler(scheduler domain.AlertScheduler) Option {
+	return func(u *ProcessAlert) {
+		u.scheduler = scheduler
+	}
+}
+
+// WithPriceHistory records the price of every alert received, including the
+// ones not notified, and adds the trend of the last days days to the email.
+// Failures are logged and never block delivery.
+func WithPriceHistory(history domain.PriceHistoryStore, days int) Option {
+	return func(u *ProcessAlert) {
+		u.history = history
+		u.trendDays = days
+	}
+}
+
+// WithExchangeRates also shows the prices in the recipient's display
+// currency, when they chose one. Without a rate for either currency, the
+// email shows only the alert's prices.
+func WithExchangeRates(rates domain.ExchangeRateProvider) Option {
+	return func(u *ProcessAlert) {
+		u.rates = rates
+	}
+}
+
+// NewProcessAlert delivers each alert on every channel the recipient enabled
+// that has a notifier. When a channel is listed twice, the last notifier wins.
+func NewProcessAlert(linkGen domain.BookingLinkGenerator, repo domain.AlertRepository, notifiers []domain.Notifier, renderer domain.EmailRenderer, opts ...Option) *ProcessAlert {
+	u := &ProcessAlert{
+		linkGen:   linkGen,
+		repo:      repo,
+		notifiers: make(map[domain.Channel]domain.Notifier, len(notifiers)),
+		renderer:  renderer,
+		rules:     domain.DefaultNotificationRules,
+		now:       time.Now,
+	}
+	for _, notifier := range notifiers {
+		u.notifiers[notifier.Channel()] = notifier
+	}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
+}
+
+func (u *ProcessAlert) Execute(ctx context.Context, alert *domain.Alert) (domain.Decision, error) {
+	u.recordPrice(ctx, alert)
+
+	decision := u.rules.Evaluate(alert)
+	if !decision.Notify {
+		return u.skip(alert, decision), nil
+	}
+
+	start := time.Now()
+	recipient, err := u.repo.GetRecipient(ctx, alert.ID)
+	metrics.DBQueryDuration.WithLabelValues("get_recipient", metrics.Result(err)).Observe(metrics.Since(start))
 	if err != nil {
-		return err
+		return decision, err
 	}
 
-	alertEmail := &domain.AlertEmail{
-		To:      userEmail,
-		Subject: "Price Alert Updated",
-		Body:    fmt.Sprintf("O preço do seu alerta foi atualizado. Novo preço: %.2f %s. Link: %s", alert.NewPrice, alert.Currency, alert.Link),
+	digest := u.digests != nil && recipient.Digest.Enabled()
+	switch {
+	case digest:
+		// Digests go out at a fixed hour, so quiet hours do not apply.
+		recipient.QuietHours = domain.QuietHours{}
+	case recipient.UrgentInQuietHours && u.rules.Urgent(alert):
+		recipient.QuietHours = domain.QuietHours{}
+	}
+	now := u.now()
+	decision = recipient.Evaluate(decision, now)
+	if decision.Skip == domain.SkipQuietHours && u.scheduler != nil {
+		return u.deferAlert(ctx, alert, recipient.QuietHoursEnd(now))
+	}
+	if !decision.Notify {
+		return u.skip(alert, decision), nil
+	}
+
+	if digest {
+		release, ok := u.claimCooldown(ctx, alert)
+		if !ok {
+			return u.skip(alert, domain.SkipDecision(domain.SkipCooldown)), nil
+		}
+		if err := u.queueDigest(ctx, alert, recipient, decision); err != nil {
+			release()
+			return decision, err
+		}
+		return decision, nil
+	}
+
+	notifiers := u.notifiersFor(alert, recipient)
+	if len(notifiers) == 0 {
+		return u.skip(alert, domain.SkipDecision(domain.SkipNoChannel)), nil
+	}
+
+	release, ok := u.claimCooldown(ctx, alert)
+	if !ok {
+		return u.skip(alert, domain.SkipDecision(domain.SkipCooldown)), nil
+	}
+
+	u.buildLinks(ctx, alert, recipient)
+	u.buildTrend(ctx, alert)
+	u.convertPrices(ctx, alert, recipient)
+
+	content, err := u.renderer.Render(alert, recipient)
+	if err != nil {
+		release()
+		return decision, err
+	}
+
+	msg := domain.Message{Alert: alert, Recipient: recipient, Trigger: decision.Trigger, Content: content}
+	var errs []error
+	for _, notifier := range notifiers {
+		if err := u.deliver(ctx, notifier, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Retrying would repeat the channels that succeeded, so the message is
+	// only retried when every channel failed.
+	switch {
+	case len(errs) == len(notifiers):
+		release()
+		return decision, deliveryError(errs)
+	case len(errs) > 0:
+		log.Printf("Alerta %d entregue em %d de %d canais", alert.ID, len(notifiers)-len(errs), len(notifiers))
+	}
+
+	metrics.NotificationsSent.WithLabelValues(string(decision.Trigger)).Inc()
+	return decision, nil
+}
+
+// deliveryError combines the errors of an alert that failed on every
+// channel. It stays permanent only when every channel failed permanently,
+// since otherwise a retry may still deliver on the others.
+func deliveryError(errs []error) error {
+	if len(errs) == 1 {
+		return errs[0]
+	}
+
+	permanent := 0
+	for _, err := range errs {
+		if errors.Is(err, domain.ErrPermanentDelivery) {
+			permanent++
+		}
+	}
+	if permanent == len(errs) {
+		return errors.Join(errs...)
+	}
+
+	retryable := make([]error, len(errs))
+	for i, err := range errs {
+		if errors.Is(err, domain.ErrPermanentDelivery) {
+			err = errors.New(err.Error())
+		}
+		retryable[i] = err
+	}
+	return errors.Join(retryable...)
+}
+
+// notifiersFor returns the notifiers of the recipient's channels, in the
+// order they listed them, skipping channels without a notifier or address.
+func (u *ProcessAlert) notifiersFor(alert *domain.Alert, recipient domain.Recipient) []domain.Notifier {
+	var notifiers []domain.Notifier
+	seen := make(map[domain.Channel]bool, len(recipient.Channels))
+	for _, channel := range recipient.Channels {
+		if seen[channel] {
+			continue
+		}
+		seen[channel] = true
+
+		notifier, ok := u.notifiers[channel]
+		if !ok {
+			log.Printf("Canal %s do alerta %d não está habilitado, ignorando", channel, alert.ID)
+			continue
+		}
+		if recipient.Address(channel) == "" {
+			log.Printf("Canal %s do alerta %d sem endereço configurado, ignorando", channel, alert.ID)
+			continue
+		}
+		notifiers = append(notifiers, notifier)
+	}
+	return notifiers
+}
+
+func (u *ProcessAlert) deliver(ctx context.Context, notifier domain.Notifier, msg domain.Message) error {
+	channel := notifier.Channel()
+	msg.Address = msg.Recipient.Address(channel)
+
+	notificationID := u.logPending(ctx, msg, channel)
+	start := time.Now()
+	response, err := notifier.Notify(ctx, msg)
+	metrics.NotificationDeliveryDuration.WithLabelValues(string(channel), metrics.Result(err)).Observe(metrics.Since(start))
+	u.logResult(ctx, notificationID, response, err)
+	if err != nil {
+		log.Printf("Erro entregando alerta %d pelo canal %s: %v", msg.Alert.ID, channel, err)
 	}
+	return err
+}
 
-	err = u.sender.Send(alertEmail.To, alertEmail.Subject, alertEmail.Body)
+func (u *ProcessAlert) queueDigest(ctx context.Context, alert *domain.Alert, recipient domain.Recipient, decision domain.Decision) error {
+	u.buildLinks(ctx, alert, recipient)
+
+	start := time.Now()
+	err := u.digests.AddDigestItem(ctx, domain.NewDigestItem(alert, recipient, decision.Trigger))
+	metrics.DBQueryDuration.WithLabelValues("add_digest_item", metrics.Result(err)).Observe(metrics.Since(start))
 	if err != nil {
 		return err
 	}
 
+	log.Printf("Alerta %d guardado para o resumo %s", alert.ID, recipient.Digest)
+	metrics.DigestItemsQueued.WithLabelValues(string(recipient.Digest)).Inc()
 	return nil
 }
+
+func (u *ProcessAlert) deferAlert(ctx context.Context, alert *domain.Alert, deliverAt time.Time) (domain.Decision, error) {
+	decision := domain.SkipDecision(domain.SkipDeferred)
+
+	start := time.Now()
+	err := u.scheduler.ScheduleAlert(ctx, alert, deliverAt)
+	metrics.DBQueryDuration.WithLabelValues("schedule_alert", metrics.Result(err)).Observe(metrics.Since(start))
+	if err != nil {
+		return decision, err
+	}
+
+	log.Printf("Alerta %d adiado para %s (horas de silêncio)", alert.ID, deliverAt.Format(time.RFC3339))
+	metrics.NotificationsDeferred.Inc()
+	return decision, nil
+}
+
+func (u *ProcessAlert) recordPrice(ctx context.Context, alert *domain.Alert) {
+	// Redeliveries and deferred alerts are deduplicated on CheckedAt, so a
+	// price without one is not recorded: the time it was processed would
+	// differ on each delivery.
+	if u.history == nil || alert.NewPrice <= 0 || alert.CheckedAt.IsZero() {
+		return
+	}
+
+	start := time.Now()
+	err := u.history.RecordPrice(ctx, domain.PricePoint{
+		AlertID:   alert.ID,
+		Price:     alert.NewPrice,
+		Currency:  alert.Currency,
+		CheckedAt: alert.CheckedAt,
+	})
+	metrics.DBQueryDuration.WithLabelValues("record_price", metrics.Result(err)).Observe(metrics.Since(start))
+	if err != nil {
+		log.Printf("Erro registrando histórico de preço do alerta %d: %v", alert.ID, err)
+	}
+}
+
+// buildTrend fills alert.Trend from the price history. Without enough
+// prices, or when the lookup fails, the email goes out without it.
+func (u *ProcessAlert) buildTrend(ctx context.Context, alert *domain.Alert) {
+	if u.history == nil {
+		return
+	}
+	since := u.now().AddDate(0, 0, -u.trendDays)
+
+	start := time.Now()
+	stats, err := u.history.PriceStats(ctx, alert.ID, alert.Currency, since)
+	metrics.DBQueryDuration.WithLabelValues("price_stats", metrics.Result(err)).Observe(metrics.Since(start))
+	if err != nil {
+		log.Printf("Erro buscando histórico de preços do alerta %d, enviando sem tendência: %v", alert.ID, err)
+		return
+	}
+	trend := domain.PriceTrend{Days: u.trendDays, Stats: stats}
+	if !trend.Comparable() {
+		return
+	}
+
+	start = time.Now()
+	trend.Daily, err = u.history.DailyLowestPrices(ctx, alert.ID, alert.Currency, since)
+	metrics.DBQueryDuration.WithLabelValues("daily_lowest_prices", metrics.Result(err)).Observe(metrics.Since(start))
+	if err != nil {
+		log.Printf("Erro buscando preços diários do alerta %d, enviando sem gráfico: %v", alert.ID, err)
+	}
+	alert.Trend = trend
+}
+
+func (u *ProcessAlert) convertPrices(ctx context.Context, alert *domain.Alert, recipient domain.Recipient) {
+	if u.rates == nil || recipient.Currency == "" || strings.EqualFold(recipient.Currency, alert.Currency) {
+		return
+	}
+
+	rate, err := u.rates.Rate(ctx, alert.Currency, recipient.Currency)
+	switch {
+	case errors.Is(err, domain.ErrUnknownCurrency):
+		log.Printf("Sem cotação de %s para %s, alerta %d enviado só na moeda original: %v", alert.Currency, recipient.Currency, alert.ID, err)
+		metrics.PriceConversions.WithLabelValues("unknown_currency").Inc()
+	case err != nil:
+		log.Printf("Erro buscando cotação de %s para %s do alerta %d, enviando só na moeda original: %v", alert.Currency, recipient.Currency, alert.ID, err)
+		metrics.PriceConversions.WithLabelValues("error").Inc()
+	default:
+		alert.DisplayRate = rate
+		metrics.PriceConversions.WithLabelValues("converted").Inc()
+	}
+}
+
+// claimCooldown reports whether the alert is out of its cooldown, and returns
+// a func that gives the claim back when nothing is delivered, so the retry is
+// not held back by its own attempt.
+func (u *ProcessAlert) claimCooldown(ctx context.Context, alert *domain.Alert) (func(), bool) {
+	if u.cooldown == nil {
+		return func() {}, true
+	}
+
+	next := domain.CooldownState{Price: alert.NewPrice, Currency: alert.Currency, NotifiedAt: u.now()}
+	previous, claimed, err := u.cooldown.Claim(ctx, alert.ID, next)
+	if err != nil {
+		log.Printf("Erro consultando cooldown do alerta %d, notificando mesmo assim: %v", alert.ID, err)
+		return func() {}, true
+	}
+	if !claimed {
+		return nil, false
+	}
+
+	return func() {
+		if err := u.cooldown.Restore(context.WithoutCancel(ctx), alert.ID, next, previous); err != nil {
+			log.Printf("Erro liberando cooldown do alerta %d: %v", alert.ID, err)
+		}
+	}, true
+}
+
+func (u *ProcessAlert) skip(alert *domain.Alert, decision domain.Decision) domain.Decision {
+	log.Printf("Alerta %d ignorado: %s", alert.ID, decision.Skip)
+	metrics.NotificationsSuppressed.WithLabelValues(string(decision.Skip)).Inc()
+	return decision
+}
+
+func (u *ProcessAlert) linkRequest(alert *domain.Alert, recipient domain.Recipient) domain.LinkRequest {
+	req := alert.LinkRequest()
+	if u.userLinks {
+		req.Providers = recipient.LinkProviders
+	}
+	return req
+}
+
+func (u *ProcessAlert) buildLinks(ctx context.Context, alert *domain.Alert, recipient domain.Recipient) {
+	alert.Links = u.linkGen.Links(u.linkRequest(alert, recipient))
+	u.trackLinks(ctx, alert)
+	if len(alert.Links) > 0 {
+		alert.Link = alert.Links[0].URL
+	}
+}
+
+func (u *ProcessAlert) trackLinks(ctx context.Context, alert *domain.Alert) {
+	if u.tracker == nil {
+		return
+	}
+
+	for i, link := range alert.Links {
+		tracked, err := u.tracker.Track(ctx, alert, link)
+		if err != nil {
+			log.Printf("Erro rastreando link %s do alerta %d, usando link direto: %v", link.Provider, alert.ID, err)
+			continue
+		}
+		alert.Links[i].URL = tracked
+	}
+}
+
+func (u *ProcessAlert) logPending(ctx context.Context, msg domain.Message, channel domain.Channel) int64 {
+	if u.notifications == nil {
+		return 0
+	}
+
+	alert := msg.Alert
+	start := time.Now()
+	id, err := u.notifications.CreateNotification(ctx, domain.Notification{
+		AlertID:   alert.ID,
+		MessageID: alert.MessageID,
+		Recipient: msg.Recipient.Email,
+		Channel:   channel,
+		Subject:   msg.Content.Subject,
+		Trigger:   msg.Trigger,
+		OldPrice:  alert.OldPrice,
+		NewPrice:  alert.NewPrice,
+		Currency:  alert.Currency,
+		Status:    domain.NotificationPending,
+	})
+	metrics.DBQueryDuration.WithLabelValues("create_notification", metrics.Result(err)).Observe(metrics.Since(start))
+	if err != nil {
+		log.Printf("Erro registrando notificação do alerta %d: %v", alert.ID, err)
+		return 0
+	}
+	return id
+}
+
+func (u *ProcessAlert) logResult(ctx context.Context, id int64, response string, sendErr error) {
+	if u.notifications == nil || id == 0 {
+		return
+	}
+
+	start := time.Now()
+	var err error
+	if sendErr != nil {
+		err = u.notifications.MarkNotificationFailed(ctx, id, sendErr.Error())
+	} else {
+		err = u.notifications.MarkNotificationSent(ctx, id, response)
+	}
+	metrics.DBQueryDuration.WithLabelValues("update_notification", metrics.Result(err)).Observe(metrics.Since(start))
+	if err != nil {
+		log.Printf("Erro atualizando notificação %d: %v", id, err)
+	}
+}
@@ -3,6 +3,7 @@ package usecases
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -16,27 +17,94 @@ type MockLinkGenerator struct {
 	mock.Mock
 }
 
-func (m *MockLinkGenerator) Generate(origin, dest string, out, ret time.Time) string {
-	args := m.Called(origin, dest, out, ret)
-	return args.String(0)
+func (m *MockLinkGenerator) Links(req domain.LinkRequest) []domain.BookingLink {
+	args := m.Called(req)
+	links, _ := args.Get(0).([]domain.BookingLink)
+	return links
+}
+
+type MockLinkTracker struct {
+	mock.Mock
+}
+
+func (m *MockLinkTracker) Track(ctx context.Context, alert *domain.Alert, link domain.BookingLink) (string, error) {
+	args := m.Called(ctx, alert, link)
+	return args.String(0), args.Error(1)
+}
+
+func bookingLinks(urls ...string) []domain.BookingLink {
+	links := make([]domain.BookingLink, 0, len(urls))
+	for _, url := range urls {
+		links = append(links, domain.BookingLink{Provider: "google_flights", Name: "Google Flights", URL: url})
+	}
+	return links
 }
 
 type MockAlertRepository struct {
 	mock.Mock
 }
 
-func (m *MockAlertRepository) GetUserEmail(ctx context.Context, alertID int64) (string, error) {
+func (m *MockAlertRepository) GetRecipient(ctx context.Context, alertID int64) (domain.Recipient, error) {
 	args := m.Called(ctx, alertID)
+	recipient, _ := args.Get(0).(domain.Recipient)
+	return recipient, args.Error(1)
+}
+
+type MockNotifier struct {
+	mock.Mock
+	channel domain.Channel
+}
+
+func newMockNotifier(channel domain.Channel) *MockNotifier {
+	return &MockNotifier{channel: channel}
+}
+
+func (m *MockNotifier) Channel() domain.Channel {
+	return m.channel
+}
+
+func (m *MockNotifier) Notify(ctx context.Context, msg domain.Message) (string, error) {
+	args := m.Called(ctx, msg)
 	return args.String(0), args.Error(1)
 }
 
-type MockEmailSender struct {
+type MockNotificationLog struct {
 	mock.Mock
 }
 
-func (m *MockEmailSender) Send(to, subject, body string) error {
-	args := m.Called(to, subject, body)
-	return args.Error(0)
+func (m *MockNotificationLog) CreateNotification(ctx context.Context, n domain.Notification) (int64, error) {
+	args := m.Called(ctx, n)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNotificationLog) MarkNotificationSent(ctx context.Context, id int64, response string) error {
+	return m.Called(ctx, id, response).Error(0)
+}
+
+func (m *MockNotificationLog) MarkNotificationFailed(ctx context.Context, id int64, reason string) error {
+	return m.Called(ctx, id, reason).Error(0)
+}
+
+func (m *MockNotificationLog) NotificationsByAlert(ctx context.Context, alertID int64, limit int) ([]domain.Notification, error) {
+	args := m.Called(ctx, alertID, limit)
+	notifications, _ := args.Get(0).([]domain.Notification)
+	return notifications, args.Error(1)
+}
+
+func (m *MockNotificationLog) NotificationsByRecipient(ctx context.Context, email string, limit int) ([]domain.Notification, error) {
+	args := m.Called(ctx, email, limit)
+	notifications, _ := args.Get(0).([]domain.Notification)
+	return notifications, args.Error(1)
+}
+
+type MockEmailRenderer struct {
+	mock.Mock
+}
+
+func (m *MockEmailRenderer) Render(alert *domain.Alert, recipient domain.Recipient) (*domain.AlertEmail, error) {
+	args := m.Called(alert, recipient)
+	email, _ := args.Get(0).(*domain.AlertEmail)
+	return email, args.Error(1)
 }
 
 func TestProcessAlert_Execute_LinkGeneration(t *testing.T) {
@@ -54,12 +122,12 @@ func TestProcessAlert_Execute_LinkGeneration(t *testing.T) {
 	}
 
 	expectedLink := "https://www.google.com/travel/flights?q=Flights%20to%20JFK%20from%20GRU..."
-	mockLinkGen.On("Generate", "GRU", "JFK", alert.OutboundDate, alert.ReturnDate).Return(expectedLink)
+	mockLinkGen.On("Links", alert.LinkRequest()).Return(bookingLinks(expectedLink))
 
-	link := mockLinkGen.Generate(alert.Origin, alert.Destination, alert.OutboundDate, alert.ReturnDate)
+	links := mockLinkGen.Links(alert.LinkRequest())
 
-	assert.Equal(t, expectedLink, link)
-	assert.NotEmpty(t, link)
+	require.Len(t, links, 1)
+	assert.Equal(t, expectedLink, links[0].URL)
 	mockLinkGen.AssertExpectations(t)
 }
 
@@ -67,7 +135,7 @@ func TestProcessAlert_Execute_RepositoryError(t *testing.T) {
 	mockLinkGen := new(MockLinkGenerator)
 	mockRepo := new(MockAlertRepository)
 
-	useCase := NewProcessAlert(mockLinkGen, mockRepo, nil)
+	useCase := NewProcessAlert(mockLinkGen, mockRepo, nil, nil)
 
 	alert := &domain.Alert{
 		ID:           1,
@@ -76,21 +144,19 @@ func TestProcessAlert_Execute_RepositoryError(t *testing.T) {
 		OutboundDate: time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC),
 		ReturnDate:   time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC),
 		NewPrice:     1200.00,
+		OldPrice:     1500.00,
 		Currency:     "BRL",
 	}
 
-	expectedLink := "https://www.google.com/travel/flights?q=Flights%20to%20JFK%20from%20GRU..."
-	mockLinkGen.On("Generate", "GRU", "JFK", alert.OutboundDate, alert.ReturnDate).Return(expectedLink)
-
 	expectedError := errors.New("database error")
-	mockRepo.On("GetUserEmail", mock.Anything, int64(1)).Return("", expectedError)
+	mockRepo.On("GetRecipient", mock.Anything, int64(1)).Return(domain.Recipient{}, expectedError)
 
 	ctx := context.Background()
-	err := useCase.Execute(ctx, alert)
+	_, err := useCase.Execute(ctx, alert)
 
 	require.Error(t, err)
 	assert.Equal(t, expectedError, err)
-	mockLinkGen.AssertExpectations(t)
+	mockLinkGen.AssertNotCalled(t, "Links", mock.Anything)
 	mockRepo.AssertExpectations(t)
 }
 
@@ -98,9 +164,979 @@ func TestProcessAlert_NewProcessAlert(t *testing.T) {
 	mockLinkGen := new(MockLinkGenerator)
 	mockRepo := new(MockAlertRepository)
 
-	useCase := NewProcessAlert(mockLinkGen, mockRepo, nil)
+	useCase := NewProcessAlert(mockLinkGen, mockRepo, nil, nil)
 
 	assert.NotNil(t, useCase)
 	assert.Equal(t, mockLinkGen, useCase.linkGen)
 	assert.Equal(t, mockRepo, useCase.repo)
 }
+
+func TestProcessAlert_Execute_Success(t *testing.T) {
+	mockLinkGen := new(MockLinkGenerator)
+	mockRepo := new(MockAlertRepository)
+	mockEmail := newMockNotifier(domain.ChannelEmail)
+	mockRenderer := new(MockEmailRenderer)
+
+	useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail}, mockRenderer)
+
+	alert := &domain.Alert{
+		ID:           1,
+		Origin:       "GRU",
+		Destination:  "JFK",
+		OutboundDate: time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC),
+		ReturnDate:   time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC),
+		NewPrice:     1200.00,
+		OldPrice:     1500.00,
+		Currency:     "BRL",
+	}
+
+	expectedLink := "https://www.google.com/travel/flights?q=Flights%20to%20JFK%20from%20GRU..."
+	mockLinkGen.On("Links", alert.LinkRequest()).Return(bookingLinks(expectedLink))
+	mockRepo.On("GetRecipient", mock.Anything, int64(1)).Return(domain.NewRecipient(1, "user@example.com"), nil)
+	content := &domain.AlertEmail{
+		Subject:  "Alerta de preço",
+		TextBody: "text",
+		HTMLBody: "<p>html</p>",
+	}
+	mockRenderer.On("Render", alert, domain.NewRecipient(1, "user@example.com")).Return(content, nil)
+	mockEmail.On("Notify", mock.Anything, domain.Message{
+		Alert:     alert,
+		Recipient: domain.NewRecipient(1, "user@example.com"),
+		Trigger:   domain.TriggerPriceDrop,
+		Content:   content,
+		Address:   "user@example.com",
+	}).Return("250 ok", nil)
+
+	decision, err := useCase.Execute(context.Background(), alert)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.NotifyDecision(domain.TriggerPriceDrop), decision)
+	assert.Equal(t, expectedLink, alert.Link)
+	mockLinkGen.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+	mockRenderer.AssertExpectations(t)
+	mockEmail.AssertExpectations(t)
+}
+
+func TestProcessAlert_Execute_RenderError(t *testing.T) {
+	mockLinkGen := new(MockLinkGenerator)
+	mockRepo := new(MockAlertRepository)
+	mockEmail := newMockNotifier(domain.ChannelEmail)
+	mockRenderer := new(MockEmailRenderer)
+
+	useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail}, mockRenderer)
+
+	alert := &domain.Alert{
+		ID:           1,
+		Origin:       "GRU",
+		Destination:  "JFK",
+		OutboundDate: time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC),
+		ReturnDate:   time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC),
+		NewPrice:     1200.00,
+		OldPrice:     1500.00,
+		Currency:     "BRL",
+	}
+
+	expectedError := errors.New("template error")
+	mockLinkGen.On("Links", alert.LinkRequest()).Return(bookingLinks("https://example.com"))
+	mockRepo.On("GetRecipient", mock.Anything, int64(1)).Return(domain.NewRecipient(1, "user@example.com"), nil)
+	mockRenderer.On("Render", alert, mock.Anything).Return(nil, expectedError)
+
+	_, err := useCase.Execute(context.Background(), alert)
+
+	assert.Equal(t, expectedError, err)
+	mockEmail.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+}
+
+func TestProcessAlert_Execute_SkipsPriceIncrease(t *testing.T) {
+	mockLinkGen := new(MockLinkGenerator)
+	mockRepo := new(MockAlertRepository)
+	mockEmail := newMockNotifier(domain.ChannelEmail)
+	mockRenderer := new(MockEmailRenderer)
+
+	useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail}, mockRenderer)
+
+	alert := &domain.Alert{
+		ID:          1,
+		Origin:      "GRU",
+		Destination: "JFK",
+		NewPrice:    1600.00,
+		OldPrice:    1500.00,
+		TargetPrice: 1000.00,
+		Currency:    "BRL",
+	}
+
+	decision, err := useCase.Execute(context.Background(), alert)
+
+	require.NoError(t, err)
+	assert.False(t, decision.Notify)
+	assert.Equal(t, domain.SkipPriceNotDropped, decision.Skip)
+	mockRepo.AssertNotCalled(t, "GetRecipient", mock.Anything, mock.Anything)
+	mockEmail.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+}
+
+func TestProcessAlert_Execute_CustomRules(t *testing.T) {
+	mockLinkGen := new(MockLinkGenerator)
+	mockRepo := new(MockAlertRepository)
+	mockEmail := newMockNotifier(domain.ChannelEmail)
+	mockRenderer := new(MockEmailRenderer)
+
+	useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail}, mockRenderer,
+		WithNotificationRules(domain.NotificationRules{MinDropPercent: 50}))
+
+	alert := &domain.Alert{
+		ID:       1,
+		NewPrice: 1200.00,
+		OldPrice: 1500.00,
+		Currency: "BRL",
+	}
+
+	decision, err := useCase.Execute(context.Background(), alert)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.SkipDecision(domain.SkipNoMeaningfulDrop), decision)
+	mockEmail.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+}
+
+func TestProcessAlert_Execute_UserLinkProviders(t *testing.T) {
+	mockLinkGen := new(MockLinkGenerator)
+	mockRepo := new(MockAlertRepository)
+	mockEmail := newMockNotifier(domain.ChannelEmail)
+	mockRenderer := new(MockEmailRenderer)
+
+	useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail}, mockRenderer, WithUserLinkProviders())
+
+	alert := &domain.Alert{
+		ID:           1,
+		Origin:       "GRU",
+		Destination:  "JFK",
+		OutboundDate: time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC),
+		NewPrice:     1200.00,
+		OldPrice:     1500.00,
+		Currency:     "BRL",
+	}
+
+	req := alert.LinkRequest()
+	req.Providers = []string{"kayak", "skyscanner"}
+	links := []domain.BookingLink{
+		{Provider: "kayak", Name: "Kayak", URL: "https://www.kayak.com/flights/GRU-JFK/2025-12-15"},
+		{Provider: "skyscanner", Name: "Skyscanner", URL: "https://www.skyscanner.com/transport/flights/gru/jfk/251215/"},
+	}
+
+	recipient := domain.NewRecipient(1, "user@example.com")
+	recipient.LinkProviders = []string{"kayak", "skyscanner"}
+	mockLinkGen.On("Links", req).Return(links)
+	mockRepo.On("GetRecipient", mock.Anything, int64(1)).Return(recipient, nil)
+	mockRenderer.On("Render", alert, mock.Anything).Return(&domain.AlertEmail{Subject: "s"}, nil)
+	mockEmail.On("Notify", mock.Anything, mock.Anything).Return("250 ok", nil)
+
+	_, err := useCase.Execute(context.Background(), alert)
+
+	require.NoError(t, err)
+	assert.Equal(t, links, alert.Links)
+	assert.Equal(t, links[0].URL, alert.Link)
+	mockLinkGen.AssertExpectations(t)
+}
+
+func TestProcessAlert_Execute_UserLinkProvidersDisabled(t *testing.T) {
+	mockLinkGen := new(MockLinkGenerator)
+	mockRepo := new(MockAlertRepository)
+	mockEmail := newMockNotifier(domain.ChannelEmail)
+	mockRenderer := new(MockEmailRenderer)
+
+	useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail}, mockRenderer)
+
+	alert := &domain.Alert{ID: 1, NewPrice: 1200.00, OldPrice: 1500.00, Currency: "BRL"}
+
+	recipient := domain.NewRecipient(1, "user@example.com")
+	recipient.LinkProviders = []string{"kayak"}
+	mockLinkGen.On("Links", alert.LinkRequest()).Return(bookingLinks("https://example.com"))
+	mockRepo.On("GetRecipient", mock.Anything, int64(1)).Return(recipient, nil)
+	mockRenderer.On("Render", alert, mock.Anything).Return(&domain.AlertEmail{Subject: "s"}, nil)
+	mockEmail.On("Notify", mock.Anything, mock.Anything).Return("250 ok", nil)
+
+	_, err := useCase.Execute(context.Background(), alert)
+
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", alert.Link)
+	mockLinkGen.AssertExpectations(t)
+}
+
+func TestProcessAlert_Execute_TracksLinks(t *testing.T) {
+	mockLinkGen := new(MockLinkGenerator)
+	mockRepo := new(MockAlertRepository)
+	mockEmail := newMockNotifier(domain.ChannelEmail)
+	mockRenderer := new(MockEmailRenderer)
+	mockTracker := new(MockLinkTracker)
+
+	useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail}, mockRenderer, WithLinkTracker(mockTracker))
+
+	alert := &domain.Alert{ID: 1, NewPrice: 1200.00, OldPrice: 1500.00, Currency: "BRL"}
+	google := domain.BookingLink{Provider: "google_flights", Name: "Google Flights", URL: "https://www.google.com/travel/flights"}
+	kayak := domain.BookingLink{Provider: "kayak", Name: "Kayak", URL: "https://www.kayak.com/flights"}
+
+	mockLinkGen.On("Links", alert.LinkRequest()).Return([]domain.BookingLink{google, kayak})
+	mockTracker.On("Track", mock.Anything, alert, google).Return("https://alerts.example.com/r/token1", nil)
+	mockTracker.On("Track", mock.Anything, alert, kayak).Return("", errors.New("database down"))
+	mockRepo.On("GetRecipient", mock.Anything, int64(1)).Return(domain.NewRecipient(1, "user@example.com"), nil)
+	mockRenderer.On("Render", alert, mock.Anything).Return(&domain.AlertEmail{Subject: "s"}, nil)
+	mockEmail.On("Notify", mock.Anything, mock.Anything).Return("250 ok", nil)
+
+	_, err := useCase.Execute(context.Background(), alert)
+
+	require.NoError(t, err)
+	assert.Equal(t, "https://alerts.example.com/r/token1", alert.Link)
+	assert.Equal(t, "https://alerts.example.com/r/token1", alert.Links[0].URL)
+	assert.Equal(t, kayak.URL, alert.Links[1].URL)
+	mockTracker.AssertExpectations(t)
+}
+
+func notificationAlert() *domain.Alert {
+	return &domain.Alert{
+		ID:        42,
+		MessageID: "abc-123",
+		NewPrice:  1200.00,
+		OldPrice:  1500.00,
+		Currency:  "BRL",
+	}
+}
+
+func TestProcessAlert_Execute_LogsSentNotification(t *testing.T) {
+	mockLinkGen := new(MockLinkGenerator)
+	mockRepo := new(MockAlertRepository)
+	mockEmail := newMockNotifier(domain.ChannelEmail)
+	mockRenderer := new(MockEmailRenderer)
+	mockLog := new(MockNotificationLog)
+
+	useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail}, mockRenderer, WithNotificationLog(mockLog))
+
+	alert := notificationAlert()
+	mockLinkGen.On("Links", alert.LinkRequest()).Return(bookingLinks("https://www.google.com/travel/flights"))
+	mockRepo.On("GetRecipient", mock.Anything, int64(42)).Return(domain.NewRecipient(42, "user@example.com"), nil)
+	mockRenderer.On("Render", alert, mock.Anything).Return(&domain.AlertEmail{Subject: "Alerta de preço"}, nil)
+	mockLog.On("CreateNotification", mock.Anything, domain.Notification{
+		AlertID:   42,
+		MessageID: "abc-123",
+		Recipient: "user@example.com",
+		Channel:   domain.ChannelEmail,
+		Subject:   "Alerta de preço",
+		Trigger:   domain.TriggerPriceDrop,
+		OldPrice:  1500.00,
+		NewPrice:  1200.00,
+		Currency:  "BRL",
+		Status:    domain.NotificationPending,
+	}).Return(int64(7), nil)
+	mockEmail.On("Notify", mock.Anything, mock.Anything).Return("250 2.0.0 ok queued as ABC123", nil)
+	mockLog.On("MarkNotificationSent", mock.Anything, int64(7), "250 2.0.0 ok queued as ABC123").Return(nil)
+
+	_, err := useCase.Execute(context.Background(), alert)
+
+	require.NoError(t, err)
+	mockLog.AssertExpectations(t)
+}
+
+func TestProcessAlert_Execute_LogsFailedNotification(t *testing.T) {
+	mockLinkGen := new(MockLinkGenerator)
+	mockRepo := new(MockAlertRepository)
+	mockEmail := newMockNotifier(domain.ChannelEmail)
+	mockRenderer := new(MockEmailRenderer)
+	mockLog := new(MockNotificationLog)
+
+	useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail}, mockRenderer, WithNotificationLog(mockLog))
+
+	alert := notificationAlert()
+	sendErr := errors.New("smtp recipient rejected: 550 no such user")
+	mockLinkGen.On("Links", alert.LinkRequest()).Return(bookingLinks("https://www.google.com/travel/flights"))
+	mockRepo.On("GetRecipient", mock.Anything, int64(42)).Return(domain.NewRecipient(42, "user@example.com"), nil)
+	mockRenderer.On("Render", alert, mock.Anything).Return(&domain.AlertEmail{Subject: "Alerta de preço"}, nil)
+	mockLog.On("CreateNotification", mock.Anything, mock.Anything).Return(int64(7), nil)
+	mockEmail.On("Notify", mock.Anything, mock.Anything).Return("", sendErr)
+	mockLog.On("MarkNotificationFailed", mock.Anything, int64(7), sendErr.Error()).Return(nil)
+
+	_, err := useCase.Execute(context.Background(), alert)
+
+	assert.Equal(t, sendErr, err)
+	mockLog.AssertExpectations(t)
+}
+
+func TestProcessAlert_Execute_NotificationLogUnavailable(t *testing.T) {
+	mockLinkGen := new(MockLinkGenerator)
+	mockRepo := new(MockAlertRepository)
+	mockEmail := newMockNotifier(domain.ChannelEmail)
+	mockRenderer := new(MockEmailRenderer)
+	mockLog := new(MockNotificationLog)
+
+	useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail}, mockRenderer, WithNotificationLog(mockLog))
+
+	alert := notificationAlert()
+	mockLinkGen.On("Links", alert.LinkRequest()).Return(bookingLinks("https://www.google.com/travel/flights"))
+	mockRepo.On("GetRecipient", mock.Anything, int64(42)).Return(domain.NewRecipient(42, "user@example.com"), nil)
+	mockRenderer.On("Render", alert, mock.Anything).Return(&domain.AlertEmail{Subject: "Alerta de preço"}, nil)
+	mockLog.On("CreateNotification", mock.Anything, mock.Anything).Return(int64(0), errors.New("database down"))
+	mockEmail.On("Notify", mock.Anything, mock.Anything).Return("250 ok", nil)
+
+	_, err := useCase.Execute(context.Background(), alert)
+
+	require.NoError(t, err)
+	mockEmail.AssertExpectations(t)
+	mockLog.AssertNotCalled(t, "MarkNotificationSent", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestProcessAlert_Execute_RecipientPreferences(t *testing.T) {
+	// 06:00 UTC is 03:00 in São Paulo.
+	now := time.Date(2025, 12, 2, 6, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name      string
+		recipient func(r *domain.Recipient)
+		expected  domain.SkipReason
+	}{
+		{name: "opted out", recipient: func(r *domain.Recipient) { r.OptedOut = true }, expected: domain.SkipOptedOut},
+		{name: "opted out of trigger", recipient: func(r *domain.Recipient) { r.OptedOutTriggers = []domain.Trigger{domain.TriggerPriceDrop} }, expected: domain.SkipOptedOut},
+		{name: "email disabled", recipient: func(r *domain.Recipient) { r.Channels = []domain.Channel{"sms"} }, expected: domain.SkipNoChannel},
+		{name: "quiet hours", recipient: func(r *domain.Recipient) { r.QuietHours = domain.QuietHours{Start: 22 * 60, End: 7 * 60} }, expected: domain.SkipQuietHours},
+		{
+			name: "quiet hours with digests disabled",
+			recipient: func(r *domain.Recipient) {
+				r.Digest = domain.DigestDaily
+				r.QuietHours = domain.QuietHours{Start: 22 * 60, End: 7 * 60}
+			},
+			expected: domain.SkipQuietHours,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockLinkGen := new(MockLinkGenerator)
+			mockRepo := new(MockAlertRepository)
+			mockEmail := newMockNotifier(domain.ChannelEmail)
+			mockRenderer := new(MockEmailRenderer)
+
+			useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail}, mockRenderer)
+			useCase.now = func() time.Time { return now }
+
+			recipient := domain.NewRecipient(1, "user@example.com")
+			tc.recipient(&recipient)
+			mockRepo.On("GetRecipient", mock.Anything, int64(1)).Return(recipient, nil)
+
+			decision, err := useCase.Execute(context.Background(), &domain.Alert{ID: 1, OldPrice: 1500, NewPrice: 1200, Currency: "BRL"})
+
+			require.NoError(t, err)
+			assert.Equal(t, domain.SkipDecision(tc.expected), decision)
+			mockLinkGen.AssertNotCalled(t, "Links", mock.Anything)
+			mockEmail.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func multiChannelRecipient() domain.Recipient {
+	recipient := domain.NewRecipient(42, "user@example.com")
+	recipient.Channels = []domain.Channel{domain.ChannelEmail, domain.ChannelSlack, domain.ChannelTelegram, domain.ChannelWebhook, domain.ChannelSlack}
+	recipient.SlackWebhookURL = "https://hooks.slack.com/services/T000/B000/XXXX"
+	recipient.WebhookURL = "https://hooks.example.com/alerts"
+	return recipient
+}
+
+func TestProcessAlert_Execute_FansOutToChannels(t *testing.T) {
+	mockLinkGen := new(MockLinkGenerator)
+	mockRepo := new(MockAlertRepository)
+	mockRenderer := new(MockEmailRenderer)
+	mockLog := new(MockNotificationLog)
+	mockEmail := newMockNotifier(domain.ChannelEmail)
+	mockSlack := newMockNotifier(domain.ChannelSlack)
+	mockTelegram := newMockNotifier(domain.ChannelTelegram)
+
+	useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail, mockSlack, mockTelegram}, mockRenderer, WithNotificationLog(mockLog))
+
+	alert := notificationAlert()
+	mockLinkGen.On("Links", alert.LinkRequest()).Return(bookingLinks("https://www.google.com/travel/flights"))
+	mockRepo.On("GetRecipient", mock.Anything, int64(42)).Return(multiChannelRecipient(), nil)
+	mockRenderer.On("Render", alert, mock.Anything).Return(&domain.AlertEmail{Subject: "Alerta de preço"}, nil)
+	mockLog.On("CreateNotification", mock.Anything, mock.MatchedBy(func(n domain.Notification) bool {
+		return n.Channel == domain.ChannelEmail && n.Recipient == "user@example.com"
+	})).Return(int64(7), nil)
+	mockLog.On("CreateNotification", mock.Anything, mock.MatchedBy(func(n domain.Notification) bool {
+		return n.Channel == domain.ChannelSlack && n.Recipient == "user@example.com"
+	})).Return(int64(8), nil)
+	mockEmail.On("Notify", mock.Anything, mock.MatchedBy(func(msg domain.Message) bool {
+		return msg.Address == "user@example.com"
+	})).Return("250 ok", nil)
+	mockSlack.On("Notify", mock.Anything, mock.MatchedBy(func(msg domain.Message) bool {
+		return msg.Address == "https://hooks.slack.com/services/T000/B000/XXXX"
+	})).Return("200 OK", nil)
+	mockLog.On("MarkNotificationSent", mock.Anything, int64(7), "250 ok").Return(nil)
+	mockLog.On("MarkNotificationSent", mock.Anything, int64(8), "200 OK").Return(nil)
+
+	decision, err := useCase.Execute(context.Background(), alert)
+
+	require.NoError(t, err)
+	assert.True(t, decision.Notify)
+	mockEmail.AssertNumberOfCalls(t, "Notify", 1)
+	mockSlack.AssertNumberOfCalls(t, "Notify", 1)
+	mockTelegram.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+	mockLog.AssertExpectations(t)
+}
+
+func TestProcessAlert_Execute_PartialDeliveryIsNotRetried(t *testing.T) {
+	mockLinkGen := new(MockLinkGenerator)
+	mockRepo := new(MockAlertRepository)
+	mockRenderer := new(MockEmailRenderer)
+	mockLog := new(MockNotificationLog)
+	mockEmail := newMockNotifier(domain.ChannelEmail)
+	mockSlack := newMockNotifier(domain.ChannelSlack)
+
+	useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail, mockSlack}, mockRenderer, WithNotificationLog(mockLog))
+
+	alert := notificationAlert()
+	mockLinkGen.On("Links", alert.LinkRequest()).Return(bookingLinks("https://www.google.com/travel/flights"))
+	mockRepo.On("GetRecipient", mock.Anything, int64(42)).Return(multiChannelRecipient(), nil)
+	mockRenderer.On("Render", alert, mock.Anything).Return(&domain.AlertEmail{Subject: "Alerta de preço"}, nil)
+	mockLog.On("CreateNotification", mock.Anything, mock.MatchedBy(func(n domain.Notification) bool { return n.Channel == domain.ChannelEmail })).Return(int64(7), nil)
+	mockLog.On("CreateNotification", mock.Anything, mock.MatchedBy(func(n domain.Notification) bool { return n.Channel == domain.ChannelSlack })).Return(int64(8), nil)
+	mockEmail.On("Notify", mock.Anything, mock.Anything).Return("250 ok", nil)
+	mockSlack.On("Notify", mock.Anything, mock.Anything).Return("", errors.New("notifier unexpected response status: 404 Not Found: no_service"))
+	mockLog.On("MarkNotificationSent", mock.Anything, int64(7), "250 ok").Return(nil)
+	mockLog.On("MarkNotificationFailed", mock.Anything, int64(8), "notifier unexpected response status: 404 Not Found: no_service").Return(nil)
+
+	_, err := useCase.Execute(context.Background(), alert)
+
+	require.NoError(t, err)
+	mockLog.AssertExpectations(t)
+}
+
+func TestProcessAlert_Execute_AllChannelsFailed(t *testing.T) {
+	mockLinkGen := new(MockLinkGenerator)
+	mockRepo := new(MockAlertRepository)
+	mockRenderer := new(MockEmailRenderer)
+	mockEmail := newMockNotifier(domain.ChannelEmail)
+	mockSlack := newMockNotifier(domain.ChannelSlack)
+
+	useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail, mockSlack}, mockRenderer)
+
+	alert := notificationAlert()
+	emailErr := errors.New("smtp transient failure: 421 try later")
+	slackErr := errors.New("notifier request failed: connection refused")
+	mockLinkGen.On("Links", alert.LinkRequest()).Return(bookingLinks("https://www.google.com/travel/flights"))
+	mockRepo.On("GetRecipient", mock.Anything, int64(42)).Return(multiChannelRecipient(), nil)
+	mockRenderer.On("Render", alert, mock.Anything).Return(&domain.AlertEmail{Subject: "Alerta de preço"}, nil)
+	mockEmail.On("Notify", mock.Anything, mock.Anything).Return("", emailErr)
+	mockSlack.On("Notify", mock.Anything, mock.Anything).Return("", slackErr)
+
+	_, err := useCase.Execute(context.Background(), alert)
+
+	assert.ErrorIs(t, err, emailErr)
+	assert.ErrorIs(t, err, slackErr)
+}
+
+func TestProcessAlert_Execute_PermanentOnlyWhenEveryChannelIs(t *testing.T) {
+	rejected := fmt.Errorf("%w: smtp recipient rejected: 550 no such user", domain.ErrPermanentDelivery)
+	testCases := []struct {
+		name      string
+		slackErr  error
+		permanent bool
+	}{
+		{name: "every channel permanent", slackErr: fmt.Errorf("%w: channel_not_found", domain.ErrPermanentDelivery), permanent: true},
+		{name: "one channel transient", slackErr: errors.New("notifier request failed: connection refused"), permanent: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockLinkGen := new(MockLinkGenerator)
+			mockRepo := new(MockAlertRepository)
+			mockRenderer := new(MockEmailRenderer)
+			mockEmail := newMockNotifier(domain.ChannelEmail)
+			mockSlack := newMockNotifier(domain.ChannelSlack)
+
+			useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail, mockSlack}, mockRenderer)
+
+			alert := notificationAlert()
+			mockLinkGen.On("Links", alert.LinkRequest()).Return(bookingLinks("https://www.google.com/travel/flights"))
+			mockRepo.On("GetRecipient", mock.Anything, int64(42)).Return(multiChannelRecipient(), nil)
+			mockRenderer.On("Render", alert, mock.Anything).Return(&domain.AlertEmail{Subject: "Alerta de preço"}, nil)
+			mockEmail.On("Notify", mock.Anything, mock.Anything).Return("", rejected)
+			mockSlack.On("Notify", mock.Anything, mock.Anything).Return("", tc.slackErr)
+
+			_, err := useCase.Execute(context.Background(), alert)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "550 no such user")
+			assert.Equal(t, tc.permanent, errors.Is(err, domain.ErrPermanentDelivery))
+		})
+	}
+}
+
+func TestProcessAlert_Execute_NoDeliverableChannel(t *testing.T) {
+	mockLinkGen := new(MockLinkGenerator)
+	mockRepo := new(MockAlertRepository)
+	mockRenderer := new(MockEmailRenderer)
+	mockTelegram := newMockNotifier(domain.ChannelTelegram)
+
+	useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockTelegram}, mockRenderer)
+
+	recipient := domain.NewRecipient(1, "user@example.com")
+	recipient.Channels = []domain.Channel{domain.ChannelTelegram}
+	mockRepo.On("GetRecipient", mock.Anything, int64(1)).Return(recipient, nil)
+
+	decision, err := useCase.Execute(context.Background(), &domain.Alert{ID: 1, OldPrice: 1500, NewPrice: 1200, Currency: "BRL"})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.SkipDecision(domain.SkipNoChannel), decision)
+	mockRenderer.AssertNotCalled(t, "Render", mock.Anything, mock.Anything)
+	mockTelegram.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+}
+
+func TestProcessAlert_Execute_QueuesDigest(t *testing.T) {
+	// 06:00 UTC is 03:00 in São Paulo, inside the quiet hours.
+	now := time.Date(2025, 12, 2, 6, 0, 0, 0, time.UTC)
+
+	mockLinkGen := new(MockLinkGenerator)
+	mockRepo := new(MockAlertRepository)
+	mockEmail := newMockNotifier(domain.ChannelEmail)
+	mockRenderer := new(MockEmailRenderer)
+	mockDigests := new(MockDigestStore)
+
+	useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail}, mockRenderer, WithDigests(mockDigests))
+	useCase.now = func() time.Time { return now }
+
+	recipient := domain.NewRecipient(1, "user@example.com")
+	recipient.Digest = domain.DigestDaily
+	recipient.QuietHours = domain.QuietHours{Start: 22 * 60, End: 7 * 60}
+	alert := &domain.Alert{ID: 1, MessageID: "abc-123", Origin: "GRU", Destination: "JFK", OldPrice: 1500, NewPrice: 1200, Currency: "BRL"}
+
+	mockRepo.On("GetRecipient", mock.Anything, int64(1)).Return(recipient, nil)
+	mockLinkGen.On("Links", mock.Anything).Return(bookingLinks("https://flights.example.com/GRU-JFK"))
+	mockDigests.On("AddDigestItem", mock.Anything, mock.MatchedBy(func(item domain.DigestItem) bool {
+		return item.AlertID == 1 && item.MessageID == "abc-123" && item.Email == "user@example.com" &&
+			item.Trigger == domain.TriggerPriceDrop && item.Link == "https://flights.example.com/GRU-JFK"
+	})).Return(nil)
+
+	decision, err := useCase.Execute(context.Background(), alert)
+
+	require.NoError(t, err)
+	assert.True(t, decision.Notify)
+	mockDigests.AssertExpectations(t)
+	mockRenderer.AssertNotCalled(t, "Render", mock.Anything, mock.Anything)
+	mockEmail.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+}
+
+func TestProcessAlert_Execute_QueueDigestError(t *testing.T) {
+	mockLinkGen := new(MockLinkGenerator)
+	mockRepo := new(MockAlertRepository)
+	mockDigests := new(MockDigestStore)
+
+	useCase := NewProcessAlert(mockLinkGen, mockRepo, nil, new(MockEmailRenderer), WithDigests(mockDigests))
+
+	recipient := domain.NewRecipient(1, "user@example.com")
+	recipient.Digest = domain.DigestWeekly
+	dbErr := errors.New("connection refused")
+	mockRepo.On("GetRecipient", mock.Anything, int64(1)).Return(recipient, nil)
+	mockLinkGen.On("Links", mock.Anything).Return(nil)
+	mockDigests.On("AddDigestItem", mock.Anything, mock.Anything).Return(dbErr)
+
+	_, err := useCase.Execute(context.Background(), &domain.Alert{ID: 1, OldPrice: 1500, NewPrice: 1200, Currency: "BRL"})
+
+	assert.ErrorIs(t, err, dbErr)
+}
+
+type MockCooldownStore struct {
+	mock.Mock
+}
+
+func (m *MockCooldownStore) Claim(ctx context.Context, alertID int64, next domain.CooldownState) (domain.CooldownState, bool, error) {
+	args := m.Called(ctx, alertID, next)
+	previous, _ := args.Get(0).(domain.CooldownState)
+	return previous, args.Bool(1), args.Error(2)
+}
+
+func (m *MockCooldownStore) Restore(ctx context.Context, alertID int64, claimed, previous domain.CooldownState) error {
+	return m.Called(ctx, alertID, claimed, previous).Error(0)
+}
+
+func TestProcessAlert_Execute_Cooldown(t *testing.T) {
+	now := time.Date(2025, 12, 2, 15, 0, 0, 0, time.UTC)
+	next := domain.CooldownState{Price: 1200, Currency: "BRL", NotifiedAt: now}
+	previous := domain.CooldownState{Price: 1210, Currency: "BRL", NotifiedAt: now.Add(-10 * time.Minute)}
+
+	t.Run("suppressed", func(t *testing.T) {
+		mockLinkGen := new(MockLinkGenerator)
+		mockRepo := new(MockAlertRepository)
+		mockEmail := newMockNotifier(domain.ChannelEmail)
+		mockCooldown := new(MockCooldownStore)
+
+		useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail}, new(MockEmailRenderer), WithCooldown(mockCooldown))
+		useCase.now = func() time.Time { return now }
+
+		mockRepo.On("GetRecipient", mock.Anything, int64(1)).Return(domain.NewRecipient(1, "user@example.com"), nil)
+		mockCooldown.On("Claim", mock.Anything, int64(1), next).Return(previous, false, nil)
+
+		decision, err := useCase.Execute(context.Background(), &domain.Alert{ID: 1, OldPrice: 1500, NewPrice: 1200, Currency: "BRL"})
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.SkipDecision(domain.SkipCooldown), decision)
+		mockLinkGen.AssertNotCalled(t, "Links", mock.Anything)
+		mockEmail.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+	})
+
+	t.Run("released when delivery fails", func(t *testing.T) {
+		mockLinkGen := new(MockLinkGenerator)
+		mockRepo := new(MockAlertRepository)
+		mockEmail := newMockNotifier(domain.ChannelEmail)
+		mockRenderer := new(MockEmailRenderer)
+		mockCooldown := new(MockCooldownStore)
+
+		useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail}, mockRenderer, WithCooldown(mockCooldown))
+		useCase.now = func() time.Time { return now }
+
+		sendErr := errors.New("smtp transient failure: 421 try later")
+		mockRepo.On("GetRecipient", mock.Anything, int64(1)).Return(domain.NewRecipient(1, "user@example.com"), nil)
+		mockCooldown.On("Claim", mock.Anything, int64(1), next).Return(previous, true, nil)
+		mockLinkGen.On("Links", mock.Anything).Return(nil)
+		mockRenderer.On("Render", mock.Anything, mock.Anything).Return(&domain.AlertEmail{}, nil)
+		mockEmail.On("Notify", mock.Anything, mock.Anything).Return("", sendErr)
+		mockCooldown.On("Restore", mock.Anything, int64(1), next, previous).Return(nil)
+
+		_, err := useCase.Execute(context.Background(), &domain.Alert{ID: 1, OldPrice: 1500, NewPrice: 1200, Currency: "BRL"})
+
+		assert.ErrorIs(t, err, sendErr)
+		mockCooldown.AssertExpectations(t)
+	})
+
+	t.Run("kept when delivered", func(t *testing.T) {
+		mockLinkGen := new(MockLinkGenerator)
+		mockRepo := new(MockAlertRepository)
+		mockEmail := newMockNotifier(domain.ChannelEmail)
+		mockRenderer := new(MockEmailRenderer)
+		mockCooldown := new(MockCooldownStore)
+
+		useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail}, mockRenderer, WithCooldown(mockCooldown))
+		useCase.now = func() time.Time { return now }
+
+		mockRepo.On("GetRecipient", mock.Anything, int64(1)).Return(domain.NewRecipient(1, "user@example.com"), nil)
+		mockCooldown.On("Claim", mock.Anything, int64(1), next).Return(domain.CooldownState{}, true, nil)
+		mockLinkGen.On("Links", mock.Anything).Return(nil)
+		mockRenderer.On("Render", mock.Anything, mock.Anything).Return(&domain.AlertEmail{}, nil)
+		mockEmail.On("Notify", mock.Anything, mock.Anything).Return("250 ok", nil)
+
+		_, err := useCase.Execute(context.Background(), &domain.Alert{ID: 1, OldPrice: 1500, NewPrice: 1200, Currency: "BRL"})
+
+		require.NoError(t, err)
+		mockCooldown.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("store unavailable", func(t *testing.T) {
+		mockLinkGen := new(MockLinkGenerator)
+		mockRepo := new(MockAlertRepository)
+		mockEmail := newMockNotifier(domain.ChannelEmail)
+		mockRenderer := new(MockEmailRenderer)
+		mockCooldown := new(MockCooldownStore)
+
+		useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail}, mockRenderer, WithCooldown(mockCooldown))
+		useCase.now = func() time.Time { return now }
+
+		mockRepo.On("GetRecipient", mock.Anything, int64(1)).Return(domain.NewRecipient(1, "user@example.com"), nil)
+		mockCooldown.On("Claim", mock.Anything, int64(1), next).Return(nil, false, errors.New("connection refused"))
+		mockLinkGen.On("Links", mock.Anything).Return(nil)
+		mockRenderer.On("Render", mock.Anything, mock.Anything).Return(&domain.AlertEmail{}, nil)
+		mockEmail.On("Notify", mock.Anything, mock.Anything).Return("250 ok", nil)
+
+		decision, err := useCase.Execute(context.Background(), &domain.Alert{ID: 1, OldPrice: 1500, NewPrice: 1200, Currency: "BRL"})
+
+		require.NoError(t, err)
+		assert.True(t, decision.Notify)
+		mockEmail.AssertExpectations(t)
+	})
+}
+
+type MockAlertScheduler struct {
+	mock.Mock
+}
+
+func (m *MockAlertScheduler) ScheduleAlert(ctx context.Context, alert *domain.Alert, deliverAt time.Time) error {
+	return m.Called(ctx, alert, deliverAt).Error(0)
+}
+
+func (m *MockAlertScheduler) ClaimScheduledAlert(ctx context.Context, lease time.Duration) (domain.ScheduledAlert, error) {
+	args := m.Called(ctx, lease)
+	scheduled, _ := args.Get(0).(domain.ScheduledAlert)
+	return scheduled, args.Error(1)
+}
+
+func (m *MockAlertScheduler) MarkScheduledAlertSent(ctx context.Context, scheduled domain.ScheduledAlert) error {
+	return m.Called(ctx, scheduled).Error(0)
+}
+
+func (m *MockAlertScheduler) MarkScheduledAlertSkipped(ctx context.Context, scheduled domain.ScheduledAlert, reason string) error {
+	return m.Called(ctx, scheduled, reason).Error(0)
+}
+
+func (m *MockAlertScheduler) RetryScheduledAlert(ctx context.Context, scheduled domain.ScheduledAlert, reason string) error {
+	return m.Called(ctx, scheduled, reason).Error(0)
+}
+
+func (m *MockAlertScheduler) MarkScheduledAlertFailed(ctx context.Context, scheduled domain.ScheduledAlert, reason string) error {
+	return m.Called(ctx, scheduled, reason).Error(0)
+}
+
+func quietRecipient() domain.Recipient {
+	recipient := domain.NewRecipient(1, "user@example.com")
+	recipient.QuietHours = domain.QuietHours{Start: 22 * 60, End: 7 * 60}
+	return recipient
+}
+
+func TestProcessAlert_Execute_DefersDuringQuietHours(t *testing.T) {
+	// 06:00 UTC is 03:00 in São Paulo; the window ends at 07:00 there.
+	now := time.Date(2025, 12, 2, 6, 0, 0, 0, time.UTC)
+	deliverAt := time.Date(2025, 12, 2, 10, 0, 0, 0, time.UTC)
+
+	mockLinkGen := new(MockLinkGenerator)
+	mockRepo := new(MockAlertRepository)
+	mockEmail := newMockNotifier(domain.ChannelEmail)
+	mockScheduler := new(MockAlertScheduler)
+
+	useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail}, new(MockEmailRenderer), WithAlertScheduler(mockScheduler))
+	useCase.now = func() time.Time { return now }
+
+	alert := &domain.Alert{ID: 1, OldPrice: 1500, NewPrice: 1200, Currency: "BRL"}
+	mockRepo.On("GetRecipient", mock.Anything, int64(1)).Return(quietRecipient(), nil)
+	mockScheduler.On("ScheduleAlert", mock.Anything, alert, mock.MatchedBy(deliverAt.Equal)).Return(nil)
+
+	decision, err := useCase.Execute(context.Background(), alert)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.SkipDecision(domain.SkipDeferred), decision)
+	mockScheduler.AssertExpectations(t)
+	mockLinkGen.AssertNotCalled(t, "Links", mock.Anything)
+	mockEmail.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+}
+
+func TestProcessAlert_Execute_UrgentDuringQuietHours(t *testing.T) {
+	now := time.Date(2025, 12, 2, 6, 0, 0, 0, time.UTC)
+	urgent := &domain.Alert{ID: 1, OldPrice: 1500, NewPrice: 900, Currency: "BRL"}
+
+	testCases := []struct {
+		name     string
+		optIn    bool
+		alert    *domain.Alert
+		deferred bool
+	}{
+		{name: "opted in", optIn: true, alert: urgent, deferred: false},
+		{name: "not opted in", optIn: false, alert: urgent, deferred: true},
+		{name: "opted in, not urgent", optIn: true, alert: &domain.Alert{ID: 1, OldPrice: 1500, NewPrice: 1200, Currency: "BRL"}, deferred: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockLinkGen := new(MockLinkGenerator)
+			mockRepo := new(MockAlertRepository)
+			mockEmail := newMockNotifier(domain.ChannelEmail)
+			mockRenderer := new(MockEmailRenderer)
+			mockScheduler := new(MockAlertScheduler)
+
+			useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail}, mockRenderer, WithAlertScheduler(mockScheduler))
+			useCase.now = func() time.Time { return now }
+
+			recipient := quietRecipient()
+			recipient.UrgentInQuietHours = tc.optIn
+			mockRepo.On("GetRecipient", mock.Anything, int64(1)).Return(recipient, nil)
+			mockScheduler.On("ScheduleAlert", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			mockLinkGen.On("Links", mock.Anything).Return(nil)
+			mockRenderer.On("Render", mock.Anything, mock.Anything).Return(&domain.AlertEmail{}, nil)
+			mockEmail.On("Notify", mock.Anything, mock.Anything).Return("250 ok", nil)
+
+			decision, err := useCase.Execute(context.Background(), tc.alert)
+
+			require.NoError(t, err)
+			if tc.deferred {
+				assert.Equal(t, domain.SkipDecision(domain.SkipDeferred), decision)
+				mockEmail.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+			} else {
+				assert.True(t, decision.Notify)
+				mockScheduler.AssertNotCalled(t, "ScheduleAlert", mock.Anything, mock.Anything, mock.Anything)
+				mockEmail.AssertExpectations(t)
+			}
+		})
+	}
+}
+
+func TestProcessAlert_Execute_ScheduleError(t *testing.T) {
+	mockRepo := new(MockAlertRepository)
+	mockScheduler := new(MockAlertScheduler)
+
+	useCase := NewProcessAlert(new(MockLinkGenerator), mockRepo, nil, new(MockEmailRenderer), WithAlertScheduler(mockScheduler))
+	useCase.now = func() time.Time { return time.Date(2025, 12, 2, 6, 0, 0, 0, time.UTC) }
+
+	dbErr := errors.New("connection refused")
+	mockRepo.On("GetRecipient", mock.Anything, int64(1)).Return(quietRecipient(), nil)
+	mockScheduler.On("ScheduleAlert", mock.Anything, mock.Anything, mock.Anything).Return(dbErr)
+
+	_, err := useCase.Execute(context.Background(), &domain.Alert{ID: 1, OldPrice: 1500, NewPrice: 1200, Currency: "BRL"})
+
+	assert.ErrorIs(t, err, dbErr)
+}
+
+type MockPriceHistoryStore struct {
+	mock.Mock
+}
+
+func (m *MockPriceHistoryStore) RecordPrice(ctx context.Context, point domain.PricePoint) error {
+	return m.Called(ctx, point).Error(0)
+}
+
+func (m *MockPriceHistoryStore) PriceStats(ctx context.Context, alertID int64, currency string, since time.Time) (domain.PriceStats, error) {
+	args := m.Called(ctx, alertID, currency, since)
+	return args.Get(0).(domain.PriceStats), args.Error(1)
+}
+
+func (m *MockPriceHistoryStore) DailyLowestPrices(ctx context.Context, alertID int64, currency string, since time.Time) ([]domain.PricePoint, error) {
+	args := m.Called(ctx, alertID, currency, since)
+	points, _ := args.Get(0).([]domain.PricePoint)
+	return points, args.Error(1)
+}
+
+func TestProcessAlert_Execute_PriceTrend(t *testing.T) {
+	now := time.Date(2025, 12, 2, 15, 0, 0, 0, time.UTC)
+	checkedAt := now.Add(-time.Minute)
+	since := now.AddDate(0, 0, -30)
+	stats := domain.PriceStats{Min: 1200, Max: 1600, Average: 1400, Count: 9}
+	daily := []domain.PricePoint{{AlertID: 1, Price: 1500, Currency: "BRL"}, {AlertID: 1, Price: 1200, Currency: "BRL"}}
+
+	mockRepo := new(MockAlertRepository)
+	mockLinkGen := new(MockLinkGenerator)
+	mockEmail := newMockNotifier(domain.ChannelEmail)
+	mockRenderer := new(MockEmailRenderer)
+	mockHistory := new(MockPriceHistoryStore)
+
+	useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail}, mockRenderer, WithPriceHistory(mockHistory, 30))
+	useCase.now = func() time.Time { return now }
+
+	mockHistory.On("RecordPrice", mock.Anything, domain.PricePoint{AlertID: 1, Price: 1200, Currency: "BRL", CheckedAt: checkedAt}).Return(nil)
+	mockRepo.On("GetRecipient", mock.Anything, int64(1)).Return(domain.NewRecipient(1, "user@example.com"), nil)
+	mockLinkGen.On("Links", mock.Anything).Return(nil)
+	mockHistory.On("PriceStats", mock.Anything, int64(1), "BRL", since).Return(stats, nil)
+	mockHistory.On("DailyLowestPrices", mock.Anything, int64(1), "BRL", since).Return(daily, nil)
+	expectedTrend := domain.PriceTrend{Days: 30, Stats: stats, Daily: daily}
+	mockRenderer.On("Render", mock.MatchedBy(func(alert *domain.Alert) bool {
+		return assert.ObjectsAreEqual(expectedTrend, alert.Trend)
+	}), mock.Anything).Return(&domain.AlertEmail{}, nil)
+	mockEmail.On("Notify", mock.Anything, mock.Anything).Return("250 ok", nil)
+
+	_, err := useCase.Execute(context.Background(), &domain.Alert{ID: 1, OldPrice: 1500, NewPrice: 1200, Currency: "BRL", CheckedAt: checkedAt})
+
+	require.NoError(t, err)
+	mockHistory.AssertExpectations(t)
+	mockRenderer.AssertExpectations(t)
+}
+
+func TestProcessAlert_Execute_RecordsSkippedPrices(t *testing.T) {
+	mockHistory := new(MockPriceHistoryStore)
+	checkedAt := time.Date(2025, 12, 2, 15, 0, 0, 0, time.UTC)
+
+	useCase := NewProcessAlert(new(MockLinkGenerator), new(MockAlertRepository), nil, new(MockEmailRenderer), WithPriceHistory(mockHistory, 30))
+
+	mockHistory.On("RecordPrice", mock.Anything, domain.PricePoint{AlertID: 1, Price: 1600, Currency: "BRL", CheckedAt: checkedAt}).Return(nil)
+
+	alert := &domain.Alert{ID: 1, OldPrice: 1500, NewPrice: 1600, Currency: "BRL", CheckedAt: checkedAt}
+	decision, err := useCase.Execute(context.Background(), alert)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.SkipPriceNotDropped, decision.Skip)
+	mockHistory.AssertExpectations(t)
+}
+
+func TestProcessAlert_Execute_SkipsPriceWithoutCheckedAt(t *testing.T) {
+	mockHistory := new(MockPriceHistoryStore)
+
+	useCase := NewProcessAlert(new(MockLinkGenerator), new(MockAlertRepository), nil, new(MockEmailRenderer), WithPriceHistory(mockHistory, 30))
+
+	decision, err := useCase.Execute(context.Background(), &domain.Alert{ID: 1, OldPrice: 1500, NewPrice: 1600, Currency: "BRL"})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.SkipPriceNotDropped, decision.Skip)
+	mockHistory.AssertNotCalled(t, "RecordPrice", mock.Anything, mock.Anything)
+}
+
+func TestProcessAlert_Execute_PriceHistoryUnavailable(t *testing.T) {
+	mockRepo := new(MockAlertRepository)
+	mockLinkGen := new(MockLinkGenerator)
+	mockEmail := newMockNotifier(domain.ChannelEmail)
+	mockRenderer := new(MockEmailRenderer)
+	mockHistory := new(MockPriceHistoryStore)
+
+	useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail}, mockRenderer, WithPriceHistory(mockHistory, 30))
+
+	dbErr := errors.New("connection refused")
+	mockHistory.On("RecordPrice", mock.Anything, mock.Anything).Return(dbErr)
+	mockRepo.On("GetRecipient", mock.Anything, int64(1)).Return(domain.NewRecipient(1, "user@example.com"), nil)
+	mockLinkGen.On("Links", mock.Anything).Return(nil)
+	mockHistory.On("PriceStats", mock.Anything, int64(1), "BRL", mock.Anything).Return(domain.PriceStats{}, dbErr)
+	mockRenderer.On("Render", mock.MatchedBy(func(alert *domain.Alert) bool {
+		return !alert.Trend.Comparable()
+	}), mock.Anything).Return(&domain.AlertEmail{}, nil)
+	mockEmail.On("Notify", mock.Anything, mock.Anything).Return("250 ok", nil)
+
+	decision, err := useCase.Execute(context.Background(), &domain.Alert{ID: 1, OldPrice: 1500, NewPrice: 1200, Currency: "BRL", CheckedAt: time.Date(2025, 12, 2, 15, 0, 0, 0, time.UTC)})
+
+	require.NoError(t, err)
+	assert.True(t, decision.Notify)
+	mockEmail.AssertExpectations(t)
+	mockHistory.AssertExpectations(t)
+	mockHistory.AssertNotCalled(t, "DailyLowestPrices", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+type MockExchangeRateProvider struct {
+	mock.Mock
+}
+
+func (m *MockExchangeRateProvider) Rate(ctx context.Context, from, to string) (domain.ExchangeRate, error) {
+	args := m.Called(ctx, from, to)
+	return args.Get(0).(domain.ExchangeRate), args.Error(1)
+}
+
+func TestProcessAlert_Execute_ConvertsPrices(t *testing.T) {
+	usd := domain.ExchangeRate{From: "BRL", To: "USD", Rate: 0.19, AsOf: time.Date(2025, 12, 2, 12, 0, 0, 0, time.UTC)}
+
+	testCases := []struct {
+		name     string
+		currency string
+		rate     domain.ExchangeRate
+		err      error
+		expected domain.ExchangeRate
+	}{
+		{name: "converted", currency: "USD", rate: usd, expected: usd},
+		{name: "unknown currency", currency: "XYZ", err: fmt.Errorf("%w: XYZ", domain.ErrUnknownCurrency)},
+		{name: "provider unavailable", currency: "USD", err: errors.New("exchange rates: unexpected status 503")},
+		{name: "same currency", currency: "brl"},
+		{name: "no display currency"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(MockAlertRepository)
+			mockLinkGen := new(MockLinkGenerator)
+			mockEmail := newMockNotifier(domain.ChannelEmail)
+			mockRenderer := new(MockEmailRenderer)
+			mockRates := new(MockExchangeRateProvider)
+
+			useCase := NewProcessAlert(mockLinkGen, mockRepo, []domain.Notifier{mockEmail}, mockRenderer, WithExchangeRates(mockRates))
+
+			recipient := domain.NewRecipient(1, "user@example.com")
+			recipient.Currency = tc.currency
+			mockRepo.On("GetRecipient", mock.Anything, int64(1)).Return(recipient, nil)
+			mockLinkGen.On("Links", mock.Anything).Return(nil)
+			mockRates.On("Rate", mock.Anything, "BRL", tc.currency).Return(tc.rate, tc.err)
+			mockRenderer.On("Render", mock.MatchedBy(func(alert *domain.Alert) bool {
+				return alert.DisplayRate == tc.expected
+			}), mock.Anything).Return(&domain.AlertEmail{}, nil)
+			mockEmail.On("Notify", mock.Anything, mock.Anything).Return("250 ok", nil)
+
+			decision, err := useCase.Execute(context.Background(), &domain.Alert{ID: 1, OldPrice: 1500, NewPrice: 1200, Currency: "BRL"})
+
+			require.NoError(t, err)
+			assert.True(t, decision.Notify)
+			mockRenderer.AssertExpectations(t)
+			if tc.currency == "" || tc.currency == "brl" {
+				mockRates.AssertNotCalled(t, "Rate", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/Luzin7/alert-service/internal/infra/metrics"
+)
+
+const (
+	DefaultDigestLease       = 5 * time.Minute
+	DefaultDigestMaxAttempts = 5
+)
+
+// SendDigests closes the digest periods that ended and emails every due
+// digest. Several workers may run it at once: the store hands each digest to
+// a single worker, and one that stops mid-send lets its lease expire so the
+// digest is sent by the next run.
+type SendDigests struct {
+	store       domain.DigestStore
+	repo        domain.AlertRepository
+	sender      domain.TempEmailSender
+	renderer    domain.DigestRenderer
+	schedule    domain.DigestSchedule
+	lease       time.Duration
+	maxAttempts int
+	now         func() time.Time
+}
+
+type DigestOption func(*SendDigests)
+
+func WithDigestSchedule(schedule domain.DigestSchedule) DigestOption {
+	return func(u *SendDigests) {
+		u.schedule = schedule
+	}
+}
+
+// WithDigestLease sets how long a claimed digest stays hidden from other
+// workers. It must be longer than rendering and sending one email takes.
+func WithDigestLease(lease time.Duration) DigestOption {
+	return func(u *SendDigests) {
+		u.lease = lease
+	}
+}
+
+// WithDigestMaxAttempts sets how many sends are tried before a digest is
+// marked failed.
+func WithDigestMaxAttempts(attempts int) DigestOption {
+	return func(u *SendDigests) {
+		u.maxAttempts = attempts
+	}
+}
+
+func NewSendDigests(store domain.DigestStore, repo domain.AlertRepository, sender domain.TempEmailSender, renderer domain.DigestRenderer, opts ...DigestOption) *SendDigests {
+	u := &SendDigests{
+		store:       store,
+		repo:        repo,
+		sender:      sender,
+		renderer:    renderer,
+		schedule:    domain.DefaultDigestSchedule,
+		lease:       DefaultDigestLease,
+		maxAttempts: DefaultDigestMaxAttempts,
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
+}
+
+// Start runs the digests every interval until ctx is canceled.
+func (u *SendDigests) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := u.Run(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Erro enviando resumos: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run closes the due periods and sends the pending digests, returning how
+// many were sent.
+func (u *SendDigests) Run(ctx context.Context) (int, error) {
+	u.closeDue(ctx)
+
+	sent := 0
+	for ctx.Err() == nil {
+		digest, err := u.store.ClaimDigest(ctx, u.lease)
+		if errors.Is(err, domain.ErrNoDigestDue) {
+			return sent, nil
+		}
+		if err != nil {
+			return sent, err
+		}
+		if u.send(ctx, digest) {
+			sent++
+		}
+	}
+	return sent, ctx.Err()
+}
+
+func (u *SendDigests) closeDue(ctx context.Context) {
+	queues, err := u.store.PendingDigestQueues(ctx)
+	if err != nil {
+		log.Printf("Erro buscando resumos pendentes: %v", err)
+		return
+	}
+
+	now := u.now()
+	for _, queue := range queues {
+		periodEnd, due := queue.Due(u.schedule, now)
+		if !due {
+			continue
+		}
+		if _, err := u.store.CloseDigest(ctx, queue, periodEnd); err != nil {
+			log.Printf("Erro fechando resumo de %s: %v", queue.Email, err)
+		}
+	}
+}
+
+func (u *SendDigests) send(ctx context.Context, digest domain.Digest) bool {
+	// The outcome is recorded even when shutdown cancels ctx mid-send.
+	finishCtx := context.WithoutCancel(ctx)
+
+	if len(digest.Items) == 0 {
+		u.finish("empty", u.store.MarkDigestSent(finishCtx, digest.ID, ""))
+		return false
+	}
+
+	recipient, err := u.recipient(ctx, digest)
+	if err != nil {
+		u.fail(finishCtx, digest, err)
+		return false
+	}
+	if recipient.OptedOut {
+		log.Printf("Resumo %d descartado: %s", digest.ID, domain.SkipOptedOut)
+		u.finish("discarded", u.store.MarkDigestFailed(finishCtx, digest.ID, string(domain.SkipOptedOut)))
+		return false
+	}
+
+	digest.SortItems()
+	email, err := u.renderer.RenderDigest(digest, recipient)
+	if err != nil {
+		u.fail(finishCtx, digest, err)
+		return false
+	}
+	email.To = digest.Email
+
+	response, err := u.sender.Send(ctx, email)
+	if err != nil {
+		u.fail(finishCtx, digest, err)
+		return false
+	}
+
+	log.Printf("Resumo %d enviado para %s com %d alertas", digest.ID, digest.Email, len(digest.Items))
+	u.finish("sent", u.store.MarkDigestSent(finishCtx, digest.ID, response))
+	return true
+}
+
+// recipient loads the preferences through the digest's alerts, skipping
+// alerts deleted since they were queued.
+func (u *SendDigests) recipient(ctx context.Context, digest domain.Digest) (domain.Recipient, error) {
+	var err error
+	for _, item := range digest.Items {
+		var recipient domain.Recipient
+		recipient, err = u.repo.GetRecipient(ctx, item.AlertID)
+		if err == nil {
+			return recipient, nil
+		}
+	}
+	return domain.Recipient{}, err
+}
+
+func (u *SendDigests) fail(ctx context.Context, digest domain.Digest, err error) {
+	log.Printf("Erro enviando resumo %d (tentativa %d de %d): %v", digest.ID, digest.Attempts, u.maxAttempts, err)
+	if digest.Attempts >= u.maxAttempts {
+		u.finish("failed", u.store.MarkDigestFailed(ctx, digest.ID, err.Error()))
+		return
+	}
+	u.finish("retry", u.store.RetryDigest(ctx, digest.ID, err.Error()))
+}
+
+func (u *SendDigests) finish(outcome string, err error) {
+	metrics.DigestsProcessed.WithLabelValues(outcome).Inc()
+	if err != nil {
+		log.Printf("Erro atualizando resumo: %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Luzin7/alert-service/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockDigestStore struct {
+	mock.Mock
+}
+
+func (m *MockDigestStore) AddDigestItem(ctx context.Context, item domain.DigestItem) error {
+	return m.Called(ctx, item).Error(0)
+}
+
+func (m *MockDigestStore) PendingDigestQueues(ctx context.Context) ([]domain.DigestQueue, error) {
+	args := m.Called(ctx)
+	queues, _ := args.Get(0).([]domain.DigestQueue)
+	return queues, args.Error(1)
+}
+
+func (m *MockDigestStore) CloseDigest(ctx context.Context, queue domain.DigestQueue, periodEnd time.Time) (bool, error) {
+	args := m.Called(ctx, queue, periodEnd)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDigestStore) ClaimDigest(ctx context.Context, lease time.Duration) (domain.Digest, error) {
+	args := m.Called(ctx, lease)
+	digest, _ := args.Get(0).(domain.Digest)
+	return digest, args.Error(1)
+}
+
+func (m *MockDigestStore) MarkDigestSent(ctx context.Context, id int64, response string) error {
+	return m.Called(ctx, id, response).Error(0)
+}
+
+func (m *MockDigestStore) RetryDigest(ctx context.Context, id int64, reason string) error {
+	return m.Called(ctx, id, reason).Error(0)
+}
+
+func (m *MockDigestStore) MarkDigestFailed(ctx context.Context, id int64, reason string) error {
+	return m.Called(ctx, id, reason).Error(0)
+}
+
+type MockEmailSender struct {
+	mock.Mock
+}
+
+func (m *MockEmailSender) Send(ctx context.Context, email *domain.AlertEmail) (string, error) {
+	args := m.Called(ctx, email)
+	return args.String(0), args.Error(1)
+}
+
+type MockDigestRenderer struct {
+	mock.Mock
+}
+
+func (m *MockDigestRenderer) RenderDigest(digest domain.Digest, recipient domain.Recipient) (*domain.AlertEmail, error) {
+	args := m.Called(digest, recipient)
+	email, _ := args.Get(0).(*domain.AlertEmail)
+	return email, args.Error(1)
+}
+
+func testDigest(attempts int) domain.Digest {
+	return domain.Digest{
+		ID:        5,
+		Email:     "user@example.com",
+		Frequency: domain.DigestDaily,
+		Attempts:  attempts,
+		Items: []domain.DigestItem{
+			{ID: 1, AlertID: 42, OldPrice: 1000, NewPrice: 950},
+			{ID: 2, AlertID: 43, OldPrice: 1000, NewPrice: 700},
+		},
+	}
+}
+
+func TestSendDigests_Run(t *testing.T) {
+	store := new(MockDigestStore)
+	repo := new(MockAlertRepository)
+	sender := new(MockEmailSender)
+	renderer := new(MockDigestRenderer)
+
+	now := time.Date(2025, 12, 2, 9, 30, 0, 0, time.UTC)
+	periodEnd := time.Date(2025, 12, 2, 8, 0, 0, 0, time.UTC)
+	due := domain.DigestQueue{Email: "user@example.com", Frequency: domain.DigestDaily, Timezone: "UTC", Oldest: periodEnd.Add(-time.Hour)}
+	notDue := domain.DigestQueue{Email: "late@example.com", Frequency: domain.DigestDaily, Timezone: "UTC", Oldest: periodEnd.Add(time.Minute)}
+
+	useCase := NewSendDigests(store, repo, sender, renderer)
+	useCase.now = func() time.Time { return now }
+
+	recipient := domain.NewRecipient(42, "user@example.com")
+	store.On("PendingDigestQueues", mock.Anything).Return([]domain.DigestQueue{due, notDue}, nil)
+	store.On("CloseDigest", mock.Anything, due, periodEnd).Return(true, nil)
+	store.On("ClaimDigest", mock.Anything, DefaultDigestLease).Return(testDigest(1), nil).Once()
+	store.On("ClaimDigest", mock.Anything, DefaultDigestLease).Return(domain.Digest{}, domain.ErrNoDigestDue).Once()
+	repo.On("GetRecipient", mock.Anything, int64(42)).Return(recipient, nil)
+	renderer.On("RenderDigest", mock.MatchedBy(func(d domain.Digest) bool {
+		return d.Items[0].AlertID == 43 && d.Items[1].AlertID == 42
+	}), recipient).Return(&domain.AlertEmail{Subject: "Resumo diário"}, nil)
+	sender.On("Send", mock.Anything, &domain.AlertEmail{To: "user@example.com", Subject: "Resumo diário"}).Return("250 ok", nil)
+	store.On("MarkDigestSent", mock.Anything, int64(5), "250 ok").Return(nil)
+
+	sent, err := useCase.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	store.AssertNotCalled(t, "CloseDigest", mock.Anything, notDue, mock.Anything)
+	store.AssertExpectations(t)
+	sender.AssertExpectations(t)
+}
+
+func TestSendDigests_Run_SendFailure(t *testing.T) {
+	testCases := []struct {
+		name     string
+		attempts int
+		expected string
+	}{
+		{name: "retried", attempts: 1, expected: "RetryDigest"},
+		{name: "last attempt", attempts: DefaultDigestMaxAttempts, expected: "MarkDigestFailed"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := new(MockDigestStore)
+			repo := new(MockAlertRepository)
+			sender := new(MockEmailSender)
+			renderer := new(MockDigestRenderer)
+
+			useCase := NewSendDigests(store, repo, sender, renderer)
+
+			store.On("PendingDigestQueues", mock.Anything).Return(nil, nil)
+			store.On("ClaimDigest", mock.Anything, DefaultDigestLease).Return(testDigest(tc.attempts), nil).Once()
+			store.On("ClaimDigest", mock.Anything, DefaultDigestLease).Return(domain.Digest{}, domain.ErrNoDigestDue).Once()
+			repo.On("GetRecipient", mock.Anything, int64(42)).Return(domain.NewRecipient(42, "user@example.com"), nil)
+			renderer.On("RenderDigest", mock.Anything, mock.Anything).Return(&domain.AlertEmail{}, nil)
+			sender.On("Send", mock.Anything, mock.Anything).Return("", errors.New("421 try later"))
+			store.On(tc.expected, mock.Anything, int64(5), "421 try later").Return(nil)
+
+			sent, err := useCase.Run(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, 0, sent)
+			store.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSendDigests_Run_SkipsDeletedAlerts(t *testing.T) {
+	store := new(MockDigestStore)
+	repo := new(MockAlertRepository)
+	sender := new(MockEmailSender)
+	renderer := new(MockDigestRenderer)
+
+	useCase := NewSendDigests(store, repo, sender, renderer)
+
+	recipient := domain.NewRecipient(43, "user@example.com")
+	store.On("PendingDigestQueues", mock.Anything).Return(nil, nil)
+	store.On("ClaimDigest", mock.Anything, DefaultDigestLease).Return(testDigest(1), nil).Once()
+	store.On("ClaimDigest", mock.Anything, DefaultDigestLease).Return(domain.Digest{}, domain.ErrNoDigestDue).Once()
+	repo.On("GetRecipient", mock.Anything, int64(42)).Return(nil, errors.New("no rows in result set"))
+	repo.On("GetRecipient", mock.Anything, int64(43)).Return(recipient, nil)
+	renderer.On("RenderDigest", mock.Anything, recipient).Return(&domain.AlertEmail{}, nil)
+	sender.On("Send", mock.Anything, mock.Anything).Return("250 ok", nil)
+	store.On("MarkDigestSent", mock.Anything, int64(5), "250 ok").Return(nil)
+
+	sent, err := useCase.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+}
+
+func TestSendDigests_Run_OptedOut(t *testing.T) {
+	store := new(MockDigestStore)
+	repo := new(MockAlertRepository)
+	sender := new(MockEmailSender)
+	renderer := new(MockDigestRenderer)
+
+	useCase := NewSendDigests(store, repo, sender, renderer)
+
+	recipient := domain.NewRecipient(42, "user@example.com")
+	recipient.OptedOut = true
+	store.On("PendingDigestQueues", mock.Anything).Return(nil, nil)
+	store.On("ClaimDigest", mock.Anything, DefaultDigestLease).Return(testDigest(1), nil).Once()
+	store.On("ClaimDigest", mock.Anything, DefaultDigestLease).Return(domain.Digest{}, domain.ErrNoDigestDue).Once()
+	repo.On("GetRecipient", mock.Anything, mock.Anything).Return(recipient, nil)
+	store.On("MarkDigestFailed", mock.Anything, int64(5), "opted_out").Return(nil)
+
+	sent, err := useCase.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	sender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+	store.AssertExpectations(t)
+}
+
+func TestSendDigests_Run_ClaimError(t *testing.T) {
+	store := new(MockDigestStore)
+	useCase := NewSendDigests(store, new(MockAlertRepository), new(MockEmailSender), new(MockDigestRenderer))
+
+	store.On("PendingDigestQueues", mock.Anything).Return(nil, errors.New("connection refused"))
+	store.On("ClaimDigest", mock.Anything, DefaultDigestLease).Return(domain.Digest{}, errors.New("connection refused"))
+
+	_, err := useCase.Run(context.Background())
+
+	assert.EqualError(t, err, "connection refused")
+}